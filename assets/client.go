@@ -3,14 +3,16 @@
 package assets
 
 import (
-	"github.com/secureworks/taegis-sdk-go/client"
-	"github.com/secureworks/taegis-sdk-go/graphql"
 	"context"
 	"net/http"
 	"time"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
 )
 
-// Client provides an easy to use Go client to the Assets API
+// Client provides an easy to use Go client to the Assets API. With graphql.RequestWithPartialData its methods
+// return the data which resolved along with a *graphql.PartialDataError listing the fields which failed
 type Client struct {
 	client *client.Client
 	url    string
@@ -560,7 +562,7 @@ type User struct {
 	Username  string    `json:"username"`
 }
 
-// GetTagCtx will gen an asset tag by id
+// GetTagCtx gets an asset tag by id.
 func (c *Client) GetTagCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (Tag, error) {
 	req := graphql.NewRequest(`query($id: ID!) {
 		tag(id: $id) {`+allTagFields+`
@@ -572,18 +574,21 @@ func (c *Client) GetTagCtx(ctx context.Context, id string, opts ...graphql.Reque
 		Tag Tag `json:"tag"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.Tag, err
+		}
 		return Tag{}, err
 	}
 
 	return res.Tag, nil
 }
 
-// GetTag will gen an asset tag by id
+// GetTag gets an asset tag by id.
 func (c *Client) GetTag(id string, opts ...graphql.RequestOption) (Tag, error) {
 	return c.GetTagCtx(context.Background(), id, opts...)
 }
 
-// GetAssetCtx will get an asset by id
+// GetAssetCtx gets an asset by id.
 func (c *Client) GetAssetCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (Asset, error) {
 	req := graphql.NewRequest(`query($id: ID!) {
 		asset(id: $id) {`+allAssetFields+`
@@ -595,18 +600,21 @@ func (c *Client) GetAssetCtx(ctx context.Context, id string, opts ...graphql.Req
 		Asset Asset `json:"asset"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.Asset, err
+		}
 		return Asset{}, err
 	}
 
 	return res.Asset, nil
 }
 
-// GetAsset will get an asset by id
+// GetAsset gets an asset by id.
 func (c *Client) GetAsset(id string, opts ...graphql.RequestOption) (Asset, error) {
 	return c.GetAssetCtx(context.Background(), id, opts...)
 }
 
-// GetAssetsByTagCtx will get a list of assets with tag
+// GetAssetsByTagCtx gets the assets with one of the tags.
 func (c *Client) GetAssetsByTagCtx(ctx context.Context, tags []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	req := graphql.NewRequest(`query($tags: [String!]!) {
 		assetsByTag(tags: $tags) {`+allAssetFields+`
@@ -618,18 +626,21 @@ func (c *Client) GetAssetsByTagCtx(ctx context.Context, tags []string, opts ...g
 		AssetsByTag []*Asset `json:"assetsByTag"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetsByTag, err
+		}
 		return nil, err
 	}
 
 	return res.AssetsByTag, nil
 }
 
-// GetAssetsByTag will get a list of assets with tag
+// GetAssetsByTag gets the assets with one of the tags.
 func (c *Client) GetAssetsByTag(tags []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	return c.GetAssetsByTagCtx(context.Background(), tags, opts...)
 }
 
-// GetAllUniqueTagsCtx will get a list of all unique tags
+// GetAllUniqueTagsCtx gets all the unique tags.
 func (c *Client) GetAllUniqueTagsCtx(ctx context.Context, opts ...graphql.RequestOption) ([]string, error) {
 	req := graphql.NewRequest(`query {
 		allUniqueTags
@@ -639,18 +650,21 @@ func (c *Client) GetAllUniqueTagsCtx(ctx context.Context, opts ...graphql.Reques
 		AllUniqueTags []string `json:"allUniqueTags"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AllUniqueTags, err
+		}
 		return nil, err
 	}
 
 	return res.AllUniqueTags, nil
 }
 
-// GetAllUniqueTags will get a list of all unique tags
+// GetAllUniqueTags gets all the unique tags.
 func (c *Client) GetAllUniqueTags(opts ...graphql.RequestOption) ([]string, error) {
 	return c.GetAllUniqueTagsCtx(context.Background(), opts...)
 }
 
-// GetAssetEndpointInfoCtx will get RedCloak endpoint info by id
+// GetAssetEndpointInfoCtx gets the Red Cloak endpoint info of an asset by id.
 func (c *Client) GetAssetEndpointInfoCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (EndpointInfo, error) {
 	req := graphql.NewRequest(`query($id: ID!) {
 		assetEndpointInfo(id: $id) {`+allEndpointInfoFields+`
@@ -662,13 +676,16 @@ func (c *Client) GetAssetEndpointInfoCtx(ctx context.Context, id string, opts ..
 		AssetEndpointInfo EndpointInfo `json:"assetEndpointInfo"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetEndpointInfo, err
+		}
 		return EndpointInfo{}, err
 	}
 
 	return res.AssetEndpointInfo, nil
 }
 
-// GetAssetEndpointInfo will get RedCloak endpoint info by id
+// GetAssetEndpointInfo gets the Red Cloak endpoint info of an asset by id.
 func (c *Client) GetAssetEndpointInfo(id string, opts ...graphql.RequestOption) (EndpointInfo, error) {
 	return c.GetAssetEndpointInfoCtx(context.Background(), id, opts...)
 }
@@ -682,7 +699,7 @@ type GetAllAssetsArguments struct {
 	FilterAssetState *AssetStateFilter          `json:"filter_asset_state"`
 }
 
-// GetAllAssetsCtx will get a list of assets
+// GetAllAssetsCtx gets a page of assets.
 func (c *Client) GetAllAssetsCtx(ctx context.Context, params *GetAllAssetsArguments, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	req := graphql.NewRequest(`query($offset: Int, $limit: Int, $order_by: AssetsOrderByInput, $order_direction: AssetsOrderDirectionInput, $filter_asset_state: AssetStateFilter) {
 		allAssets(offset: $offset, limit: $limit, order_by: $order_by, order_direction: $order_direction, filter_asset_state: $filter_asset_state) {`+allAssetsResultFields+`
@@ -698,18 +715,21 @@ func (c *Client) GetAllAssetsCtx(ctx context.Context, params *GetAllAssetsArgume
 		AllAssets *AssetsResult `json:"allAssets"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AllAssets, err
+		}
 		return nil, err
 	}
 
 	return res.AllAssets, nil
 }
 
-// GetAllAssets will get a list of assets
+// GetAllAssets gets a page of assets.
 func (c *Client) GetAllAssets(params *GetAllAssetsArguments, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	return c.GetAllAssetsCtx(context.Background(), params, opts...)
 }
 
-// GetAllAssetsExportCtx will get a list of assets for export to CSV
+// GetAllAssetsExportCtx gets a page of assets for export to CSV.
 func (c *Client) GetAllAssetsExportCtx(ctx context.Context, offset *int, limit *int, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	req := graphql.NewRequest(`query($offset: Int, $limit: Int) {
 		allAssetsExport(offset: $offset, limit: $limit) {`+allAssetsResultFields+`
@@ -722,18 +742,21 @@ func (c *Client) GetAllAssetsExportCtx(ctx context.Context, offset *int, limit *
 		AllAssetsExport *AssetsResult `json:"allAssetsExport"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AllAssetsExport, err
+		}
 		return nil, err
 	}
 
 	return res.AllAssetsExport, nil
 }
 
-// GetAllAssetsExport will get a list of assets for export to CSV
+// GetAllAssetsExport gets a page of assets for export to CSV.
 func (c *Client) GetAllAssetsExport(offset *int, limit *int, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	return c.GetAllAssetsExportCtx(context.Background(), offset, limit, opts...)
 }
 
-// GetAssetCountCtx will count of assets of a specific endpoint_type
+// GetAssetCountCtx counts the assets of an endpoint type.
 func (c *Client) GetAssetCountCtx(ctx context.Context, endpoint_type *AgentType, opts ...graphql.RequestOption) (AssetCounts, error) {
 	req := graphql.NewRequest(`query($endpoint_type: AgentType) {
 		assetCount(endpoint_type: $endpoint_type) {`+allAssetCountsFields+`
@@ -745,18 +768,21 @@ func (c *Client) GetAssetCountCtx(ctx context.Context, endpoint_type *AgentType,
 		AssetCount AssetCounts `json:"assetCount"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetCount, err
+		}
 		return AssetCounts{}, err
 	}
 
 	return res.AssetCount, nil
 }
 
-// GetAssetCount will count of assets of a specific endpoint_type
+// GetAssetCount counts the assets of an endpoint type.
 func (c *Client) GetAssetCount(endpoint_type *AgentType, opts ...graphql.RequestOption) (AssetCounts, error) {
 	return c.GetAssetCountCtx(context.Background(), endpoint_type, opts...)
 }
 
-// GetAssetCountGroupByEndpointTypeCtx will count of assets of grouped by endpoint_type
+// GetAssetCountGroupByEndpointTypeCtx counts the assets grouped by endpoint type.
 func (c *Client) GetAssetCountGroupByEndpointTypeCtx(ctx context.Context, opts ...graphql.RequestOption) ([]*AssetCountsByEndpointType, error) {
 	req := graphql.NewRequest(`query {
 		assetCountGroupByEndpointType {`+allAssetCountsByEndpointTypeFields+`
@@ -767,18 +793,21 @@ func (c *Client) GetAssetCountGroupByEndpointTypeCtx(ctx context.Context, opts .
 		AssetCountGroupByEndpointType []*AssetCountsByEndpointType `json:"assetCountGroupByEndpointType"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetCountGroupByEndpointType, err
+		}
 		return nil, err
 	}
 
 	return res.AssetCountGroupByEndpointType, nil
 }
 
-// GetAssetCountGroupByEndpointType will count of assets of grouped by endpoint_type
+// GetAssetCountGroupByEndpointType counts the assets grouped by endpoint type.
 func (c *Client) GetAssetCountGroupByEndpointType(opts ...graphql.RequestOption) ([]*AssetCountsByEndpointType, error) {
 	return c.GetAssetCountGroupByEndpointTypeCtx(context.Background(), opts...)
 }
 
-// GetAllAssetsCountCtx will count of all assets
+// GetAllAssetsCountCtx counts all the assets.
 func (c *Client) GetAllAssetsCountCtx(ctx context.Context, opts ...graphql.RequestOption) (AssetCounts, error) {
 	req := graphql.NewRequest(`query {
 		allAssetsCount {`+allAssetCountsFields+`
@@ -789,18 +818,21 @@ func (c *Client) GetAllAssetsCountCtx(ctx context.Context, opts ...graphql.Reque
 		AllAssetsCount AssetCounts `json:"allAssetsCount"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AllAssetsCount, err
+		}
 		return AssetCounts{}, err
 	}
 
 	return res.AllAssetsCount, nil
 }
 
-// GetAllAssetsCount will count of all assets
+// GetAllAssetsCount counts all the assets.
 func (c *Client) GetAllAssetsCount(opts ...graphql.RequestOption) (AssetCounts, error) {
 	return c.GetAllAssetsCountCtx(context.Background(), opts...)
 }
 
-// GetAssetsByIdsCtx will bulk lookup by ids
+// GetAssetsByIdsCtx looks up assets by ids.
 func (c *Client) GetAssetsByIdsCtx(ctx context.Context, ids []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	req := graphql.NewRequest(`query($ids: [ID!]) {
 		assetsByIds(ids: $ids) {`+allAssetFields+`
@@ -812,18 +844,21 @@ func (c *Client) GetAssetsByIdsCtx(ctx context.Context, ids []string, opts ...gr
 		AssetsByIds []*Asset `json:"assetsByIds"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetsByIds, err
+		}
 		return nil, err
 	}
 
 	return res.AssetsByIds, nil
 }
 
-// GetAssetsByIds will bulk lookup by ids
+// GetAssetsByIds looks up assets by ids.
 func (c *Client) GetAssetsByIds(ids []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	return c.GetAssetsByIdsCtx(context.Background(), ids, opts...)
 }
 
-// GetAssetsByHostIdsCtx will bulk lookup by hostIds
+// GetAssetsByHostIdsCtx looks up assets by host ids.
 func (c *Client) GetAssetsByHostIdsCtx(ctx context.Context, hostIds []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	req := graphql.NewRequest(`query($hostIds: [String!]) {
 		assetsByHostIds(hostIds: $hostIds) {`+allAssetFields+`
@@ -835,18 +870,21 @@ func (c *Client) GetAssetsByHostIdsCtx(ctx context.Context, hostIds []string, op
 		AssetsByHostIds []*Asset `json:"assetsByHostIds"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetsByHostIds, err
+		}
 		return nil, err
 	}
 
 	return res.AssetsByHostIds, nil
 }
 
-// GetAssetsByHostIds will bulk lookup by hostIds
+// GetAssetsByHostIds looks up assets by host ids.
 func (c *Client) GetAssetsByHostIds(hostIds []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	return c.GetAssetsByHostIdsCtx(context.Background(), hostIds, opts...)
 }
 
-// GetAssetsByIpAddressesCtx will bulk lookup by ipAddress
+// GetAssetsByIpAddressesCtx looks up assets by IP addresses.
 func (c *Client) GetAssetsByIpAddressesCtx(ctx context.Context, ipAddresses []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	req := graphql.NewRequest(`query($ipAddresses: [String!]) {
 		assetsByIpAddresses(ipAddresses: $ipAddresses) {`+allAssetFields+`
@@ -858,18 +896,21 @@ func (c *Client) GetAssetsByIpAddressesCtx(ctx context.Context, ipAddresses []st
 		AssetsByIpAddresses []*Asset `json:"assetsByIpAddresses"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetsByIpAddresses, err
+		}
 		return nil, err
 	}
 
 	return res.AssetsByIpAddresses, nil
 }
 
-// GetAssetsByIpAddresses will bulk lookup by ipAddress
+// GetAssetsByIpAddresses looks up assets by IP addresses.
 func (c *Client) GetAssetsByIpAddresses(ipAddresses []string, opts ...graphql.RequestOption) ([]*Asset, error) {
 	return c.GetAssetsByIpAddressesCtx(context.Background(), ipAddresses, opts...)
 }
 
-// GetAllAssetHistoriesCtx will get a list of asset histories for the tenant
+// GetAllAssetHistoriesCtx gets the asset histories of the tenant.
 func (c *Client) GetAllAssetHistoriesCtx(ctx context.Context, offset *int, limit *int, opts ...graphql.RequestOption) ([]*AssetHistory, error) {
 	req := graphql.NewRequest(`query($offset: Int, $limit: Int) {
 		allAssetHistories(offset: $offset, limit: $limit) {`+allAssetHistoryFields+`
@@ -882,18 +923,21 @@ func (c *Client) GetAllAssetHistoriesCtx(ctx context.Context, offset *int, limit
 		AllAssetHistories []*AssetHistory `json:"allAssetHistories"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AllAssetHistories, err
+		}
 		return nil, err
 	}
 
 	return res.AllAssetHistories, nil
 }
 
-// GetAllAssetHistories will get a list of asset histories for the tenant
+// GetAllAssetHistories gets the asset histories of the tenant.
 func (c *Client) GetAllAssetHistories(offset *int, limit *int, opts ...graphql.RequestOption) ([]*AssetHistory, error) {
 	return c.GetAllAssetHistoriesCtx(context.Background(), offset, limit, opts...)
 }
 
-// GetAssetRedCloakHistoriesCtx will get history of actions on an asset by id (includes RedCloack history)
+// GetAssetRedCloakHistoriesCtx gets the history of actions on an asset by id, including the Red Cloak history.
 func (c *Client) GetAssetRedCloakHistoriesCtx(ctx context.Context, id string, offset *int, limit *int, opts ...graphql.RequestOption) ([]*AssetRedCloakHistory, error) {
 	req := graphql.NewRequest(`query($id: ID!, $offset: Int, $limit: Int) {
 		assetRedCloakHistories(id: $id, offset: $offset, limit: $limit) {`+allAssetRedCloakHistoryFields+`
@@ -907,13 +951,16 @@ func (c *Client) GetAssetRedCloakHistoriesCtx(ctx context.Context, id string, of
 		AssetRedCloakHistories []*AssetRedCloakHistory `json:"assetRedCloakHistories"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.AssetRedCloakHistories, err
+		}
 		return nil, err
 	}
 
 	return res.AssetRedCloakHistories, nil
 }
 
-// GetAssetRedCloakHistories will get history of actions on an asset by id (includes RedCloack history)
+// GetAssetRedCloakHistories gets the history of actions on an asset by id, including the Red Cloak history.
 func (c *Client) GetAssetRedCloakHistories(id string, offset *int, limit *int, opts ...graphql.RequestOption) ([]*AssetRedCloakHistory, error) {
 	return c.GetAssetRedCloakHistoriesCtx(context.Background(), id, offset, limit, opts...)
 }
@@ -940,7 +987,7 @@ type GetSearchAssetsArguments struct {
 	FilterAssetState   *AssetStateFilter          `json:"filter_asset_state"`
 }
 
-// GetSearchAssetsCtx will search assets. Soon to be deprecated
+// GetSearchAssetsCtx searches assets, soon to be deprecated in favor of searchAssetsV2.
 func (c *Client) GetSearchAssetsCtx(ctx context.Context, params *GetSearchAssetsArguments, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	req := graphql.NewRequest(`query($offset: Int, $limit: Int, $hostname: String, $host_id: String, $ip_address: String, $mac_address: String, $os_version: String, $os_family: String, $os_distributor: String, $username: String, $endpoint_type: String, $tag: String, $host_id_partial_match: Boolean, $only_most_recent: Boolean, $order_by: AssetsOrderByInput, $order_direction: AssetsOrderDirectionInput, $or_search: Boolean, $filter_asset_state: AssetStateFilter) {
 		searchAssets(offset: $offset, limit: $limit, hostname: $hostname, host_id: $host_id, ip_address: $ip_address, mac_address: $mac_address, os_version: $os_version, os_family: $os_family, os_distributor: $os_distributor, username: $username, endpoint_type: $endpoint_type, tag: $tag, host_id_partial_match: $host_id_partial_match, only_most_recent: $only_most_recent, order_by: $order_by, order_direction: $order_direction, or_search: $or_search, filter_asset_state: $filter_asset_state) {`+allAssetsResultFields+`
@@ -969,18 +1016,21 @@ func (c *Client) GetSearchAssetsCtx(ctx context.Context, params *GetSearchAssets
 		SearchAssets *AssetsResult `json:"searchAssets"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.SearchAssets, err
+		}
 		return nil, err
 	}
 
 	return res.SearchAssets, nil
 }
 
-// GetSearchAssets will search assets. Soon to be deprecated
+// GetSearchAssets searches assets, soon to be deprecated in favor of searchAssetsV2.
 func (c *Client) GetSearchAssets(params *GetSearchAssetsArguments, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	return c.GetSearchAssetsCtx(context.Background(), params, opts...)
 }
 
-// GetSearchAssetsV2Ctx will search assets v2
+// GetSearchAssetsV2Ctx searches assets.
 func (c *Client) GetSearchAssetsV2Ctx(ctx context.Context, input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	req := graphql.NewRequest(`query($input: SearchAssetsInput!, $paginationInput: SearchAssetsPaginationInput) {
 		searchAssetsV2(input: $input, paginationInput: $paginationInput) {`+allAssetsResultFields+`
@@ -993,18 +1043,21 @@ func (c *Client) GetSearchAssetsV2Ctx(ctx context.Context, input SearchAssetsInp
 		SearchAssetsV2 *AssetsResult `json:"searchAssetsV2"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.SearchAssetsV2, err
+		}
 		return nil, err
 	}
 
 	return res.SearchAssetsV2, nil
 }
 
-// GetSearchAssetsV2 will search assets v2
+// GetSearchAssetsV2 searches assets.
 func (c *Client) GetSearchAssetsV2(input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) (*AssetsResult, error) {
 	return c.GetSearchAssetsV2Ctx(context.Background(), input, paginationInput, opts...)
 }
 
-// GetExportSearchAssetsCtx will export search assets results
+// GetExportSearchAssetsCtx exports the results of an asset search.
 func (c *Client) GetExportSearchAssetsCtx(ctx context.Context, input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) (*AssetsExportOutput, error) {
 	req := graphql.NewRequest(`query($input: SearchAssetsInput!, $paginationInput: SearchAssetsPaginationInput) {
 		exportSearchAssets(input: $input, paginationInput: $paginationInput) {`+allAssetsExportOutputFields+`
@@ -1017,18 +1070,21 @@ func (c *Client) GetExportSearchAssetsCtx(ctx context.Context, input SearchAsset
 		ExportSearchAssets *AssetsExportOutput `json:"exportSearchAssets"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.ExportSearchAssets, err
+		}
 		return nil, err
 	}
 
 	return res.ExportSearchAssets, nil
 }
 
-// GetExportSearchAssets will export search assets results
+// GetExportSearchAssets exports the results of an asset search.
 func (c *Client) GetExportSearchAssets(input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) (*AssetsExportOutput, error) {
 	return c.GetExportSearchAssetsCtx(context.Background(), input, paginationInput, opts...)
 }
 
-// IsolateAssetCtx will isolate an asset by id
+// IsolateAssetCtx isolates an asset by id.
 func (c *Client) IsolateAssetCtx(ctx context.Context, id string, reason string, opts ...graphql.RequestOption) (Asset, error) {
 	req := graphql.NewRequest(`mutation($id: ID!, $reason: String!) {
 		isolateAsset(id: $id, reason: $reason) {`+allAssetFields+`
@@ -1041,18 +1097,21 @@ func (c *Client) IsolateAssetCtx(ctx context.Context, id string, reason string,
 		IsolateAsset Asset `json:"isolateAsset"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.IsolateAsset, err
+		}
 		return Asset{}, err
 	}
 
 	return res.IsolateAsset, nil
 }
 
-// IsolateAsset will isolate an asset by id
+// IsolateAsset isolates an asset by id.
 func (c *Client) IsolateAsset(id string, reason string, opts ...graphql.RequestOption) (Asset, error) {
 	return c.IsolateAssetCtx(context.Background(), id, reason, opts...)
 }
 
-// IntegrateAssetCtx will integate an asset by id
+// IntegrateAssetCtx integrates an asset by id.
 func (c *Client) IntegrateAssetCtx(ctx context.Context, id string, reason string, opts ...graphql.RequestOption) (Asset, error) {
 	req := graphql.NewRequest(`mutation($id: ID!, $reason: String!) {
 		integrateAsset(id: $id, reason: $reason) {`+allAssetFields+`
@@ -1065,18 +1124,21 @@ func (c *Client) IntegrateAssetCtx(ctx context.Context, id string, reason string
 		IntegrateAsset Asset `json:"integrateAsset"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.IntegrateAsset, err
+		}
 		return Asset{}, err
 	}
 
 	return res.IntegrateAsset, nil
 }
 
-// IntegrateAsset will integate an asset by id
+// IntegrateAsset integrates an asset by id.
 func (c *Client) IntegrateAsset(id string, reason string, opts ...graphql.RequestOption) (Asset, error) {
 	return c.IntegrateAssetCtx(context.Background(), id, reason, opts...)
 }
 
-// DeleteAssetsCtx will delete or un-delete asset
+// DeleteAssetsCtx deletes or undeletes assets.
 func (c *Client) DeleteAssetsCtx(ctx context.Context, ids []string, undelete *bool, opts ...graphql.RequestOption) (*bool, error) {
 	req := graphql.NewRequest(`mutation($ids: [ID!]!, $undelete: Boolean) {
 		deleteAssets(ids: $ids, undelete: $undelete)
@@ -1088,18 +1150,21 @@ func (c *Client) DeleteAssetsCtx(ctx context.Context, ids []string, undelete *bo
 		DeleteAssets *bool `json:"deleteAssets"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.DeleteAssets, err
+		}
 		return nil, err
 	}
 
 	return res.DeleteAssets, nil
 }
 
-// DeleteAssets will delete or un-delete asset
+// DeleteAssets deletes or undeletes assets.
 func (c *Client) DeleteAssets(ids []string, undelete *bool, opts ...graphql.RequestOption) (*bool, error) {
 	return c.DeleteAssetsCtx(context.Background(), ids, undelete, opts...)
 }
 
-// CreateAssetTagCtx will create a new tag for an asseti
+// CreateAssetTagCtx creates a new tag for an asset.
 func (c *Client) CreateAssetTagCtx(ctx context.Context, hostId string, tag string, opts ...graphql.RequestOption) (Tag, error) {
 	req := graphql.NewRequest(`mutation($hostId: String!, $tag: String!) {
 		createAssetTag(hostId: $hostId, tag: $tag) {`+allTagFields+`
@@ -1112,18 +1177,21 @@ func (c *Client) CreateAssetTagCtx(ctx context.Context, hostId string, tag strin
 		CreateAssetTag Tag `json:"createAssetTag"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.CreateAssetTag, err
+		}
 		return Tag{}, err
 	}
 
 	return res.CreateAssetTag, nil
 }
 
-// CreateAssetTag will create a new tag for an asseti
+// CreateAssetTag creates a new tag for an asset.
 func (c *Client) CreateAssetTag(hostId string, tag string, opts ...graphql.RequestOption) (Tag, error) {
 	return c.CreateAssetTagCtx(context.Background(), hostId, tag, opts...)
 }
 
-// UpdateAssetTagCtx will updates a tag for an asset
+// UpdateAssetTagCtx updates a tag of an asset.
 func (c *Client) UpdateAssetTagCtx(ctx context.Context, id string, tag string, opts ...graphql.RequestOption) (Tag, error) {
 	req := graphql.NewRequest(`mutation($id: ID!, $tag: String!) {
 		updateAssetTag(id: $id, tag: $tag) {`+allTagFields+`
@@ -1136,18 +1204,21 @@ func (c *Client) UpdateAssetTagCtx(ctx context.Context, id string, tag string, o
 		UpdateAssetTag Tag `json:"updateAssetTag"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.UpdateAssetTag, err
+		}
 		return Tag{}, err
 	}
 
 	return res.UpdateAssetTag, nil
 }
 
-// UpdateAssetTag will updates a tag for an asset
+// UpdateAssetTag updates a tag of an asset.
 func (c *Client) UpdateAssetTag(id string, tag string, opts ...graphql.RequestOption) (Tag, error) {
 	return c.UpdateAssetTagCtx(context.Background(), id, tag, opts...)
 }
 
-// DeleteAssetTagCtx will deletes a tag for an asset
+// DeleteAssetTagCtx deletes a tag of an asset.
 func (c *Client) DeleteAssetTagCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (*Tag, error) {
 	req := graphql.NewRequest(`mutation($id: ID!) {
 		deleteAssetTag(id: $id) {`+allTagFields+`
@@ -1159,17 +1230,21 @@ func (c *Client) DeleteAssetTagCtx(ctx context.Context, id string, opts ...graph
 		DeleteAssetTag *Tag `json:"deleteAssetTag"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.DeleteAssetTag, err
+		}
 		return nil, err
 	}
 
 	return res.DeleteAssetTag, nil
 }
 
-// DeleteAssetTag will deletes a tag for an asset
+// DeleteAssetTag deletes a tag of an asset.
 func (c *Client) DeleteAssetTag(id string, opts ...graphql.RequestOption) (*Tag, error) {
 	return c.DeleteAssetTagCtx(context.Background(), id, opts...)
 }
 
+// UpdateAssetCtx updates the tags of an asset.
 func (c *Client) UpdateAssetCtx(ctx context.Context, assetInput *AssetInput, opts ...graphql.RequestOption) (Asset, error) {
 	req := graphql.NewRequest(`mutation($assetInput: AssetInput) {
 		updateAsset(assetInput: $assetInput) {`+allAssetFields+`
@@ -1181,12 +1256,16 @@ func (c *Client) UpdateAssetCtx(ctx context.Context, assetInput *AssetInput, opt
 		UpdateAsset Asset `json:"updateAsset"`
 	}
 	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.UpdateAsset, err
+		}
 		return Asset{}, err
 	}
 
 	return res.UpdateAsset, nil
 }
 
+// UpdateAsset updates the tags of an asset.
 func (c *Client) UpdateAsset(assetInput *AssetInput, opts ...graphql.RequestOption) (Asset, error) {
 	return c.UpdateAssetCtx(context.Background(), assetInput, opts...)
 }
@@ -0,0 +1,110 @@
+package assets
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+// TestClient_Schema checks the queries of every method against the bundled schema
+func TestClient_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL)
+
+	str := "test"
+	limit := 10
+	yes := true
+	agent := AgentTypeEndpointRedcloak
+	orderBy := AssetsOrderByInputHostname
+	direction := AssetsOrderDirectionInputAsc
+	state := AssetStateFilterActive
+
+	_, _ = c.GetTag("id")
+	_, _ = c.GetAsset("id")
+	_, _ = c.GetAssetsByTag([]string{"tag"})
+	_, _ = c.GetAllUniqueTags()
+	_, _ = c.GetAssetEndpointInfo("id")
+	_, _ = c.GetAllAssets(&GetAllAssetsArguments{Offset: &limit, Limit: &limit, OrderBy: &orderBy, OrderDirection: &direction, FilterAssetState: &state})
+	_, _ = c.GetAllAssetsExport(&limit, &limit)
+	_, _ = c.GetAssetCount(&agent)
+	_, _ = c.GetAssetCountGroupByEndpointType()
+	_, _ = c.GetAllAssetsCount()
+	_, _ = c.GetAssetsByIds([]string{"id"})
+	_, _ = c.GetAssetsByHostIds([]string{"id"})
+	_, _ = c.GetAssetsByIpAddresses([]string{"127.0.0.1"})
+	_, _ = c.GetAllAssetHistories(&limit, &limit)
+	_, _ = c.GetAssetRedCloakHistories("id", &limit, &limit)
+	_, _ = c.GetSearchAssets(&GetSearchAssetsArguments{
+		Offset: &limit, Limit: &limit, Hostname: &str, HostId: &str, IpAddress: &str, MacAddress: &str, OsVersion: &str,
+		OsFamily: &str, OsDistributor: &str, Username: &str, EndpointType: &str, Tag: &str, HostIdPartialMatch: &yes,
+		OnlyMostRecent: &yes, OrderBy: &orderBy, OrderDirection: &direction, OrSearch: &yes, FilterAssetState: &state,
+	})
+	search := SearchAssetsInput{Hostname: &str, OnlyMostRecent: &yes, FilterAssetState: &state}
+	pagination := &SearchAssetsPaginationInput{Offset: &limit, Limit: &limit, OrderBy: &orderBy, OrderDirection: &direction}
+	_, _ = c.GetSearchAssetsV2(search, pagination)
+	_, _ = c.GetExportSearchAssets(search, pagination)
+	_, _ = c.IsolateAsset("id", "reason")
+	_, _ = c.IntegrateAsset("id", "reason")
+	_, _ = c.DeleteAssets([]string{"id"}, &yes)
+	_, _ = c.CreateAssetTag("hostId", "tag")
+	_, _ = c.UpdateAssetTag("id", "tag")
+	_, _ = c.DeleteAssetTag("id")
+	_, _ = c.UpdateAsset(&AssetInput{ID: "id", Tags: []string{"tag"}})
+}
+
+func TestClient_GetAssetsByIdsPartialData(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		field := client.OperationName(req.Query)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				field: []interface{}{map[string]interface{}{"id": "1"}, nil},
+			},
+			"errors": []interface{}{
+				map[string]interface{}{"message": "asset not found", "path": []interface{}{field, 1}},
+			},
+		})
+	}))
+	defer srv.Close()
+	c := New(srv.URL)
+
+	for name, get := range map[string]func(...graphql.RequestOption) ([]*Asset, error){
+		"ids": func(opts ...graphql.RequestOption) ([]*Asset, error) {
+			return c.GetAssetsByIds([]string{"1", "2"}, opts...)
+		},
+		"hostIds": func(opts ...graphql.RequestOption) ([]*Asset, error) {
+			return c.GetAssetsByHostIds([]string{"1", "2"}, opts...)
+		},
+		"ipAddresses": func(opts ...graphql.RequestOption) ([]*Asset, error) {
+			return c.GetAssetsByIpAddresses([]string{"1", "2"}, opts...)
+		},
+	} {
+		assets, err := get(graphql.RequestWithPartialData())
+		var partial *graphql.PartialDataError
+		require.True(t, errors.As(err, &partial), name)
+		require.Len(t, partial.Errors, 1, name)
+		assert.Equal(t, "asset not found", partial.Errors[0].Message, name)
+		require.Len(t, assets, 2, name)
+		assert.Equal(t, "1", assets[0].ID, name)
+		assert.Nil(t, assets[1], name)
+
+		//without partial data mode the errors fail the call
+		assets, err = get()
+		assert.Error(t, err, name)
+		assert.False(t, graphql.IsPartialData(err), name)
+		assert.Nil(t, assets, name)
+	}
+}
@@ -0,0 +1,4 @@
+// Package assets is a client to the Assets API, client.go is generated from schema/assets.graphql
+package assets
+
+//go:generate go run github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen -schema ../schema -source ../schema/assets.graphql -package assets -service Assets -out client.go
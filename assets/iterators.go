@@ -0,0 +1,114 @@
+package assets
+
+import (
+	"context"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// GetAllAssetsIterator returns an Iterator over the Asset items of GetAllAssets, starting at params.Offset with pages
+// of params.Limit assets
+func (c *Client) GetAllAssetsIterator(params *GetAllAssetsArguments, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		args := *params
+		args.Offset, args.Limit = &req.Offset, &req.Size
+		res, err := c.GetAllAssetsCtx(ctx, &args, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return assetsPage(res), nil
+	}, common.FirstOffset(params.Offset, params.Limit))
+}
+
+// GetAllAssetsExportIterator returns an Iterator over the Asset items of GetAllAssetsExport
+func (c *Client) GetAllAssetsExportIterator(offset *int, limit *int, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		res, err := c.GetAllAssetsExportCtx(ctx, &req.Offset, &req.Size, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return assetsPage(res), nil
+	}, common.FirstOffset(offset, limit))
+}
+
+// GetSearchAssetsIterator returns an Iterator over the Asset items of GetSearchAssets, starting at params.Offset with
+// pages of params.Limit assets
+func (c *Client) GetSearchAssetsIterator(params *GetSearchAssetsArguments, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		args := *params
+		args.Offset, args.Limit = &req.Offset, &req.Size
+		res, err := c.GetSearchAssetsCtx(ctx, &args, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return assetsPage(res), nil
+	}, common.FirstOffset(params.Offset, params.Limit))
+}
+
+// GetSearchAssetsV2Iterator returns an Iterator over the Asset items of GetSearchAssetsV2, paginationInput being the
+// first page
+func (c *Client) GetSearchAssetsV2Iterator(input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) *common.Iterator {
+	first := SearchAssetsPaginationInput{}
+	if paginationInput != nil {
+		first = *paginationInput
+	}
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		pagination := first
+		pagination.Offset, pagination.Limit = &req.Offset, &req.Size
+		res, err := c.GetSearchAssetsV2Ctx(ctx, input, &pagination, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return assetsPage(res), nil
+	}, common.FirstOffset(first.Offset, first.Limit))
+}
+
+// GetExportSearchAssetsIterator returns an Iterator over the string rows of GetExportSearchAssets, paginationInput
+// being the first page
+func (c *Client) GetExportSearchAssetsIterator(input SearchAssetsInput, paginationInput *SearchAssetsPaginationInput, opts ...graphql.RequestOption) *common.Iterator {
+	first := SearchAssetsPaginationInput{}
+	if paginationInput != nil {
+		first = *paginationInput
+	}
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		pagination := first
+		pagination.Offset, pagination.Limit = &req.Offset, &req.Size
+		res, err := c.GetExportSearchAssetsCtx(ctx, input, &pagination, opts...)
+		if err != nil || res == nil {
+			return nil, err
+		}
+		return &common.Page{Items: res.Rows, Total: res.TotalCount}, nil
+	}, common.FirstOffset(first.Offset, first.Limit))
+}
+
+// GetAllAssetHistoriesIterator returns an Iterator over the *AssetHistory items of GetAllAssetHistories
+func (c *Client) GetAllAssetHistoriesIterator(offset *int, limit *int, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		res, err := c.GetAllAssetHistoriesCtx(ctx, &req.Offset, &req.Size, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page{Items: res}, nil
+	}, common.FirstOffset(offset, limit))
+}
+
+// GetAssetRedCloakHistoriesIterator returns an Iterator over the *AssetRedCloakHistory items of
+// GetAssetRedCloakHistories
+func (c *Client) GetAssetRedCloakHistoriesIterator(id string, offset *int, limit *int, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		res, err := c.GetAssetRedCloakHistoriesCtx(ctx, id, &req.Offset, &req.Size, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page{Items: res}, nil
+	}, common.FirstOffset(offset, limit))
+}
+
+func assetsPage(res *AssetsResult) *common.Page {
+	if res == nil {
+		return nil
+	}
+	total := res.TotalResults
+	return &common.Page{Items: res.Assets, Total: &total}
+}
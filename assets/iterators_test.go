@@ -0,0 +1,68 @@
+package assets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assetsServer serves total assets by offset and limit
+func assetsServer(t *testing.T, total int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Offset int    `json:"offset"`
+				Limit  int    `json:"limit"`
+				Order  string `json:"order_by"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, string(AssetsOrderByInputHostname), req.Variables.Order)
+
+		assets := []map[string]interface{}{}
+		for i := req.Variables.Offset; i < req.Variables.Offset+req.Variables.Limit && i < total; i++ {
+			assets = append(assets, map[string]interface{}{"id": fmt.Sprint(i)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"allAssets": map[string]interface{}{
+					"totalResults": total,
+					"offset":       req.Variables.Offset,
+					"limit":        req.Variables.Limit,
+					"assets":       assets,
+				},
+			},
+		})
+	}))
+}
+
+func TestClient_GetAllAssetsIterator(t *testing.T) {
+	s := assetsServer(t, 7)
+	defer s.Close()
+	c := New(s.URL)
+
+	offset, limit := 1, 3
+	orderBy := AssetsOrderByInputHostname
+	it := c.GetAllAssetsIterator(&GetAllAssetsArguments{Offset: &offset, Limit: &limit, OrderBy: &orderBy})
+
+	var first Asset
+	require.NoError(t, it.Next(context.Background(), &first))
+	assert.Equal(t, "1", first.ID)
+	total, ok := it.Total()
+	assert.True(t, ok)
+	assert.Equal(t, 7, total)
+
+	var rest []Asset
+	require.NoError(t, it.Prefetch(2).All(context.Background(), &rest))
+	ids := []string{}
+	for _, asset := range rest {
+		ids = append(ids, asset.ID)
+	}
+	assert.Equal(t, []string{"2", "3", "4", "5", "6"}, ids)
+}
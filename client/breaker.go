@@ -0,0 +1,262 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBreakerFailureThreshold = 5
+	defaultBreakerCoolDown         = 30 * time.Second
+	defaultBreakerHalfOpenRequests = 1
+)
+
+// ErrBreakerOpen is matched by every BreakerOpenError with errors.Is
+var ErrBreakerOpen = errors.New("ctpx-sdk-go/client: circuit breaker is open")
+
+// BreakerState is the state of the circuit breaker of a single endpoint
+type BreakerState int
+
+const (
+	// BreakerClosed lets every request through
+	BreakerClosed BreakerState = iota
+	// BreakerOpen fails every request without sending it
+	BreakerOpen
+	// BreakerHalfOpen lets a limited number of trial requests through to probe the endpoint
+	BreakerHalfOpen
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("unknown(%d)", int(s))
+	}
+}
+
+// BreakerOpenError is returned by Client.Do when the breaker for the endpoint is open
+type BreakerOpenError struct {
+	Endpoint string
+	State    BreakerState
+}
+
+func (e *BreakerOpenError) Error() string {
+	return fmt.Sprintf("ctpx-sdk-go/client: circuit breaker is %s for %s", e.State, e.Endpoint)
+}
+
+// Is lets errors.Is(err, ErrBreakerOpen) match
+func (e *BreakerOpenError) Is(target error) bool {
+	return target == ErrBreakerOpen
+}
+
+// BreakerSettings configures the circuit breakers used by the Client, one breaker is kept per host and path
+type BreakerSettings struct {
+	// FailureThreshold is the number of consecutive failures which opens the breaker, defaults to 5
+	FailureThreshold int
+	// CoolDown is how long the breaker stays open before letting trial requests through, defaults to 30 seconds
+	CoolDown time.Duration
+	// HalfOpenMaxRequests is the number of trial requests allowed while half-open,
+	// that many successes close the breaker again. Defaults to 1
+	HalfOpenMaxRequests int
+	// IsFailure decides whether a result counts as a failure, defaults to any error or a 5xx response
+	IsFailure func(*http.Response, error) bool
+	// OnStateChange is called whenever the breaker of an endpoint changes state
+	OnStateChange func(endpoint string, from, to BreakerState)
+}
+
+// WithCircuitBreaker enables a circuit breaker per API endpoint so a degraded API fails fast instead of being hammered
+func WithCircuitBreaker(settings BreakerSettings) Option {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = defaultBreakerFailureThreshold
+	}
+	if settings.CoolDown <= 0 {
+		settings.CoolDown = defaultBreakerCoolDown
+	}
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = defaultBreakerHalfOpenRequests
+	}
+	if settings.IsFailure == nil {
+		settings.IsFailure = defaultIsFailure
+	}
+	return func(c *Client) {
+		c.breakers = &breakerSet{
+			settings: settings,
+			breakers: map[string]*breaker{},
+			now:      time.Now,
+		}
+	}
+}
+
+// defaultIsFailure counts the transport errors, the HTTPTimeout of the client included, and the server errors.
+// The cancellations of the caller are left out before, see breakerSet.do
+func defaultIsFailure(resp *http.Response, err error) bool {
+	return err != nil || resp.StatusCode >= http.StatusInternalServerError
+}
+
+type breakerSet struct {
+	settings BreakerSettings
+	now      func() time.Time
+
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+type breaker struct {
+	state      BreakerState
+	generation uint64
+	failures   int
+	successes  int
+	inFlight   int
+	openedAt   time.Time
+}
+
+func breakerEndpoint(req *http.Request) string {
+	return req.URL.Host + req.URL.Path
+}
+
+// do sends the request through the breaker of its endpoint
+func (b *breakerSet) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	endpoint := breakerEndpoint(req)
+	generation, err := b.allow(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := send(req)
+	if err != nil && req.Context().Err() != nil { //cancelled by the caller, neither a success nor a failure
+		b.release(endpoint, generation)
+		return resp, err
+	}
+	b.record(endpoint, generation, b.settings.IsFailure(resp, err))
+	return resp, err
+}
+
+// release gives back the half-open slot of a request without recording its result
+func (b *breakerSet) release(endpoint string, generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if br := b.breakers[endpoint]; br.generation == generation && br.state == BreakerHalfOpen {
+		br.inFlight--
+	}
+}
+
+func (b *breakerSet) state(endpoint string) BreakerState {
+	b.mu.Lock()
+	br, ok := b.breakers[endpoint]
+	if !ok {
+		b.mu.Unlock()
+		return BreakerClosed
+	}
+	from := br.state
+	b.refresh(br)
+	to := br.state
+	b.mu.Unlock()
+
+	b.notify(endpoint, from, to)
+	return to
+}
+
+func (b *breakerSet) allow(endpoint string) (uint64, error) {
+	b.mu.Lock()
+	br, ok := b.breakers[endpoint]
+	if !ok {
+		br = &breaker{}
+		b.breakers[endpoint] = br
+	}
+
+	from := br.state
+	b.refresh(br)
+	to := br.state
+
+	var err error
+	switch {
+	case to == BreakerOpen:
+		err = &BreakerOpenError{Endpoint: endpoint, State: to}
+	case to == BreakerHalfOpen && br.inFlight >= b.settings.HalfOpenMaxRequests:
+		err = &BreakerOpenError{Endpoint: endpoint, State: to}
+	case to == BreakerHalfOpen:
+		br.inFlight++
+	}
+	generation := br.generation
+	b.mu.Unlock()
+
+	b.notify(endpoint, from, to)
+	return generation, err
+}
+
+func (b *breakerSet) record(endpoint string, generation uint64, failed bool) {
+	b.mu.Lock()
+	br := b.breakers[endpoint]
+	if br.generation != generation { //result of a request sent before the last state change
+		b.mu.Unlock()
+		return
+	}
+
+	from := br.state
+	switch br.state {
+	case BreakerClosed:
+		if !failed {
+			br.failures = 0
+			break
+		}
+		br.failures++
+		if br.failures >= b.settings.FailureThreshold {
+			b.setState(br, BreakerOpen)
+		}
+	case BreakerHalfOpen:
+		br.inFlight--
+		if failed {
+			b.setState(br, BreakerOpen)
+			break
+		}
+		br.successes++
+		if br.successes >= b.settings.HalfOpenMaxRequests {
+			b.setState(br, BreakerClosed)
+		}
+	}
+	to := br.state
+	b.mu.Unlock()
+
+	b.notify(endpoint, from, to)
+}
+
+// refresh moves an open breaker to half-open once the cool down has passed, it must be called with the lock held
+func (b *breakerSet) refresh(br *breaker) {
+	if br.state == BreakerOpen && b.now().Sub(br.openedAt) >= b.settings.CoolDown {
+		b.setState(br, BreakerHalfOpen)
+	}
+}
+
+func (b *breakerSet) setState(br *breaker, state BreakerState) {
+	br.state = state
+	br.generation++
+	br.failures = 0
+	br.successes = 0
+	br.inFlight = 0
+	if state == BreakerOpen {
+		br.openedAt = b.now()
+	}
+}
+
+func (b *breakerSet) notify(endpoint string, from, to BreakerState) {
+	if from != to && b.settings.OnStateChange != nil {
+		b.settings.OnStateChange(endpoint, from, to)
+	}
+}
+
+// BreakerState returns the circuit breaker state for the given endpoint (host and path of the API URL).
+// It is always BreakerClosed when the Client was created without WithCircuitBreaker
+func (c *Client) BreakerState(endpoint string) BreakerState {
+	if c.breakers == nil {
+		return BreakerClosed
+	}
+	return c.breakers.state(endpoint)
+}
@@ -0,0 +1,192 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stateChange struct {
+	from, to BreakerState
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var (
+		failing = int32(1)
+		calls   uint64
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&calls, 1)
+		if atomic.LoadInt32(&failing) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var (
+		mu      sync.Mutex
+		changes []stateChange
+	)
+	c := NewClient(WithCircuitBreaker(BreakerSettings{
+		FailureThreshold: 2,
+		CoolDown:         time.Minute,
+		OnStateChange: func(_ string, from, to BreakerState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, stateChange{from, to})
+		},
+	}))
+	now := time.Now()
+	c.breakers.now = func() time.Time { return now }
+
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	endpoint := u.Host
+
+	do := func() (*http.Response, error) {
+		req, err := http.NewRequest(http.MethodPost, srv.URL, nil)
+		require.NoError(t, err)
+		resp, err := c.Do(req)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return resp, err
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err := do()
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	}
+	assert.Equal(t, BreakerOpen, c.BreakerState(endpoint))
+
+	_, err = do() //nolint: bodyclose closed in do
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	var openErr *BreakerOpenError
+	require.True(t, errors.As(err, &openErr))
+	assert.Equal(t, endpoint, openErr.Endpoint)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+
+	// the trial request fails and the breaker opens again
+	now = now.Add(time.Minute)
+	_, err = do() //nolint: bodyclose closed in do
+	require.NoError(t, err)
+	assert.Equal(t, BreakerOpen, c.BreakerState(endpoint))
+
+	now = now.Add(time.Minute)
+	atomic.StoreInt32(&failing, 0)
+	resp, err := do()
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, BreakerClosed, c.BreakerState(endpoint))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []stateChange{
+		{BreakerClosed, BreakerOpen},
+		{BreakerOpen, BreakerHalfOpen},
+		{BreakerHalfOpen, BreakerOpen},
+		{BreakerOpen, BreakerHalfOpen},
+		{BreakerHalfOpen, BreakerClosed},
+	}, changes)
+}
+
+func TestCircuitBreakerPerEndpoint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithCircuitBreaker(BreakerSettings{FailureThreshold: 1}))
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL+"/down", nil)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/down", nil)
+	_, err = c.Do(req) //nolint: bodyclose no body is returned with an error
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+
+	req, _ = http.NewRequest(http.MethodPost, srv.URL+"/up", nil)
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestCircuitBreakerNotRetried(t *testing.T) {
+	var calls uint64
+	srv := flakyServer(t, 10, http.StatusServiceUnavailable, &calls)
+	defer srv.Close()
+
+	p := testRetryPolicy()
+	p.MaxAttempts = 5
+	c := NewClient(WithRetryPolicy(p), WithCircuitBreaker(BreakerSettings{FailureThreshold: 2}))
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	_, err := c.Do(req) //nolint: bodyclose no body is returned with an error
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+}
+
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithCircuitBreaker(BreakerSettings{FailureThreshold: 1}))
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		req, _ := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, nil)
+		_, err := c.Do(req) //nolint: bodyclose no body is returned with an error
+		cancel()
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, BreakerClosed, c.BreakerState(u.Host))
+}
+
+func TestCircuitBreakerCountsTimeouts(t *testing.T) {
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer srv.Close()
+	defer close(done)
+
+	c := NewClient(WithHTTPTimeout(20*time.Millisecond), WithCircuitBreaker(BreakerSettings{FailureThreshold: 2}))
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+		_, err := c.Do(req) //nolint: bodyclose no body is returned with an error
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	}
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	assert.Equal(t, BreakerOpen, c.BreakerState(u.Host))
+
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, nil)
+	_, err = c.Do(req) //nolint: bodyclose no body is returned with an error
+	assert.True(t, errors.Is(err, ErrBreakerOpen))
+}
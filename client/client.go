@@ -9,7 +9,7 @@ import (
 	"github.com/secureworks/taegis-sdk-go/log"
 
 	"github.com/hashicorp/go-cleanhttp"
-	"moul.io/http2curl"
+	"golang.org/x/oauth2"
 )
 
 const (
@@ -26,27 +26,52 @@ type Client struct {
 	Logger      log.Logger
 	bearer      *string
 	tenant      *string
+	retry       *RetryPolicy
+	breakers    *breakerSet
+	tokens      oauth2.TokenSource
+	limiter     *rateLimiter
+	metrics     Instrumentation
+	debug       *DebugSettings
+	values      map[interface{}]interface{}
 }
 
-// Do will run the HTTP request and add a bearer if the client was setup with a token
-// optionally you can set a env var of `CURL_DEBUG=true` to get curl output printed out for
-// easier debugging
+// Do will run the HTTP request and add a bearer if the client was setup with a token.
+// The request, trace and span ids of the request context (see log.CtxWithTraceContext) are sent as headers,
+// a request id is generated when there is none. Requests can be dumped for debugging with WithDebug
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	c.addHeaderValues(req.Header)
-	if os.Getenv("CURL_DEBUG") == "true" {
-		command, err := http2curl.GetCurlCommand(req)
-		if err != nil {
-			c.Logger.Warn().WithError(err).Msg("curl not found")
-		} else {
-			c.Logger.Debug().WithStr("command", command.String()).Msg("curl command")
-		}
+	log.InjectTraceHeaders(req.Context(), req.Header) //once per Do so retries share the same request id
+	send := c.send
+	if c.retry != nil {
+		send = func(req *http.Request) (*http.Response, error) { return c.doWithRetry(req, c.send) }
+	}
+	if c.debug != nil {
+		send = c.dumpRequest(req, send)
+	}
+	if c.metrics != nil {
+		return c.doInstrumented(req, send)
+	}
+	return send(req)
+}
+
+// send performs a single attempt of the request
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	if c.limiter != nil {
+		return c.limiter.do(req, c.sendThroughBreaker)
+	}
+	return c.sendThroughBreaker(req)
+}
+
+func (c *Client) sendThroughBreaker(req *http.Request) (*http.Response, error) {
+	if c.breakers != nil {
+		return c.breakers.do(req, c.authorizedDo)
 	}
-	return c.client.Do(req)
+	return c.authorizedDo(req)
 }
 
 func (c *Client) addHeaderValues(header http.Header) {
-	_, ok := header[common.AuthorizationHeader] //Only add the bearer token if there isn't one there already
-	if c.bearer != nil && !ok {                 //APIs may not know how to respond if there is more than one entry - there are multiple ways to set one.
+	_, ok := header[common.AuthorizationHeader]    //Only add the bearer token if there isn't one there already
+	if c.bearer != nil && c.tokens == nil && !ok { //APIs may not know how to respond if there is more than one entry - there are multiple ways to set one.
 		header.Add(common.AuthorizationHeader, "Bearer "+*c.bearer)
 	}
 	_, ok = header[common.XTenantContextHeader]
@@ -86,6 +111,10 @@ func NewClient(opts ...Option) *Client {
 		opt(&client)
 	}
 
+	if client.debug == nil && os.Getenv("CURL_DEBUG") == "true" { //deprecated, use WithDebug
+		client.debug = &DebugSettings{Format: DebugCurl}
+	}
+
 	if client.client == nil {
 		client.client = cleanhttp.DefaultClient()
 		client.client.Timeout = client.HTTPTimeout
@@ -134,6 +163,22 @@ func WithHTTPTimeout(in time.Duration) Option {
 	}
 }
 
+// WithValue attaches value to the client under key, for the packages building on the client to keep their
+// client-wide settings, like graphql.WithInterceptors. Keys should be of unexported types, as with context.WithValue
+func WithValue(key, value interface{}) Option {
+	return func(c *Client) {
+		if c.values == nil {
+			c.values = map[interface{}]interface{}{}
+		}
+		c.values[key] = value
+	}
+}
+
+// Value returns the value attached to the client under key with WithValue, or nil
+func (c *Client) Value(key interface{}) interface{} {
+	return c.values[key]
+}
+
 // WithHTTPClient sets the underlying http client for use with requests, overrides default of http.DefaultClient
 func WithHTTPClient(hc *http.Client) Option {
 	if hc == nil {
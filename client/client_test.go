@@ -58,11 +58,13 @@ func TestLogger(t *testing.T) {
 
 func TestCurlDebug(t *testing.T) {
 	logger, hook := loggerWithHook(t)
-	c := NewClient(WithLogger(logger))
+	os.Setenv("CURL_DEBUG", "true")
+	c := NewClient(WithLogger(logger), WithBearerToken("secret"))
+	os.Unsetenv("CURL_DEBUG")
 
-	c.Logger = logger
 	fakeHandler := func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte(``))
 	}
@@ -70,14 +72,13 @@ func TestCurlDebug(t *testing.T) {
 	defer server.Close()
 
 	req, _ := http.NewRequest(http.MethodPost, server.URL, nil)
-	os.Setenv("CURL_DEBUG", "true")
-	defer os.Unsetenv("CURL_DEBUG")
+	req.Header.Set(log.XRequestID, "req")
 	resp, err := c.Do(req)
 	assert.Nil(t, err)
 	defer resp.Body.Close()
 	e := <-hook
 
-	assert.Equal(t, logrus.Fields{"command": "curl -X 'POST' -H 'Content-Type: application/json' '" + server.URL + "'"}, e.Data)
+	assert.Equal(t, logrus.Fields{"command": "curl -X 'POST' -H 'Authorization: [REDACTED]' -H 'Content-Type: application/json' -H 'X-Request-Id: req' '" + server.URL + "'"}, e.Data)
 }
 
 func TestBearer(t *testing.T) {
@@ -98,6 +99,36 @@ func TestBearer(t *testing.T) {
 	resp.Body.Close()
 }
 
+func TestTraceHeaders(t *testing.T) {
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ids = append(ids, r.Header.Get(log.XRequestID))
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", r.Header.Get(log.XTraceID))
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", r.Header.Get(log.Traceparent))
+		if len(ids) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	c := NewClient(WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond, RetryStatusCodes: []int{http.StatusServiceUnavailable}}))
+	ctx := log.CtxWithTraceContext(context.Background(), log.TraceContext{
+		TraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:  "00f067aa0ba902b7",
+	})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	resp, err := c.Do(req)
+	assert.Nil(t, err)
+	resp.Body.Close()
+
+	// a request id is generated once and shared by the retries
+	assert.Len(t, ids, 2)
+	assert.NotEmpty(t, ids[0])
+	assert.Equal(t, ids[0], ids[1])
+}
+
 func TestTimeouts(t *testing.T) {
 	c := NewClient(WithHTTPTimeout(10 * time.Millisecond))
 	fakeHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -196,3 +227,11 @@ func TestCustomHTTPClient(t *testing.T) {
 	assert.Equal(t, got, one)
 	assert.Equal(t, rd, one)
 }
+
+func TestValue(t *testing.T) {
+	type key struct{}
+	c := NewClient(WithValue(key{}, "value"))
+	assert.Equal(t, "value", c.Value(key{}))
+	assert.Nil(t, c.Value("missing"))
+	assert.Nil(t, NewClient().Value(key{}))
+}
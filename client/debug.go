@@ -0,0 +1,291 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"moul.io/http2curl"
+)
+
+// DebugFormat selects how WithDebug dumps requests
+type DebugFormat int
+
+const (
+	// DebugCurl dumps requests as curl commands
+	DebugCurl DebugFormat = iota
+	// DebugHTTP dumps requests and responses in the HTTP/1.1 wire format
+	DebugHTTP
+	// DebugFields dumps requests and responses as structured log fields
+	DebugFields
+)
+
+const (
+	// Redacted replaces the values hidden by a RedactionPolicy
+	Redacted = "[REDACTED]"
+
+	defaultMaxDebugBody = 64 * 1024
+)
+
+// RedactionPolicy lists what is hidden from debug dumps. Header and key names are case insensitive
+type RedactionPolicy struct {
+	// Headers are replaced entirely
+	Headers []string
+	// Cookies are the Cookie and Set-Cookie names whose values are replaced, "*" replaces every cookie value
+	Cookies []string
+	// BodyKeys are JSON object keys whose values are replaced at any depth of a request or response body,
+	// such as the credentials of a connectors.ConnectionInput
+	BodyKeys []string
+}
+
+// DefaultRedactionPolicy hides the auth headers, every cookie value and common secret fields
+func DefaultRedactionPolicy() RedactionPolicy {
+	return RedactionPolicy{
+		Headers:  []string{"Authorization", "Proxy-Authorization"},
+		Cookies:  []string{"*"},
+		BodyKeys: []string{"credentials", "password", "secret", "client_secret", "token", "access_token", "refresh_token"},
+	}
+}
+
+// DebugSettings configures WithDebug
+type DebugSettings struct {
+	Format DebugFormat
+	// IncludeResponseBody adds the response body to the response dump
+	IncludeResponseBody bool
+	// MaxBodyBytes truncates dumped bodies, defaults to 64KiB
+	MaxBodyBytes int
+	// Redaction defaults to DefaultRedactionPolicy
+	Redaction *RedactionPolicy
+	// Sink receives every dump, it defaults to logging msg with fields at debug level with the client logger
+	Sink func(msg string, fields map[string]interface{})
+}
+
+// WithDebug dumps every request before it is sent, and its response with the time it took, using settings.
+// Retried attempts are not dumped again
+func WithDebug(settings DebugSettings) Option {
+	return func(c *Client) {
+		c.debug = &settings
+	}
+}
+
+// dumpRequest dumps req and wraps send to dump the response
+func (c *Client) dumpRequest(req *http.Request, send func(*http.Request) (*http.Response, error)) func(*http.Request) (*http.Response, error) {
+	d := c.debug
+	policy := DefaultRedactionPolicy()
+	if d.Redaction != nil {
+		policy = *d.Redaction
+	}
+	sink := d.Sink
+	if sink == nil {
+		sink = func(msg string, fields map[string]interface{}) {
+			c.Logger.Debug().WithFields(fields).Msg(msg)
+		}
+	}
+	maxBody := d.MaxBodyBytes
+	if maxBody <= 0 {
+		maxBody = defaultMaxDebugBody
+	}
+
+	body, err := rewindBody(req)
+	if err != nil {
+		c.Logger.Warn().WithError(err).Msg("failed reading request body for debugging")
+		return send
+	}
+	header := policy.redactHeader(req.Header, "Cookie")
+	body = truncate(policy.redactBody(body), maxBody)
+
+	switch d.Format {
+	case DebugCurl:
+		command, err := http2curl.GetCurlCommand(redactedRequest(req, header, body))
+		if err != nil {
+			c.Logger.Warn().WithError(err).Msg("curl not found")
+		} else {
+			sink("curl command", map[string]interface{}{"command": command.String()})
+		}
+	case DebugHTTP:
+		raw, err := httputil.DumpRequestOut(redactedRequest(req, header, body), true)
+		if err != nil {
+			c.Logger.Warn().WithError(err).Msg("failed dumping request")
+		} else {
+			sink("http request", map[string]interface{}{"request": string(raw)})
+		}
+	default:
+		sink("http request", map[string]interface{}{
+			"method":  req.Method,
+			"url":     req.URL.String(),
+			"headers": flattenHeader(header),
+			"body":    string(body),
+		})
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := send(req)
+		fields := map[string]interface{}{"duration": time.Since(start).String()}
+		if err != nil {
+			fields["error"] = err.Error()
+			sink("http response", fields)
+			return resp, err
+		}
+
+		var respBody []byte
+		if d.IncludeResponseBody {
+			if respBody, err = ioutil.ReadAll(resp.Body); err != nil {
+				return nil, err
+			}
+			_ = resp.Body.Close()
+			resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+			respBody = truncate(policy.redactBody(respBody), maxBody)
+		}
+		respHeader := policy.redactHeader(resp.Header, "Set-Cookie")
+
+		switch d.Format {
+		case DebugHTTP:
+			dumped := *resp
+			dumped.Header = respHeader
+			dumped.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+			dumped.ContentLength = int64(len(respBody))
+			dumped.TransferEncoding = nil
+			raw, err := httputil.DumpResponse(&dumped, d.IncludeResponseBody)
+			if err != nil {
+				c.Logger.Warn().WithError(err).Msg("failed dumping response")
+				break
+			}
+			fields["response"] = string(raw)
+		default:
+			fields["status"] = resp.StatusCode
+			if d.Format == DebugFields {
+				fields["headers"] = flattenHeader(respHeader)
+			}
+			if d.IncludeResponseBody {
+				fields["body"] = string(respBody)
+			}
+		}
+		sink("http response", fields)
+		return resp, nil
+	}
+}
+
+func redactedRequest(req *http.Request, header http.Header, body []byte) *http.Request {
+	out := req.Clone(req.Context())
+	out.Header = header
+	out.Body, out.ContentLength = nil, 0
+	if len(body) > 0 {
+		out.Body = ioutil.NopCloser(bytes.NewReader(body))
+		out.ContentLength = int64(len(body))
+	}
+	return out
+}
+
+// redactHeader returns a copy of header without the policy headers and cookie values.
+// cookieHeader is the header holding cookies, Cookie for requests and Set-Cookie for responses
+func (p RedactionPolicy) redactHeader(header http.Header, cookieHeader string) http.Header {
+	out := header.Clone()
+	if out == nil {
+		return http.Header{}
+	}
+	for _, h := range p.Headers {
+		if _, ok := out[http.CanonicalHeaderKey(h)]; ok {
+			out.Set(h, Redacted)
+		}
+	}
+	if cookies, ok := out[cookieHeader]; ok && len(p.Cookies) > 0 {
+		for i, v := range cookies {
+			cookies[i] = p.redactCookies(v, cookieHeader == "Set-Cookie")
+		}
+	}
+	return out
+}
+
+// redactCookies redacts a Cookie header value, or a Set-Cookie header value when setCookie is true
+func (p RedactionPolicy) redactCookies(v string, setCookie bool) string {
+	parts := strings.Split(v, ";")
+	for i, part := range parts {
+		if setCookie && i > 0 { //cookie attributes
+			break
+		}
+		kv := strings.SplitN(strings.TrimSpace(part), "=", 2)
+		if len(kv) == 2 && p.redactCookie(kv[0]) {
+			parts[i] = fmt.Sprintf("%s%s=%s", leadingSpace(part), kv[0], Redacted)
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+func (p RedactionPolicy) redactCookie(name string) bool {
+	for _, c := range p.Cookies {
+		if c == "*" || strings.EqualFold(c, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func leadingSpace(s string) string {
+	return s[:len(s)-len(strings.TrimLeft(s, " "))]
+}
+
+// redactBody redacts the policy keys of a JSON body, other bodies are returned as they are
+func (p RedactionPolicy) redactBody(body []byte) []byte {
+	if len(body) == 0 || len(p.BodyKeys) == 0 {
+		return body
+	}
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return body
+	}
+	out, err := json.Marshal(p.redactValue(v))
+	if err != nil {
+		return body
+	}
+	return out
+}
+
+func (p RedactionPolicy) redactValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			if p.redactKey(k) {
+				v[k] = Redacted
+			} else {
+				v[k] = p.redactValue(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range v {
+			v[i] = p.redactValue(val)
+		}
+	}
+	return v
+}
+
+func (p RedactionPolicy) redactKey(key string) bool {
+	for _, k := range p.BodyKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncate(body []byte, max int) []byte {
+	if len(body) <= max {
+		return body
+	}
+	return append(body[:max:max], []byte("...(truncated)")...)
+}
+
+func flattenHeader(header http.Header) map[string]string {
+	out := make(map[string]string, len(header))
+	for k, v := range header {
+		out[k] = strings.Join(v, ", ")
+	}
+	return out
+}
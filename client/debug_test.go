@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type debugEntry struct {
+	msg    string
+	fields map[string]interface{}
+}
+
+func debugClient(settings DebugSettings, opts ...Option) (*Client, *[]debugEntry) {
+	var entries []debugEntry
+	settings.Sink = func(msg string, fields map[string]interface{}) {
+		entries = append(entries, debugEntry{msg: msg, fields: fields})
+	}
+	return NewClient(append(opts, WithDebug(settings))...), &entries
+}
+
+func secretServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "hunter2", "only the dump is redacted")
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		_, _ = w.Write([]byte(`{"data":{"connection":{"id":"1","credentials":{"apiKey":"hunter2"}}}}`))
+	}))
+}
+
+const secretBody = `{"query":"mutation { createConnection }","variables":{"input":{"name":"c","credentials":{"apiKey":"hunter2"}}}}`
+
+func TestDebugFields(t *testing.T) {
+	srv := secretServer(t)
+	defer srv.Close()
+	c, entries := debugClient(DebugSettings{Format: DebugFields, IncludeResponseBody: true}, WithBearerToken("token"))
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(secretBody))
+	require.NoError(t, err)
+	req.Header.Add("Cookie", "access_token=token; other=1")
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "hunter2", "the caller still gets the response body")
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, *entries, 2)
+	reqDump := (*entries)[0].fields
+	assert.Equal(t, http.MethodPost, reqDump["method"])
+	headers := reqDump["headers"].(map[string]string)
+	assert.Equal(t, Redacted, headers["Authorization"])
+	assert.Equal(t, "access_token=[REDACTED]; other=[REDACTED]", headers["Cookie"])
+	assert.NotContains(t, reqDump["body"], "hunter2")
+	assert.Contains(t, reqDump["body"], `"credentials":"[REDACTED]"`)
+
+	respDump := (*entries)[1].fields
+	assert.Equal(t, http.StatusOK, respDump["status"])
+	assert.NotEmpty(t, respDump["duration"])
+	assert.Equal(t, "session=[REDACTED]; Path=/", respDump["headers"].(map[string]string)["Set-Cookie"])
+	assert.NotContains(t, respDump["body"], "hunter2")
+	assert.Contains(t, respDump["body"], `"id":"1"`)
+}
+
+func TestDebugHTTP(t *testing.T) {
+	srv := secretServer(t)
+	defer srv.Close()
+	c, entries := debugClient(DebugSettings{Format: DebugHTTP, IncludeResponseBody: true, MaxBodyBytes: 40}, WithBearerToken("token"))
+
+	resp, err := c.Do(mustRequest(t, srv.URL, secretBody))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, *entries, 2)
+	raw := (*entries)[0].fields["request"].(string)
+	assert.True(t, strings.HasPrefix(raw, "POST / HTTP/1.1\r\n"), raw)
+	assert.Contains(t, raw, "Authorization: [REDACTED]\r\n")
+	assert.Contains(t, raw, "...(truncated)")
+	assert.NotContains(t, raw, "token")
+
+	raw = (*entries)[1].fields["response"].(string)
+	assert.True(t, strings.HasPrefix(raw, "HTTP/1.1 200 OK\r\n"), raw)
+	assert.NotContains(t, raw, "hunter2")
+}
+
+func TestDebugCustomRedaction(t *testing.T) {
+	srv := secretServer(t)
+	defer srv.Close()
+	c, entries := debugClient(DebugSettings{
+		Format:    DebugFields,
+		Redaction: &RedactionPolicy{Cookies: []string{"access_token"}, BodyKeys: []string{"apiKey"}},
+	})
+
+	req := mustRequest(t, srv.URL, secretBody)
+	req.Header.Add("Cookie", "access_token=token; other=1")
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	reqDump := (*entries)[0].fields
+	assert.Equal(t, "access_token=[REDACTED]; other=1", reqDump["headers"].(map[string]string)["Cookie"])
+	assert.Contains(t, reqDump["body"], `"credentials":{"apiKey":"[REDACTED]"}`)
+	assert.NotContains(t, (*entries)[1].fields, "body")
+}
+
+func mustRequest(t *testing.T, url, body string) *http.Request {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	return req
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/internal/operation"
+)
+
+// RequestMetrics describes a single call of Client.Do, retries included
+type RequestMetrics struct {
+	Method string
+	// Endpoint is the host and path of the request URL
+	Endpoint string
+	// Operation is the GraphQL operation name, see OperationName. It is empty for non GraphQL requests
+	Operation string
+	// StatusCode is 0 when no response was received
+	StatusCode    int
+	Err           error
+	Duration      time.Duration
+	RequestBytes  int64
+	ResponseBytes int64
+}
+
+// Instrumentation receives the metrics of every request made by a Client
+type Instrumentation interface {
+	ObserveRequest(m RequestMetrics)
+}
+
+// WithInstrumentation reports the metrics of every request to in. Requests with a response are reported once
+// their body is read to the end or closed, so the duration and response size cover the whole body
+func WithInstrumentation(in Instrumentation) Option {
+	return func(c *Client) {
+		c.metrics = in
+	}
+}
+
+// doInstrumented runs send and reports the request to c.metrics
+func (c *Client) doInstrumented(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	body, err := rewindBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	m := RequestMetrics{
+		Method:       req.Method,
+		Endpoint:     req.URL.Host + req.URL.Path,
+		Operation:    operationFromBody(body),
+		RequestBytes: int64(len(body)),
+	}
+	start := time.Now()
+	resp, err := send(req)
+	if err != nil || resp == nil {
+		m.Err = err
+		m.Duration = time.Since(start)
+		c.metrics.ObserveRequest(m)
+		return resp, err
+	}
+
+	m.StatusCode = resp.StatusCode
+	resp.Body = &countingBody{ReadCloser: resp.Body, done: func(n int64, err error) {
+		m.ResponseBytes = n
+		m.Err = err
+		m.Duration = time.Since(start)
+		c.metrics.ObserveRequest(m)
+	}}
+	return resp, nil
+}
+
+// countingBody counts the bytes read from a response body and calls done once, at EOF, on a read error or on close
+type countingBody struct {
+	io.ReadCloser
+	n    int64
+	once sync.Once
+	done func(n int64, err error)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	if err == io.EOF {
+		b.finish(nil)
+	} else if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish(nil)
+	return err
+}
+
+func (b *countingBody) finish(err error) {
+	b.once.Do(func() { b.done(b.n, err) })
+}
+
+func operationFromBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var gqlReq struct {
+		Query         string `json:"query"`
+		OperationName string `json:"operationName"`
+	}
+	if err := json.Unmarshal(body, &gqlReq); err != nil {
+		return ""
+	}
+	if gqlReq.OperationName != "" {
+		return gqlReq.OperationName
+	}
+	return OperationName(gqlReq.Query)
+}
+
+// OperationName returns the name of the first operation in a GraphQL query. Anonymous operations are
+// named after their first field, so `query { assets { id } }` is named "assets"
+func OperationName(query string) string {
+	return operation.Name(query)
+}
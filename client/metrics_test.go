@@ -0,0 +1,76 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type instrumentationFunc func(m RequestMetrics)
+
+func (f instrumentationFunc) ObserveRequest(m RequestMetrics) { f(m) }
+
+func TestOperationName(t *testing.T) {
+	for query, want := range map[string]string{
+		`query GetAssets($ids: [ID!]) { assets(ids: $ids) { id } }`: "GetAssets",
+		`# comment
+		mutation createPlaybook { createPlaybook { id } }`: "createPlaybook",
+		`subscription{ messageAdded(roomName: "test") { text } }`: "messageAdded",
+		`query ($id: ID!) { alias: asset(id: $id) { id } }`:      "asset",
+		`{ tenants { id } }`: "tenants",
+		``:                   "",
+	} {
+		assert.Equal(t, want, OperationName(query), query)
+	}
+}
+
+func TestInstrumentation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"data":{}}`))
+	}))
+	defer server.Close()
+
+	var observed []RequestMetrics
+	c := NewClient(WithInstrumentation(instrumentationFunc(func(m RequestMetrics) {
+		observed = append(observed, m)
+	})))
+
+	body := []byte(`{"query":"query { assets { id } }","operationName":"ListAssets"}`)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, server.URL+"/graphql", bytes.NewReader(body))
+	require.NoError(t, err)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	assert.Empty(t, observed, "reported once the body is consumed")
+
+	_, err = ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	require.Len(t, observed, 1)
+	m := observed[0]
+	assert.Equal(t, http.MethodPost, m.Method)
+	assert.Equal(t, req.URL.Host+"/graphql", m.Endpoint)
+	assert.Equal(t, "ListAssets", m.Operation)
+	assert.Equal(t, http.StatusCreated, m.StatusCode)
+	assert.Equal(t, int64(len(body)), m.RequestBytes)
+	assert.Equal(t, int64(len(`{"data":{}}`)), m.ResponseBytes)
+	assert.NoError(t, m.Err)
+	assert.True(t, m.Duration > 0)
+
+	// failed requests are reported straight away
+	server.Close()
+	req, err = http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	_, err = c.Do(req)
+	require.Error(t, err)
+	require.Len(t, observed, 2)
+	assert.Equal(t, 0, observed[1].StatusCode)
+	assert.Error(t, observed[1].Err)
+}
@@ -0,0 +1,235 @@
+package client
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/internal/sentinel"
+)
+
+const (
+	// adaptiveMinFactor is the lowest fraction of its configured rate an adaptive bucket slows down to
+	adaptiveMinFactor = 1.0 / 16
+	// adaptiveRecoverFactor is the fraction of its configured rate an adaptive bucket recovers per successful request
+	adaptiveRecoverFactor = 1.0 / 32
+)
+
+// ErrRateLimited is returned, wrapped, by Client.Do when a fail fast rate limit has no token available
+var ErrRateLimited = sentinel.ErrRateLimited
+
+// RateLimit is a token bucket limit, a zero Rate means no limit
+type RateLimit struct {
+	// Rate is the number of requests per second
+	Rate float64
+	// Burst is the number of requests which can be sent at once, defaults to 1
+	Burst int
+}
+
+// RateLimitSettings configures the client side rate limiting of the Client.
+// Limits are applied globally and per tenant, the tenant being the X-Tenant-Context header of the request
+type RateLimitSettings struct {
+	// Global limits all requests sent by the Client
+	Global RateLimit
+	// PerTenant is the default limit of every tenant
+	PerTenant RateLimit
+	// Tenants overrides PerTenant for specific tenant ids
+	Tenants map[string]RateLimit
+	// FailFast makes requests fail with ErrRateLimited instead of waiting for a token
+	FailFast bool
+	// Adaptive halves the rate of a bucket every time the API answers 429 Too Many Requests,
+	// the rate is restored gradually with each successful request
+	Adaptive bool
+}
+
+// WithRateLimit enables client side rate limiting, applied to every attempt of a request
+func WithRateLimit(settings RateLimitSettings) Option {
+	return func(c *Client) {
+		c.limiter = &rateLimiter{
+			settings: settings,
+			global:   newBucket(settings.Global),
+			tenants:  map[string]*bucket{},
+			now:      time.Now,
+		}
+	}
+}
+
+type rateLimiter struct {
+	settings RateLimitSettings
+	global   *bucket
+	now      func() time.Time
+
+	mu      sync.Mutex
+	tenants map[string]*bucket
+}
+
+// do waits for, or fails fast without, a token for the request before sending it
+func (l *rateLimiter) do(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	tenant := req.Header.Get(common.XTenantContextHeader)
+	buckets := l.buckets(tenant)
+
+	if err := l.take(req, tenant, buckets); err != nil {
+		return nil, err
+	}
+
+	resp, err := send(req)
+	if l.settings.Adaptive && err == nil {
+		now := l.now()
+		for _, b := range buckets {
+			if resp.StatusCode == http.StatusTooManyRequests {
+				b.throttle(now)
+			} else {
+				b.recover(now)
+			}
+		}
+	}
+	return resp, err
+}
+
+func (l *rateLimiter) take(req *http.Request, tenant string, buckets []*bucket) error {
+	now := l.now()
+	if l.settings.FailFast {
+		for i, b := range buckets {
+			if !b.tryTake(now) {
+				for _, taken := range buckets[:i] {
+					taken.giveBack()
+				}
+				return fmt.Errorf("%w for tenant %q", ErrRateLimited, tenant)
+			}
+		}
+		return nil
+	}
+
+	var wait time.Duration
+	for _, b := range buckets {
+		if d := b.reserve(now); d > wait {
+			wait = d
+		}
+	}
+	if wait <= 0 {
+		return nil
+	}
+	if err := sleepCtx(req.Context(), wait); err != nil {
+		for _, b := range buckets {
+			b.giveBack()
+		}
+		return err
+	}
+	return nil
+}
+
+// buckets returns the global and tenant buckets which apply to tenant, unlimited buckets are left out
+func (l *rateLimiter) buckets(tenant string) []*bucket {
+	buckets := make([]*bucket, 0, 2)
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	if tenant == "" {
+		return buckets
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.tenants[tenant]
+	if !ok {
+		limit, found := l.settings.Tenants[tenant]
+		if !found {
+			limit = l.settings.PerTenant
+		}
+		b = newBucket(limit)
+		l.tenants[tenant] = b
+	}
+	if b != nil {
+		buckets = append(buckets, b)
+	}
+	return buckets
+}
+
+// bucket is a token bucket, tokens may go negative to queue up waiting requests
+type bucket struct {
+	mu      sync.Mutex
+	maxRate float64
+	rate    float64
+	burst   float64
+	tokens  float64
+	last    time.Time
+}
+
+func newBucket(limit RateLimit) *bucket {
+	if limit.Rate <= 0 {
+		return nil
+	}
+	burst := float64(limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+	return &bucket{
+		maxRate: limit.Rate,
+		rate:    limit.Rate,
+		burst:   burst,
+		tokens:  burst,
+	}
+}
+
+// refill adds the tokens earned since the last call, it must be called with the lock held
+func (b *bucket) refill(now time.Time) {
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+}
+
+// reserve takes a token and returns how long to wait before it is available
+func (b *bucket) reserve(now time.Time) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func (b *bucket) tryTake(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *bucket) giveBack() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+}
+
+func (b *bucket) throttle(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill(now)
+	b.rate = math.Max(b.rate/2, b.maxRate*adaptiveMinFactor)
+}
+
+func (b *bucket) recover(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate >= b.maxRate {
+		return
+	}
+	b.refill(now)
+	b.rate = math.Min(b.rate+b.maxRate*adaptiveRecoverFactor, b.maxRate)
+}
+
+func (b *bucket) currentRate() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+)
+
+func okServer(calls *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(calls, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+func doTenant(ctx context.Context, c *Client, url, tenant string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, nil)
+	if err != nil {
+		return err
+	}
+	if tenant != "" {
+		req.Header.Set(common.XTenantContextHeader, tenant)
+	}
+	resp, err := c.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestRateLimitFailFastPerTenant(t *testing.T) {
+	var calls uint64
+	srv := okServer(&calls)
+	defer srv.Close()
+
+	c := NewClient(WithRateLimit(RateLimitSettings{
+		PerTenant: RateLimit{Rate: 0.001, Burst: 2},
+		Tenants:   map[string]RateLimit{"big": {Rate: 0.001, Burst: 3}},
+		FailFast:  true,
+	}))
+	ctx := context.Background()
+
+	require.NoError(t, doTenant(ctx, c, srv.URL, "small"))
+	require.NoError(t, doTenant(ctx, c, srv.URL, "small"))
+	err := doTenant(ctx, c, srv.URL, "small")
+	assert.True(t, errors.Is(err, ErrRateLimited))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, doTenant(ctx, c, srv.URL, "big"))
+	}
+	assert.True(t, errors.Is(doTenant(ctx, c, srv.URL, "big"), ErrRateLimited))
+
+	// requests without a tenant only use the global limit, which is unset
+	require.NoError(t, doTenant(ctx, c, srv.URL, ""))
+	assert.Equal(t, uint64(6), atomic.LoadUint64(&calls))
+}
+
+func TestRateLimitFailFastGivesBackGlobalToken(t *testing.T) {
+	var calls uint64
+	srv := okServer(&calls)
+	defer srv.Close()
+
+	c := NewClient(WithRateLimit(RateLimitSettings{
+		Global:    RateLimit{Rate: 0.001, Burst: 1},
+		PerTenant: RateLimit{Rate: 0.001, Burst: 1},
+		FailFast:  true,
+	}))
+	c.limiter.tenants["used"] = &bucket{maxRate: 0.001, rate: 0.001, burst: 1}
+
+	assert.True(t, errors.Is(doTenant(context.Background(), c, srv.URL, "used"), ErrRateLimited))
+	require.NoError(t, doTenant(context.Background(), c, srv.URL, "fresh"))
+}
+
+func TestRateLimitWaits(t *testing.T) {
+	var calls uint64
+	srv := okServer(&calls)
+	defer srv.Close()
+
+	c := NewClient(WithRateLimit(RateLimitSettings{Global: RateLimit{Rate: 20, Burst: 1}}))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		require.NoError(t, doTenant(context.Background(), c, srv.URL, "tenant"))
+	}
+	assert.True(t, time.Since(start) >= 90*time.Millisecond)
+}
+
+func TestRateLimitWaitHonoursContext(t *testing.T) {
+	var calls uint64
+	srv := okServer(&calls)
+	defer srv.Close()
+
+	c := NewClient(WithRateLimit(RateLimitSettings{Global: RateLimit{Rate: 0.01, Burst: 1}}))
+	require.NoError(t, doTenant(context.Background(), c, srv.URL, ""))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, doTenant(ctx, c, srv.URL, ""))
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+}
+
+func TestRateLimitAdaptive(t *testing.T) {
+	var throttled int32 = 1
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.LoadInt32(&throttled) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithRateLimit(RateLimitSettings{
+		PerTenant: RateLimit{Rate: 1000, Burst: 100},
+		Adaptive:  true,
+	}))
+
+	require.NoError(t, doTenant(context.Background(), c, srv.URL, "t"))
+	require.NoError(t, doTenant(context.Background(), c, srv.URL, "t"))
+	b := c.limiter.tenants["t"]
+	assert.Equal(t, 250.0, b.currentRate())
+
+	atomic.StoreInt32(&throttled, 0)
+	require.NoError(t, doTenant(context.Background(), c, srv.URL, "t"))
+	assert.Equal(t, 250.0+1000*adaptiveRecoverFactor, b.currentRate())
+}
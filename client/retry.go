@@ -0,0 +1,222 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/internal/operation"
+)
+
+const (
+	defaultRetryAttempts  = 3
+	defaultRetryBaseDelay = 100 * time.Millisecond
+	defaultRetryMaxDelay  = 5 * time.Second
+	defaultRetryJitter    = 0.2
+)
+
+// RetryPolicy defines how the Client retries requests that failed with a transient error.
+// GraphQL queries are retried by default, mutations are only retried when RetryMutations is set
+// as a mutation like IsolateAsset or CreateRule is not safe to repeat
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, it doubles with every following attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay which is randomised
+	Jitter float64
+	// RetryMutations allows GraphQL mutations to be retried
+	RetryMutations bool
+	// RetryStatusCodes are the response codes considered transient, defaults to 502, 503 and 504
+	RetryStatusCodes []int
+}
+
+// DefaultRetryPolicy returns a RetryPolicy making up to 3 attempts with an exponential backoff starting at 100ms
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultRetryAttempts,
+		BaseDelay:   defaultRetryBaseDelay,
+		MaxDelay:    defaultRetryMaxDelay,
+		Jitter:      defaultRetryJitter,
+		RetryStatusCodes: []int{
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+// WithRetryPolicy enables retrying of requests failing with a transient error, like a 503 or a connection reset
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = &p
+	}
+}
+
+func (p *RetryPolicy) retryableStatus(code int) bool {
+	for _, sc := range p.RetryStatusCodes {
+		if sc == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before the given attempt, attempts are counted from 1
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64() //nolint:gosec // jitter does not need a secure source
+	}
+	return time.Duration(delay)
+}
+
+// doWithRetry runs send until it succeeds, fails with a permanent error or the attempts run out.
+// The request body is buffered so it can be resent on every attempt.
+func (c *Client) doWithRetry(req *http.Request, send func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	body, err := rewindBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	p := c.retry
+	if p.MaxAttempts <= 1 || (!p.RetryMutations && isMutation(body)) {
+		return send(req)
+	}
+
+	ctx := req.Context()
+	attemptReq := req
+	for attempt := 1; ; attempt++ {
+		resp, err := send(attemptReq)
+
+		last := attempt >= p.MaxAttempts || ctx.Err() != nil
+		var delay time.Duration
+		switch {
+		case err != nil:
+			if last || !isRetryableError(err) {
+				return resp, err
+			}
+			delay = p.backoff(attempt)
+		case p.retryableStatus(resp.StatusCode) || resp.StatusCode == http.StatusTooManyRequests:
+			if last {
+				return resp, nil
+			}
+			delay = p.backoff(attempt)
+			if ra, ok := retryAfter(resp.Header); ok && ra > delay {
+				delay = ra
+			}
+			drainBody(resp.Body)
+		default:
+			return resp, nil
+		}
+
+		c.Logger.Debug().WithInt("attempt", attempt).WithDur("delay", delay).WithError(err).Msg("retrying request")
+
+		if err := sleepCtx(ctx, delay); err != nil {
+			return nil, err
+		}
+
+		attemptReq = req.Clone(ctx)
+		if attemptReq.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// rewindBody reads the request body into memory and sets GetBody so the body can be sent again
+func rewindBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		req.GetBody = func() (io.ReadCloser, error) { return http.NoBody, nil }
+		return nil, nil
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return body, nil
+}
+
+// isMutation reports whether body is a GraphQL request for a mutation
+func isMutation(body []byte) bool {
+	if len(body) == 0 {
+		return false
+	}
+	var gqlReq struct {
+		Query string `json:"query"`
+	}
+	if err := json.Unmarshal(body, &gqlReq); err != nil {
+		return false
+	}
+	return operation.Type(gqlReq.Query) == operation.Mutation
+}
+
+func isRetryableError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// retryAfter parses the Retry-After header which is either a number of seconds or an HTTP date
+func retryAfter(header http.Header) (time.Duration, bool) {
+	v := header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		d := time.Until(t)
+		if d < 0 {
+			d = 0
+		}
+		return d, true
+	}
+	return 0, false
+}
+
+func drainBody(body io.ReadCloser) {
+	_, _ = io.Copy(ioutil.Discard, io.LimitReader(body, 4096))
+	_ = body.Close()
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
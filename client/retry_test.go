@@ -0,0 +1,189 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRetryPolicy() RetryPolicy {
+	p := DefaultRetryPolicy()
+	p.BaseDelay = time.Millisecond
+	p.MaxDelay = 5 * time.Millisecond
+	return p
+}
+
+func flakyServer(t *testing.T, failures uint64, status int, calls *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		if atomic.AddUint64(calls, 1) <= failures {
+			w.WriteHeader(status)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+}
+
+func TestRetryQuery(t *testing.T) {
+	var calls uint64
+	srv := flakyServer(t, 2, http.StatusServiceUnavailable, &calls)
+	defer srv.Close()
+
+	c := NewClient(WithRetryPolicy(testRetryPolicy()))
+	const body = `{"query":"query { allUniqueTags }"}`
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(body))
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, uint64(3), atomic.LoadUint64(&calls))
+	out, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, body, string(out))
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	var calls uint64
+	srv := flakyServer(t, 10, http.StatusBadGateway, &calls)
+	defer srv.Close()
+
+	c := NewClient(WithRetryPolicy(testRetryPolicy()))
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(`{"query":"query { a }"}`))
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, uint64(3), atomic.LoadUint64(&calls))
+}
+
+func TestRetrySkipsMutations(t *testing.T) {
+	var calls uint64
+	srv := flakyServer(t, 1, http.StatusServiceUnavailable, &calls)
+	defer srv.Close()
+
+	const body = `{"query":"# isolate\n mutation { isolateAsset(id: \"1\") { id } }"}`
+
+	c := NewClient(WithRetryPolicy(testRetryPolicy()))
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+
+	//a fragment definition may come first
+	atomic.StoreUint64(&calls, 0)
+	req, err = http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(
+		`{"query":"fragment asset on Asset { id }\n mutation { isolateAsset(id: \"1\") { ...asset } }"}`))
+	require.NoError(t, err)
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+
+	p := testRetryPolicy()
+	p.RetryMutations = true
+	c = NewClient(WithRetryPolicy(p))
+	atomic.StoreUint64(&calls, 0)
+	req, err = http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(body))
+	require.NoError(t, err)
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+}
+
+func TestRetryHonoursRetryAfter(t *testing.T) {
+	var calls uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddUint64(&calls, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithRetryPolicy(testRetryPolicy()))
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(`{"query":"query { a }"}`))
+	require.NoError(t, err)
+
+	start := time.Now()
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, time.Since(start) >= time.Second)
+}
+
+func TestRetryStopsOnContextCancel(t *testing.T) {
+	var calls uint64
+	srv := flakyServer(t, 10, http.StatusServiceUnavailable, &calls)
+	defer srv.Close()
+
+	p := testRetryPolicy()
+	p.MaxAttempts = 10
+	p.BaseDelay = time.Second
+	p.MaxDelay = time.Second
+	c := NewClient(WithRetryPolicy(p))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, srv.URL, bytes.NewBufferString(`{"query":"query { a }"}`))
+	require.NoError(t, err)
+
+	_, err = c.Do(req) //nolint: bodyclose no body is returned with an error
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&calls))
+}
+
+func TestRetryTimeout(t *testing.T) {
+	var calls uint64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddUint64(&calls, 1) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	c := NewClient(WithRetryPolicy(testRetryPolicy()), WithHTTPTimeout(20*time.Millisecond))
+	req, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewBufferString(`{"query":"query { a }"}`))
+	require.NoError(t, err)
+
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+}
+
+func TestRetryBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	assert.Equal(t, 100*time.Millisecond, p.backoff(1))
+	assert.Equal(t, 200*time.Millisecond, p.backoff(2))
+	assert.Equal(t, 300*time.Millisecond, p.backoff(3))
+
+	p.Jitter = 0.5
+	for i := 0; i < 10; i++ {
+		d := p.backoff(1)
+		assert.True(t, d >= 50*time.Millisecond && d <= 100*time.Millisecond)
+	}
+}
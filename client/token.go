@@ -0,0 +1,172 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+
+	"github.com/dgrijalva/jwt-go"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+const defaultRefreshBefore = time.Minute
+
+// ClientCredentials holds the OAuth2 client credentials used to get access tokens for the API
+type ClientCredentials struct {
+	ClientID     string
+	ClientSecret string
+	TokenURL     string
+	Scopes       []string
+	// RefreshBefore is how long before its expiry a token is refreshed, defaults to 1 minute
+	RefreshBefore time.Duration
+	// HTTPClient is used to call the TokenURL, defaults to a clean http client
+	HTTPClient *http.Client
+}
+
+// TokenSource is an oauth2.TokenSource caching the access token from the client credentials flow.
+// The token is refreshed RefreshBefore its expiry, which is read from the JWT exp claim when present.
+// It is safe for concurrent use and can be shared between a Client and graphql.SubscriptionWithTokenSource
+type TokenSource struct {
+	fetch         func() (*oauth2.Token, error)
+	refreshBefore time.Duration
+	now           func() time.Time
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+var _ oauth2.TokenSource = (*TokenSource)(nil)
+
+// NewClientCredentialsTokenSource creates a TokenSource fetching tokens with the given client credentials
+func NewClientCredentialsTokenSource(creds ClientCredentials) *TokenSource {
+	conf := &clientcredentials.Config{
+		ClientID:     creds.ClientID,
+		ClientSecret: creds.ClientSecret,
+		TokenURL:     creds.TokenURL,
+		Scopes:       creds.Scopes,
+	}
+	ctx := context.Background()
+	if creds.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, creds.HTTPClient)
+	}
+	return newTokenSource(func() (*oauth2.Token, error) {
+		return conf.Token(ctx)
+	}, creds.RefreshBefore)
+}
+
+func newTokenSource(fetch func() (*oauth2.Token, error), refreshBefore time.Duration) *TokenSource {
+	if refreshBefore <= 0 {
+		refreshBefore = defaultRefreshBefore
+	}
+	return &TokenSource{
+		fetch:         fetch,
+		refreshBefore: refreshBefore,
+		now:           time.Now,
+	}
+}
+
+// Token returns the cached token, fetching a new one if it is missing or about to expire
+func (ts *TokenSource) Token() (*oauth2.Token, error) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.token != nil && (ts.token.Expiry.IsZero() || ts.now().Add(ts.refreshBefore).Before(ts.token.Expiry)) {
+		return ts.token, nil
+	}
+
+	tok, err := ts.fetch()
+	if err != nil {
+		return nil, fmt.Errorf("ctpx-sdk-go/client: failed fetching access token: %w", err)
+	}
+	if exp, ok := jwtExpiry(tok.AccessToken); ok {
+		tok.Expiry = exp
+	}
+	ts.token = tok
+	return tok, nil
+}
+
+// Invalidate drops the cached token so the next call to Token fetches a new one
+func (ts *TokenSource) Invalidate() {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.token = nil
+}
+
+// jwtExpiry reads the exp claim of an access token, without verifying it, if the token is a JWT
+func jwtExpiry(accessToken string) (time.Time, bool) {
+	claims := jwt.MapClaims{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(accessToken, claims); err != nil {
+		return time.Time{}, false
+	}
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.Unix(int64(exp), 0), true
+}
+
+// WithTokenSource sets the source of the bearer token added to every request, it takes precedence over WithBearerToken.
+// When the API answers 401 and the source is a *TokenSource the token is refreshed and the request sent once more
+func WithTokenSource(ts oauth2.TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = ts
+	}
+}
+
+// WithClientCredentials creates a TokenSource for the given credentials and uses it with WithTokenSource
+func WithClientCredentials(creds ClientCredentials) Option {
+	return WithTokenSource(NewClientCredentialsTokenSource(creds))
+}
+
+// TokenSource returns the token source set with WithTokenSource or WithClientCredentials, nil otherwise.
+// It can be passed to graphql.SubscriptionWithTokenSource so subscriptions share the same token
+func (c *Client) TokenSource() oauth2.TokenSource {
+	return c.tokens
+}
+
+// authorizedDo sends the request with a token from the token source, re-authenticating once on a 401
+func (c *Client) authorizedDo(req *http.Request) (*http.Response, error) {
+	if c.tokens == nil || req.Header.Get(common.AuthorizationHeader) != "" {
+		return c.client.Do(req)
+	}
+	if req.GetBody == nil {
+		if _, err := rewindBody(req); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.doWithToken(req, req.Body)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	ts, ok := c.tokens.(*TokenSource)
+	if !ok {
+		return resp, nil
+	}
+	drainBody(resp.Body)
+	ts.Invalidate()
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	c.Logger.Debug().Msg("request unauthorized, refreshed access token")
+	return c.doWithToken(req, body)
+}
+
+func (c *Client) doWithToken(req *http.Request, body io.ReadCloser) (*http.Response, error) {
+	tok, err := c.tokens.Token()
+	if err != nil {
+		return nil, err
+	}
+	r := req.Clone(req.Context())
+	r.Body = body
+	tok.SetAuthHeader(r)
+	return c.client.Do(r)
+}
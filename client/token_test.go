@@ -0,0 +1,123 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+)
+
+func newTokenServer(t *testing.T, exp time.Time, fetches *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddUint64(fetches, 1)
+		user, pass, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "id", user)
+		assert.Equal(t, "secret", pass)
+
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"exp": exp.Unix(),
+			"n":   n,
+		})
+		signed, err := token.SignedString([]byte("key"))
+		require.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/x-www-form-urlencoded")
+		_, _ = fmt.Fprintf(w, "access_token=%s&token_type=bearer&expires_in=3600", signed)
+	}))
+}
+
+func TestTokenSourceCachesAndRefreshes(t *testing.T) {
+	var fetches uint64
+	exp := time.Now().Add(10 * time.Minute).Truncate(time.Second)
+	authSrv := newTokenServer(t, exp, &fetches)
+	defer authSrv.Close()
+
+	ts := NewClientCredentialsTokenSource(ClientCredentials{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		TokenURL:     authSrv.URL,
+	})
+
+	tok, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, exp, tok.Expiry)
+	assert.Equal(t, "Bearer", tok.Type())
+
+	same, err := ts.Token()
+	require.NoError(t, err)
+	assert.Equal(t, tok.AccessToken, same.AccessToken)
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&fetches))
+
+	// within RefreshBefore of the exp claim a new token is fetched
+	ts.now = func() time.Time { return exp.Add(-30 * time.Second) }
+	refreshed, err := ts.Token()
+	require.NoError(t, err)
+	assert.NotEqual(t, tok.AccessToken, refreshed.AccessToken)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&fetches))
+}
+
+func TestTokenSourceReauthOn401(t *testing.T) {
+	var fetches uint64
+	authSrv := newTokenServer(t, time.Now().Add(time.Hour), &fetches)
+	defer authSrv.Close()
+
+	var (
+		calls     uint64
+		firstAuth atomic.Value
+	)
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get(common.AuthorizationHeader)
+		assert.True(t, strings.HasPrefix(auth, "Bearer "))
+		if atomic.AddUint64(&calls, 1) == 1 {
+			firstAuth.Store(auth)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.NotEqual(t, firstAuth.Load(), auth)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer apiSrv.Close()
+
+	c := NewClient(WithBearerToken("static"), WithClientCredentials(ClientCredentials{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		TokenURL:     authSrv.URL,
+	}))
+
+	req, err := http.NewRequest(http.MethodPost, apiSrv.URL, strings.NewReader(`{"query":"mutation { a }"}`))
+	require.NoError(t, err)
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&calls))
+	assert.Equal(t, uint64(2), atomic.LoadUint64(&fetches))
+	assert.NotNil(t, c.TokenSource())
+}
+
+func TestTokenSourceKeepsRequestAuthorization(t *testing.T) {
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer mine", r.Header.Get(common.AuthorizationHeader))
+	}))
+	defer apiSrv.Close()
+
+	c := NewClient(WithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "theirs"})))
+	req, err := http.NewRequest(http.MethodPost, apiSrv.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set(common.AuthorizationHeader, "Bearer mine")
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+}
@@ -0,0 +1,662 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// maxPositionalArgs is the number of arguments above which a method takes an Arguments struct
+const maxPositionalArgs = 3
+
+// defaultBindings maps the built-in and shared Taegis scalars to Go types
+var defaultBindings = map[string]string{
+	"String":     "string",
+	"Int":        "int",
+	"Float":      "float64",
+	"Boolean":    "bool",
+	"ID":         "string",
+	"Time":       "time.Time",
+	"Any":        "interface{}",
+	"JSONObject": "github.com/secureworks/taegis-sdk-go/common.Object",
+	"IDs":        "github.com/secureworks/taegis-sdk-go/common.IDs",
+	"Tags":       "github.com/secureworks/taegis-sdk-go/common.Tags",
+}
+
+// Config describes what to generate
+type Config struct {
+	// Schemas are the SDL files or directories of .graphql files making up the schema
+	Schemas []string
+	// Sources restricts the generated types and operations to the definitions of these files, all of them when empty
+	Sources []string
+	// Package is the name of the generated Go package
+	Package string
+	// Service names the API in the doc of the client
+	Service string
+	// Bindings maps GraphQL types to Go types, as import/path.Type, on top of the defaults
+	Bindings map[string]string
+}
+
+// Generate returns the gofmt-ed source of the client described by cfg
+func Generate(cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		return nil, fmt.Errorf("gql_clientgen: missing package name")
+	}
+	schema, err := graphql.LoadSchema(cfg.Schemas...)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &generator{
+		cfg:      cfg,
+		schema:   schema.AST(),
+		sources:  map[string]bool{},
+		bindings: map[string]string{},
+		imports: map[string]bool{
+			"context":  true,
+			"net/http": true,
+			"github.com/secureworks/taegis-sdk-go/client":  true,
+			"github.com/secureworks/taegis-sdk-go/graphql": true,
+		},
+		edges: map[string]map[string]bool{},
+	}
+	for _, s := range cfg.Sources {
+		g.sources[filepath.Clean(s)] = true
+	}
+	for k, v := range defaultBindings {
+		g.bindings[k] = v
+	}
+	for k, v := range cfg.Bindings {
+		g.bindings[k] = v
+	}
+
+	file, err := g.file()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := fileTemplate.Execute(&buf, file); err != nil {
+		return nil, fmt.Errorf("gql_clientgen: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("gql_clientgen: invalid generated code: %w", err)
+	}
+	return src, nil
+}
+
+type generator struct {
+	cfg      Config
+	schema   *ast.Schema
+	sources  map[string]bool
+	bindings map[string]string
+	imports  map[string]bool
+	// edges are the object fields kept in the field selections, without cycles
+	edges map[string]map[string]bool
+}
+
+type fileData struct {
+	Package    string
+	Service    string
+	StdImports []string
+	Imports    []string
+	Selections []selectionData
+	Types      []typeData
+	Methods    []methodData
+}
+
+// typeData is either an enum or a struct, the types are generated in the order of their names
+type typeData struct {
+	Enum   *enumData
+	Struct *structData
+}
+
+type selectionData struct {
+	Const  string
+	Fields []string
+}
+
+type enumData struct {
+	Doc    []string
+	Name   string
+	Values []enumValue
+}
+
+type enumValue struct {
+	Const string
+	Type  string
+	Value string
+}
+
+type structData struct {
+	Doc    []string
+	Name   string
+	Fields []fieldData
+}
+
+type fieldData struct {
+	Name string
+	Type string
+	JSON string
+}
+
+type methodData struct {
+	Name        string
+	Summary     string
+	Description []string
+	Params      string
+	Args        string
+	Arguments   *structData
+	Vars        []varData
+	Query       string
+	Field       string
+	ResultField string
+	Result      string
+	Zero        string
+}
+
+type varData struct {
+	Name  string
+	Value string
+}
+
+// owned reports whether the definition at pos is part of the generated sources
+func (g *generator) owned(pos *ast.Position) bool {
+	if len(g.sources) == 0 {
+		return true
+	}
+	return pos != nil && pos.Src != nil && g.sources[filepath.Clean(pos.Src.Name)]
+}
+
+func (g *generator) isRoot(def *ast.Definition) bool {
+	return def == g.schema.Query || def == g.schema.Mutation || def == g.schema.Subscription
+}
+
+func (g *generator) file() (*fileData, error) {
+	f := &fileData{Package: g.cfg.Package, Service: g.cfg.Service}
+	if f.Service == "" {
+		f.Service = goName(g.cfg.Package)
+	}
+
+	var names []string
+	for name, def := range g.schema.Types {
+		if def.BuiltIn || g.isRoot(def) || !g.owned(def.Position) {
+			continue
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if _, ok := g.bindings[name]; !ok && g.schema.Types[name].Kind == ast.Scalar {
+			return nil, fmt.Errorf("gql_clientgen: scalar %s needs a Go type, bind it with -bind %s=import/path.Type", name, name)
+		}
+	}
+	for _, name := range names {
+		def := g.schema.Types[name]
+		switch def.Kind {
+		case ast.Scalar:
+			// bound to a Go type, checked above
+		case ast.Enum:
+			e := g.enum(def)
+			f.Types = append(f.Types, typeData{Enum: &e})
+		case ast.Object, ast.InputObject:
+			s, err := g.structure(def.Name, def.Description, def.Fields)
+			if err != nil {
+				return nil, err
+			}
+			f.Types = append(f.Types, typeData{Struct: s})
+		default:
+			return nil, fmt.Errorf("gql_clientgen: %s %s is not supported", strings.ToLower(string(def.Kind)), name)
+		}
+	}
+
+	// singular fields are kept first, a parent is cheaper to select than a list of children
+	for _, lists := range []bool{false, true} {
+		for _, name := range names {
+			if def := g.schema.Types[name]; def.Kind == ast.Object {
+				g.link(def, lists)
+			}
+		}
+	}
+	for _, name := range names {
+		if def := g.schema.Types[name]; def.Kind == ast.Object {
+			f.Selections = append(f.Selections, g.selection(def))
+		}
+	}
+
+	for _, root := range []*ast.Definition{g.schema.Query, g.schema.Mutation} {
+		if root == nil {
+			continue
+		}
+		for _, field := range root.Fields {
+			if strings.HasPrefix(field.Name, "__") || !g.owned(field.Position) {
+				continue
+			}
+			m, err := g.method(root, field)
+			if err != nil {
+				return nil, err
+			}
+			f.Methods = append(f.Methods, *m)
+		}
+	}
+
+	for imp := range g.imports {
+		if strings.Contains(imp, ".") {
+			f.Imports = append(f.Imports, imp)
+		} else {
+			f.StdImports = append(f.StdImports, imp)
+		}
+	}
+	sort.Strings(f.StdImports)
+	sort.Strings(f.Imports)
+	return f, nil
+}
+
+func (g *generator) enum(def *ast.Definition) enumData {
+	e := enumData{Doc: doc(def.Description), Name: def.Name}
+	for _, v := range def.EnumValues {
+		e.Values = append(e.Values, enumValue{Const: def.Name + enumName(v.Name), Type: def.Name, Value: v.Name})
+	}
+	return e
+}
+
+func (g *generator) structure(name, description string, fields ast.FieldList) (*structData, error) {
+	s := &structData{Doc: doc(description), Name: name}
+	for _, field := range fields {
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		typ, err := g.goType(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("gql_clientgen: field %s.%s: %w", name, field.Name, err)
+		}
+		s.Fields = append(s.Fields, fieldData{Name: goName(field.Name), Type: typ, JSON: field.Name})
+	}
+	return s, nil
+}
+
+// goType returns the Go type of t, nullable values are pointers except for the bound custom scalars
+func (g *generator) goType(t *ast.Type) (string, error) {
+	if t.Elem != nil {
+		elem, err := g.goType(t.Elem)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elem, nil
+	}
+
+	def := g.schema.Types[t.NamedType]
+	if def == nil {
+		return "", fmt.Errorf("unknown type %s", t.NamedType)
+	}
+	if binding, ok := g.bindings[t.NamedType]; ok {
+		typ := g.bind(binding)
+		if !t.NonNull && (def.Kind != ast.Scalar || def.BuiltIn || typ == "time.Time") {
+			typ = "*" + typ
+		}
+		return typ, nil
+	}
+	if def.Kind == ast.Scalar || !g.owned(def.Position) {
+		return "", fmt.Errorf("type %s is not generated, bind it with -bind %s=import/path.Type", def.Name, def.Name)
+	}
+	if !t.NonNull {
+		return "*" + def.Name, nil
+	}
+	return def.Name, nil
+}
+
+// bind returns the Go type of a binding and records its import
+func (g *generator) bind(binding string) string {
+	i := strings.LastIndex(binding, ".")
+	if i < 0 || strings.HasSuffix(binding, "{}") {
+		return binding
+	}
+	path := binding[:i]
+	g.imports[path] = true
+	return filepath.Base(path) + binding[i:]
+}
+
+// link records the object fields of def which can be selected without a cycle between the selections,
+// either its list fields or the other ones
+func (g *generator) link(def *ast.Definition, lists bool) {
+	for _, field := range def.Fields {
+		target := g.schema.Types[field.Type.Name()]
+		if target == nil || target.Kind != ast.Object || hasRequiredArgs(field) || (field.Type.Elem != nil) != lists {
+			continue
+		}
+		if target.Name == def.Name || g.reaches(target.Name, def.Name, map[string]bool{}) {
+			continue
+		}
+		if g.edges[def.Name] == nil {
+			g.edges[def.Name] = map[string]bool{}
+		}
+		g.edges[def.Name][target.Name] = true
+	}
+}
+
+func (g *generator) reaches(from, to string, seen map[string]bool) bool {
+	if from == to {
+		return true
+	}
+	seen[from] = true
+	for next := range g.edges[from] {
+		if !seen[next] && g.reaches(next, to, seen) {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *generator) selection(def *ast.Definition) selectionData {
+	s := selectionData{Const: selectionConst(def.Name)}
+	for _, field := range def.Fields {
+		if strings.HasPrefix(field.Name, "__") || hasRequiredArgs(field) {
+			continue
+		}
+		target := g.schema.Types[field.Type.Name()]
+		switch {
+		case target.Kind != ast.Object:
+			s.Fields = append(s.Fields, field.Name)
+		case g.edges[def.Name][target.Name]:
+			s.Fields = append(s.Fields, field.Name+" {` + "+selectionConst(target.Name)+" + `\n\t}")
+		}
+	}
+	return s
+}
+
+func (g *generator) method(root *ast.Definition, field *ast.FieldDefinition) (*methodData, error) {
+	operation := "query"
+	name := goName(field.Name)
+	if root == g.schema.Mutation {
+		operation = "mutation"
+	} else if !strings.HasPrefix(name, "Get") {
+		name = "Get" + name
+	}
+
+	result, err := g.goType(field.Type)
+	if err != nil {
+		return nil, fmt.Errorf("gql_clientgen: %s %s: %w", operation, field.Name, err)
+	}
+	m := &methodData{
+		Name:        name,
+		Summary:     fmt.Sprintf("calls the %s %s", field.Name, operation),
+		Field:       field.Name,
+		ResultField: goName(field.Name),
+		Result:      result,
+		Zero:        zero(g.schema.Types[field.Type.Name()], field.Type, result),
+	}
+	// the description reads as the summary, "Looks up an item." documents GetItem as "GetItem looks up an item."
+	if d := doc(field.Description); len(d) > 0 {
+		m.Summary, m.Description = lowerFirst(d[0]), d[1:]
+	}
+
+	var (
+		defs, args, params []string
+		arguments          = &structData{Name: name + "Arguments", Doc: []string{name + "Arguments is the parameters for " + name}}
+	)
+	for _, arg := range field.Arguments {
+		typ, err := g.goType(arg.Type)
+		if err != nil {
+			return nil, fmt.Errorf("gql_clientgen: %s %s argument %s: %w", operation, field.Name, arg.Name, err)
+		}
+		defs = append(defs, fmt.Sprintf("$%s: %s", arg.Name, arg.Type.String()))
+		args = append(args, fmt.Sprintf("%s: $%s", arg.Name, arg.Name))
+		arguments.Fields = append(arguments.Fields, fieldData{Name: goName(arg.Name), Type: typ, JSON: arg.Name})
+		params = append(params, paramName(arg.Name)+" "+typ)
+		m.Vars = append(m.Vars, varData{Name: arg.Name, Value: paramName(arg.Name)})
+	}
+	if len(field.Arguments) > maxPositionalArgs {
+		m.Arguments = arguments
+		params = []string{"params *" + arguments.Name}
+		for i := range m.Vars {
+			m.Vars[i].Value = "params." + arguments.Fields[i].Name
+		}
+	}
+	for _, p := range params {
+		m.Params += p + ", "
+		m.Args += strings.Fields(p)[0] + ", "
+	}
+
+	var query strings.Builder
+	query.WriteString(operation)
+	if len(defs) > 0 {
+		query.WriteString("(" + strings.Join(defs, ", ") + ")")
+	}
+	query.WriteString(" {\n\t\t" + field.Name)
+	if len(args) > 0 {
+		query.WriteString("(" + strings.Join(args, ", ") + ")")
+	}
+	if def := g.schema.Types[field.Type.Name()]; def.Kind == ast.Object {
+		query.WriteString(" {`+" + selectionConst(def.Name) + "+`\n\t\t}")
+	}
+	query.WriteString("\n\t}")
+	m.Query = query.String()
+	return m, nil
+}
+
+func hasRequiredArgs(field *ast.FieldDefinition) bool {
+	for _, arg := range field.Arguments {
+		if arg.Type.NonNull && arg.DefaultValue == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// zero returns the zero value of the Go type typ of t
+func zero(def *ast.Definition, t *ast.Type, typ string) string {
+	switch {
+	case strings.HasPrefix(typ, "*"), strings.HasPrefix(typ, "[]"), typ == "interface{}":
+		return "nil"
+	case t.Elem != nil:
+		return "nil"
+	case typ == "string" || def.Kind == ast.Enum:
+		return `""`
+	case typ == "int" || typ == "float64":
+		return "0"
+	case typ == "bool":
+		return "false"
+	case def.Kind == ast.Object || typ == "time.Time":
+		return typ + "{}"
+	}
+	return "*new(" + typ + ")"
+}
+
+func selectionConst(name string) string {
+	return "all" + name + "Fields"
+}
+
+// goName exports a GraphQL name, snake_case is turned into CamelCase
+func goName(name string) string {
+	if name == "id" {
+		return "ID"
+	}
+	var b strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		r := []rune(part)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	return b.String()
+}
+
+// enumName turns an enum value into the suffix of its Go constant, SCREAMING_CASE words are capitalized
+func enumName(value string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(value, "_") {
+		if part == "" {
+			continue
+		}
+		if strings.ToUpper(part) == part {
+			part = strings.ToLower(part)
+		}
+		r := []rune(part)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	return b.String()
+}
+
+var reserved = map[string]bool{
+	"break": true, "case": true, "chan": true, "const": true, "continue": true, "default": true, "defer": true,
+	"else": true, "fallthrough": true, "for": true, "func": true, "go": true, "goto": true, "if": true,
+	"import": true, "interface": true, "map": true, "package": true, "range": true, "return": true,
+	"select": true, "struct": true, "switch": true, "type": true, "var": true,
+	// names used by the generated methods
+	"c": true, "ctx": true, "opts": true, "req": true, "res": true, "err": true, "params": true,
+}
+
+func paramName(name string) string {
+	if reserved[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// lowerFirst lowercases the first letter of s unless it starts an acronym
+func lowerFirst(s string) string {
+	r := []rune(s)
+	if len(r) > 1 && unicode.IsUpper(r[1]) {
+		return s
+	}
+	return strings.ToLower(string(r[:1])) + string(r[1:])
+}
+
+func doc(description string) []string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil
+	}
+	return strings.Split(description, "\n")
+}
+
+var fileTemplate = template.Must(template.New("client").Parse(`// Code generated by gql_clientgen, please do not edit
+
+package {{ .Package }}
+
+import (
+{{- range .StdImports }}
+	"{{ . }}"
+{{- end }}
+{{ range .Imports }}
+	"{{ . }}"
+{{- end }}
+)
+
+// Client provides an easy to use Go client to the {{ .Service }} API. With graphql.RequestWithPartialData its methods
+// return the data which resolved along with a *graphql.PartialDataError listing the fields which failed
+type Client struct {
+	client *client.Client
+	url    string
+}
+
+// New returns a new Client, ready for use
+func New(url string, opts ...client.Option) *Client {
+	client := client.NewClient(opts...)
+	return &Client{
+		client: client,
+		url:    url,
+	}
+}
+
+func (c *Client) makeRequest(ctx context.Context, req *graphql.Request, res interface{}) error {
+	header := http.Header{}
+	return graphql.ExecuteQueryContext(ctx, &graphql.QueryConfig{
+		HClient:   c.client,
+		Header:    header,
+		Output:    res,
+		Request:   req,
+		ServerURL: c.url,
+	})
+}
+{{ range .Selections }}
+const {{ .Const }} = ` + "`" + `
+{{- range .Fields }}
+	{{ . }}
+{{- end }}
+` + "`" + `
+{{ end }}
+{{- range .Types }}
+{{- with .Enum }}
+{{ range .Doc }}// {{ . }}
+{{ end -}}
+type {{ .Name }} string
+
+const (
+{{- range .Values }}
+	{{ .Const }} {{ .Type }} = "{{ .Value }}"
+{{- end }}
+)
+{{ end }}
+{{- with .Struct }}
+{{ template "struct" . }}
+{{ end }}
+{{- end }}
+{{- range .Methods }}
+{{- if .Arguments }}
+{{ template "struct" .Arguments }}
+{{ end }}
+// {{ .Name }}Ctx {{ .Summary }}
+{{ if .Description }}//
+{{ end }}{{ range .Description }}// {{ . }}
+{{ end -}}
+func (c *Client) {{ .Name }}Ctx(ctx context.Context, {{ .Params }}opts ...graphql.RequestOption) ({{ .Result }}, error) {
+	req := graphql.NewRequest(` + "`" + `{{ .Query }}` + "`" + `, opts...)
+{{- range .Vars }}
+	req.Var("{{ .Name }}", {{ .Value }})
+{{- end }}
+
+	var res struct {
+		{{ .ResultField }} {{ .Result }} ` + "`" + `json:"{{ .Field }}"` + "`" + `
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.{{ .ResultField }}, err
+		}
+		return {{ .Zero }}, err
+	}
+
+	return res.{{ .ResultField }}, nil
+}
+
+// {{ .Name }} {{ .Summary }}
+{{ if .Description }}//
+{{ end }}{{ range .Description }}// {{ . }}
+{{ end -}}
+func (c *Client) {{ .Name }}({{ .Params }}opts ...graphql.RequestOption) ({{ .Result }}, error) {
+	return c.{{ .Name }}Ctx(context.Background(), {{ .Args }}opts...)
+}
+{{ end }}
+// IClient can be used to help mock out the Client in tests
+type IClient interface {
+{{- range .Methods }}
+	{{ .Name }}Ctx(ctx context.Context, {{ .Params }}opts ...graphql.RequestOption) ({{ .Result }}, error)
+	{{ .Name }}({{ .Params }}opts ...graphql.RequestOption) ({{ .Result }}, error)
+{{- end }}
+}
+
+var _ IClient = (*Client)(nil)
+
+{{- define "struct" }}
+{{- range .Doc }}// {{ . }}
+{{ end -}}
+type {{ .Name }} struct {
+{{- range .Fields }}
+	{{ .Name }} {{ .Type }} ` + "`" + `json:"{{ .JSON }}"` + "`" + `
+{{- end }}
+}
+{{- end }}
+`))
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen/internal/inventory"
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+var update = flag.Bool("update", false, "regenerate the inventory client")
+
+var inventoryConfig = Config{
+	Schemas: []string{"testdata/inventory.graphql"},
+	Package: "inventory",
+	Service: "Inventory",
+}
+
+// TestGenerate_Inventory makes sure internal/inventory is up to date with the generator, run go generate ./... otherwise
+func TestGenerate_Inventory(t *testing.T) {
+	src, err := Generate(inventoryConfig)
+	require.NoError(t, err)
+
+	golden := filepath.Join("internal", "inventory", "client.go")
+	if *update {
+		require.NoError(t, ioutil.WriteFile(golden, src, 0644))
+	}
+	expected, err := ioutil.ReadFile(golden)
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), string(src))
+}
+
+func TestGenerate_InventoryClient(t *testing.T) {
+	schema, err := graphql.LoadSchema("testdata/inventory.graphql")
+	require.NoError(t, err)
+
+	g := testutils.NewMockGraphQLHandler(t)
+	g.Schema = schema
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+	c := inventory.New(srv.URL)
+
+	createdAt := time.Date(2020, 10, 1, 12, 0, 0, 0, time.UTC)
+	g.Response = map[string]interface{}{
+		"item": map[string]interface{}{
+			"id":         "i1",
+			"name":       "item",
+			"state":      "SOLD_OUT",
+			"tags":       []string{"tag"},
+			"created_at": createdAt,
+			"category":   map[string]interface{}{"id": "c1", "name": "category"},
+		},
+	}
+	g.ExpectedVariables = map[string]interface{}{"id": "i1"}
+	item, err := c.GetItem("i1")
+	require.NoError(t, err)
+	assert.Equal(t, &inventory.Item{
+		ID:        "i1",
+		Name:      "item",
+		State:     inventory.ItemStateSoldOut,
+		Tags:      common.Tags{"tag"},
+		CreatedAt: createdAt,
+		Category:  &inventory.Category{ID: "c1", Name: "category"},
+	}, item)
+
+	limit := 10
+	g.Response = map[string]interface{}{"searchItems": map[string]interface{}{"totalCount": 0, "items": []interface{}{}}}
+	g.ExpectedVariables = map[string]interface{}{"name": nil, "state": "IN_STOCK", "tags": nil, "limit": float64(10), "offset": nil}
+	state := inventory.ItemStateInStock
+	page, err := c.GetSearchItems(&inventory.GetSearchItemsArguments{State: &state, Limit: &limit})
+	require.NoError(t, err)
+	assert.Equal(t, &inventory.ItemPage{Items: []inventory.Item{}}, page)
+
+	g.ExpectedVariables = nil
+	g.WithErrors([]graphql.Error{{Message: "not found"}}, func() {
+		item, err := c.CreateItem(inventory.ItemInput{Name: "item"})
+		assert.Error(t, err)
+		assert.Equal(t, inventory.Item{}, item)
+	})
+}
+
+func TestGenerate_Sources(t *testing.T) {
+	schema := filepath.Join("..", "..", "schema")
+
+	src, err := Generate(Config{
+		Schemas: []string{schema},
+		Sources: []string{filepath.Join(schema, "assets.graphql")},
+		Package: "assets",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, string(src), "func (c *Client) GetAssetCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (Asset, error) {")
+	assert.Contains(t, string(src), "type GetAllAssetsArguments struct {")
+	assert.NotContains(t, string(src), "type Cluster struct {")
+
+	_, err = Generate(Config{
+		Schemas: []string{schema},
+		Sources: []string{filepath.Join(schema, "collectors.graphql")},
+		Package: "collectors",
+	})
+	assert.EqualError(t, err, "gql_clientgen: scalar Hosts needs a Go type, bind it with -bind Hosts=import/path.Type")
+
+	_, err = Generate(Config{
+		Schemas: []string{schema},
+		Sources: []string{filepath.Join(schema, "collectors.graphql")},
+		Package: "collectors",
+		Bindings: map[string]string{
+			"Hosts":            "github.com/secureworks/taegis-sdk-go/collectors.Hosts",
+			"KubernetesConfig": "github.com/secureworks/taegis-sdk-go/collectors.KubernetesConfig",
+			"Map":              "github.com/secureworks/taegis-sdk-go/collectors.Map",
+			"Matrix":           "github.com/secureworks/taegis-sdk-go/collectors.Matrix",
+			"Sample":           "github.com/secureworks/taegis-sdk-go/collectors.Sample",
+			"StringSlice":      "github.com/secureworks/taegis-sdk-go/collectors.StringSlice",
+			"Vector":           "github.com/secureworks/taegis-sdk-go/collectors.Vector",
+		},
+	})
+	assert.NoError(t, err)
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	_, err := Generate(Config{Schemas: []string{"testdata/inventory.graphql"}})
+	assert.EqualError(t, err, "gql_clientgen: missing package name")
+
+	_, err = Generate(Config{Schemas: []string{"testdata/missing.graphql"}, Package: "inventory"})
+	assert.Error(t, err)
+
+	related := Config{
+		Schemas: []string{"testdata/inventory.graphql", "testdata/related.graphql"},
+		Sources: []string{"testdata/related.graphql"},
+		Package: "related",
+	}
+	_, err = Generate(related)
+	assert.EqualError(t, err, "gql_clientgen: query relatedItems: type Item is not generated, bind it with -bind Item=import/path.Type")
+
+	related.Bindings = map[string]string{"Item": "github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen/internal/inventory.Item"}
+	src, err := Generate(related)
+	require.NoError(t, err)
+	assert.Contains(t, string(src), `"github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen/internal/inventory"`)
+	assert.Contains(t, string(src), "func (c *Client) GetRelatedItemsCtx(ctx context.Context, id string, opts ...graphql.RequestOption) ([]*inventory.Item, error) {")
+}
+
+func TestNames(t *testing.T) {
+	for name, expected := range map[string]string{
+		"id":                       "ID",
+		"hostId":                   "HostId",
+		"host_id":                  "HostId",
+		"clusterID":                "ClusterID",
+		"isServerR2For2003And2008": "IsServerR2For2003And2008",
+	} {
+		assert.Equal(t, expected, goName(name))
+	}
+
+	for value, expected := range map[string]string{
+		"ENDPOINT_CARBON_BLACK": "EndpointCarbonBlack",
+		"ip_address":            "IpAddress",
+		"asc":                   "Asc",
+		"APIKey":                "APIKey",
+		"OAuthPassword":         "OAuthPassword",
+	} {
+		assert.Equal(t, expected, enumName(value))
+	}
+
+	assert.Equal(t, "type_", paramName("type"))
+	assert.Equal(t, "limit", paramName("limit"))
+}
@@ -0,0 +1,328 @@
+// Code generated by gql_clientgen, please do not edit
+
+package inventory
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// Client provides an easy to use Go client to the Inventory API. With graphql.RequestWithPartialData its methods
+// return the data which resolved along with a *graphql.PartialDataError listing the fields which failed
+type Client struct {
+	client *client.Client
+	url    string
+}
+
+// New returns a new Client, ready for use
+func New(url string, opts ...client.Option) *Client {
+	client := client.NewClient(opts...)
+	return &Client{
+		client: client,
+		url:    url,
+	}
+}
+
+func (c *Client) makeRequest(ctx context.Context, req *graphql.Request, res interface{}) error {
+	header := http.Header{}
+	return graphql.ExecuteQueryContext(ctx, &graphql.QueryConfig{
+		HClient:   c.client,
+		Header:    header,
+		Output:    res,
+		Request:   req,
+		ServerURL: c.url,
+	})
+}
+
+const allCategoryFields = `
+	id
+	name
+`
+
+const allItemFields = `
+	id
+	name
+	description
+	state
+	price
+	tags
+	properties
+	created_at
+	deleted_at
+	category {` + allCategoryFields + `
+	}
+`
+
+const allItemEventFields = `
+	action
+`
+
+const allItemPageFields = `
+	totalCount
+	items {` + allItemFields + `
+	}
+`
+
+type Category struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Items []Item `json:"items"`
+}
+
+// Describes an item of the inventory.
+type Item struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Description *string       `json:"description"`
+	State       ItemState     `json:"state"`
+	Price       *float64      `json:"price"`
+	Tags        common.Tags   `json:"tags"`
+	Properties  common.Object `json:"properties"`
+	CreatedAt   time.Time     `json:"created_at"`
+	DeletedAt   *time.Time    `json:"deleted_at"`
+	Category    *Category     `json:"category"`
+	History     []ItemEvent   `json:"history"`
+}
+
+type ItemEvent struct {
+	Action string `json:"action"`
+}
+
+type ItemInput struct {
+	Name        string      `json:"name"`
+	Description *string     `json:"description"`
+	State       *ItemState  `json:"state"`
+	Tags        common.Tags `json:"tags"`
+	CategoryId  *string     `json:"categoryId"`
+}
+
+type ItemPage struct {
+	TotalCount int    `json:"totalCount"`
+	Items      []Item `json:"items"`
+}
+
+// The lifecycle of an item.
+type ItemState string
+
+const (
+	ItemStateInStock      ItemState = "IN_STOCK"
+	ItemStateSoldOut      ItemState = "SOLD_OUT"
+	ItemStateDiscontinued ItemState = "discontinued"
+)
+
+// GetItemCtx looks up an item by id.
+func (c *Client) GetItemCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (*Item, error) {
+	req := graphql.NewRequest(`query($id: ID!) {
+		item(id: $id) {`+allItemFields+`
+		}
+	}`, opts...)
+	req.Var("id", id)
+
+	var res struct {
+		Item *Item `json:"item"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.Item, err
+		}
+		return nil, err
+	}
+
+	return res.Item, nil
+}
+
+// GetItem looks up an item by id.
+func (c *Client) GetItem(id string, opts ...graphql.RequestOption) (*Item, error) {
+	return c.GetItemCtx(context.Background(), id, opts...)
+}
+
+// GetItemsCtx calls the items query
+func (c *Client) GetItemsCtx(ctx context.Context, ids []string, opts ...graphql.RequestOption) ([]*Item, error) {
+	req := graphql.NewRequest(`query($ids: [ID!]!) {
+		items(ids: $ids) {`+allItemFields+`
+		}
+	}`, opts...)
+	req.Var("ids", ids)
+
+	var res struct {
+		Items []*Item `json:"items"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.Items, err
+		}
+		return nil, err
+	}
+
+	return res.Items, nil
+}
+
+// GetItems calls the items query
+func (c *Client) GetItems(ids []string, opts ...graphql.RequestOption) ([]*Item, error) {
+	return c.GetItemsCtx(context.Background(), ids, opts...)
+}
+
+// GetItemCountCtx calls the itemCount query
+func (c *Client) GetItemCountCtx(ctx context.Context, state *ItemState, opts ...graphql.RequestOption) (int, error) {
+	req := graphql.NewRequest(`query($state: ItemState) {
+		itemCount(state: $state)
+	}`, opts...)
+	req.Var("state", state)
+
+	var res struct {
+		ItemCount int `json:"itemCount"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.ItemCount, err
+		}
+		return 0, err
+	}
+
+	return res.ItemCount, nil
+}
+
+// GetItemCount calls the itemCount query
+func (c *Client) GetItemCount(state *ItemState, opts ...graphql.RequestOption) (int, error) {
+	return c.GetItemCountCtx(context.Background(), state, opts...)
+}
+
+// GetSearchItemsArguments is the parameters for GetSearchItems
+type GetSearchItemsArguments struct {
+	Name   *string     `json:"name"`
+	State  *ItemState  `json:"state"`
+	Tags   common.Tags `json:"tags"`
+	Limit  *int        `json:"limit"`
+	Offset *int        `json:"offset"`
+}
+
+// GetSearchItemsCtx calls the searchItems query
+func (c *Client) GetSearchItemsCtx(ctx context.Context, params *GetSearchItemsArguments, opts ...graphql.RequestOption) (*ItemPage, error) {
+	req := graphql.NewRequest(`query($name: String, $state: ItemState, $tags: Tags, $limit: Int, $offset: Int) {
+		searchItems(name: $name, state: $state, tags: $tags, limit: $limit, offset: $offset) {`+allItemPageFields+`
+		}
+	}`, opts...)
+	req.Var("name", params.Name)
+	req.Var("state", params.State)
+	req.Var("tags", params.Tags)
+	req.Var("limit", params.Limit)
+	req.Var("offset", params.Offset)
+
+	var res struct {
+		SearchItems *ItemPage `json:"searchItems"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.SearchItems, err
+		}
+		return nil, err
+	}
+
+	return res.SearchItems, nil
+}
+
+// GetSearchItems calls the searchItems query
+func (c *Client) GetSearchItems(params *GetSearchItemsArguments, opts ...graphql.RequestOption) (*ItemPage, error) {
+	return c.GetSearchItemsCtx(context.Background(), params, opts...)
+}
+
+// GetCategoriesCtx calls the categories query
+func (c *Client) GetCategoriesCtx(ctx context.Context, opts ...graphql.RequestOption) ([]Category, error) {
+	req := graphql.NewRequest(`query {
+		categories {`+allCategoryFields+`
+		}
+	}`, opts...)
+
+	var res struct {
+		Categories []Category `json:"categories"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.Categories, err
+		}
+		return nil, err
+	}
+
+	return res.Categories, nil
+}
+
+// GetCategories calls the categories query
+func (c *Client) GetCategories(opts ...graphql.RequestOption) ([]Category, error) {
+	return c.GetCategoriesCtx(context.Background(), opts...)
+}
+
+// CreateItemCtx calls the createItem mutation
+func (c *Client) CreateItemCtx(ctx context.Context, input ItemInput, opts ...graphql.RequestOption) (Item, error) {
+	req := graphql.NewRequest(`mutation($input: ItemInput!) {
+		createItem(input: $input) {`+allItemFields+`
+		}
+	}`, opts...)
+	req.Var("input", input)
+
+	var res struct {
+		CreateItem Item `json:"createItem"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.CreateItem, err
+		}
+		return Item{}, err
+	}
+
+	return res.CreateItem, nil
+}
+
+// CreateItem calls the createItem mutation
+func (c *Client) CreateItem(input ItemInput, opts ...graphql.RequestOption) (Item, error) {
+	return c.CreateItemCtx(context.Background(), input, opts...)
+}
+
+// DeleteItemCtx calls the deleteItem mutation
+func (c *Client) DeleteItemCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (*bool, error) {
+	req := graphql.NewRequest(`mutation($id: ID!) {
+		deleteItem(id: $id)
+	}`, opts...)
+	req.Var("id", id)
+
+	var res struct {
+		DeleteItem *bool `json:"deleteItem"`
+	}
+	if err := c.makeRequest(ctx, req, &res); err != nil {
+		if graphql.IsPartialData(err) {
+			return res.DeleteItem, err
+		}
+		return nil, err
+	}
+
+	return res.DeleteItem, nil
+}
+
+// DeleteItem calls the deleteItem mutation
+func (c *Client) DeleteItem(id string, opts ...graphql.RequestOption) (*bool, error) {
+	return c.DeleteItemCtx(context.Background(), id, opts...)
+}
+
+// IClient can be used to help mock out the Client in tests
+type IClient interface {
+	GetItemCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (*Item, error)
+	GetItem(id string, opts ...graphql.RequestOption) (*Item, error)
+	GetItemsCtx(ctx context.Context, ids []string, opts ...graphql.RequestOption) ([]*Item, error)
+	GetItems(ids []string, opts ...graphql.RequestOption) ([]*Item, error)
+	GetItemCountCtx(ctx context.Context, state *ItemState, opts ...graphql.RequestOption) (int, error)
+	GetItemCount(state *ItemState, opts ...graphql.RequestOption) (int, error)
+	GetSearchItemsCtx(ctx context.Context, params *GetSearchItemsArguments, opts ...graphql.RequestOption) (*ItemPage, error)
+	GetSearchItems(params *GetSearchItemsArguments, opts ...graphql.RequestOption) (*ItemPage, error)
+	GetCategoriesCtx(ctx context.Context, opts ...graphql.RequestOption) ([]Category, error)
+	GetCategories(opts ...graphql.RequestOption) ([]Category, error)
+	CreateItemCtx(ctx context.Context, input ItemInput, opts ...graphql.RequestOption) (Item, error)
+	CreateItem(input ItemInput, opts ...graphql.RequestOption) (Item, error)
+	DeleteItemCtx(ctx context.Context, id string, opts ...graphql.RequestOption) (*bool, error)
+	DeleteItem(id string, opts ...graphql.RequestOption) (*bool, error)
+}
+
+var _ IClient = (*Client)(nil)
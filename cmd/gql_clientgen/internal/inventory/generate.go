@@ -0,0 +1,4 @@
+// Package inventory is generated from testdata/inventory.graphql, it checks that the output of gql_clientgen builds
+package inventory
+
+//go:generate go run github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen -schema ../../testdata/inventory.graphql -package inventory -service Inventory -out client.go
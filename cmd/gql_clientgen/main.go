@@ -0,0 +1,88 @@
+// Command gql_clientgen generates a typed service client from Taegis SDL schema files, in the style of assets.Client:
+// model types, enums and input types, the field selections of every object, a Ctx and a plain method for each query
+// and mutation, and the IClient interface to mock them. Subscriptions are left to the hand-written code.
+//
+// It works offline so it can run from a go:generate directive, for example:
+//
+//	//go:generate go run github.com/secureworks/taegis-sdk-go/cmd/gql_clientgen -schema ../schema -source ../schema/assets.graphql -package assets -out client.go
+//
+// The types of the other schema files and the custom scalars have to be bound to Go types with -bind,
+// JSONObject, IDs and Tags are bound to the common package by default.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// listFlag collects the values of a repeated or comma separated flag
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, strings.Split(v, ",")...)
+	return nil
+}
+
+// bindFlag collects the GraphQL=import/path.GoType bindings
+type bindFlag map[string]string
+
+func (b bindFlag) String() string {
+	var s []string
+	for k, v := range b {
+		s = append(s, k+"="+v)
+	}
+	return strings.Join(s, ",")
+}
+
+func (b bindFlag) Set(v string) error {
+	for _, binding := range strings.Split(v, ",") {
+		kv := strings.SplitN(binding, "=", 2)
+		if len(kv) != 2 || kv[0] == "" || kv[1] == "" {
+			return fmt.Errorf("invalid binding %q, expected GraphQLType=import/path.GoType", binding)
+		}
+		b[kv[0]] = kv[1]
+	}
+	return nil
+}
+
+func main() {
+	var (
+		cfg      = Config{Bindings: bindFlag{}}
+		schemas  listFlag
+		sources  listFlag
+		out      string
+		flagSet  = flag.NewFlagSet("gql_clientgen", flag.ExitOnError)
+		bindings = bindFlag(cfg.Bindings)
+	)
+	flagSet.Var(&schemas, "schema", "SDL file or directory of .graphql files of the schema, can be repeated")
+	flagSet.Var(&sources, "source", "SDL file whose definitions are generated, can be repeated, defaults to the whole schema")
+	flagSet.Var(bindings, "bind", "GraphQLType=import/path.GoType binding, can be repeated")
+	flagSet.StringVar(&cfg.Package, "package", "", "name of the generated package")
+	flagSet.StringVar(&cfg.Service, "service", "", "name of the API in the client doc, defaults to the package name")
+	flagSet.StringVar(&out, "out", "", "output file, defaults to stdout")
+	_ = flagSet.Parse(os.Args[1:])
+
+	cfg.Schemas = schemas
+	cfg.Sources = sources
+	src, err := Generate(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if out == "" {
+		_, _ = os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(out, src, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -23,6 +23,7 @@ var (
 
 func TestClient(t *testing.T) {
 	g := testutils.NewMockGraphQLHandler(t)
+	g.Schema = testutils.Schema(t)
 	srv := httptest.NewServer(g)
 	defer srv.Close()
 
@@ -72,6 +72,16 @@ type Pagination struct {
 	PerPage *int `json:"perPage"`
 }
 
+// PageRequest returns the PageRequest of the page p points to
+func (p Pagination) PageRequest() PageRequest {
+	return FirstPage(p.Page, p.PerPage)
+}
+
+// PaginationOf returns the Pagination of the page req describes
+func PaginationOf(req PageRequest) Pagination {
+	return NewPaginationOptions(req.Page, req.Size)
+}
+
 // IntP is a helper function to return a pointer to an int which is useful for
 // optional int parameters in APIs.
 func IntP(i int) *int {
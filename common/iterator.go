@@ -0,0 +1,309 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// DefaultPageSize is the size of the pages an Iterator fetches when the list API is not given one
+const DefaultPageSize = 100
+
+// PageRequest describes the page an Iterator needs from the list API
+type PageRequest struct {
+	// Page is the number of the page, 1 for the first one
+	Page int
+	// Offset is the number of items before the page
+	Offset int
+	// Size is the maximum number of items in the page
+	Size int
+	// Cursor is the next page ID returned with the previous page, nil for the first page of a cursor Iterator
+	Cursor *string
+}
+
+// Page is a page of items returned by a list API
+type Page struct {
+	// Items is the slice of the items in the page
+	Items interface{}
+	// Total is the number of items across all the pages, nil when the list API doesn't report it
+	Total *int
+	// Next is the ID of the next page for the list APIs paginating with page IDs, nil after the last page
+	Next *string
+}
+
+// FirstPage returns the PageRequest of the page number page with size items, a nil page or size meaning the defaults
+func FirstPage(page, size *int) PageRequest {
+	req := PageRequest{Page: 1}
+	if page != nil && *page > 0 {
+		req.Page = *page
+	}
+	if size != nil {
+		req.Size = *size
+	}
+	return req
+}
+
+// FirstOffset returns the PageRequest of the size items after offset, a nil offset or size meaning the defaults
+func FirstOffset(offset, size *int) PageRequest {
+	req := PageRequest{}
+	if offset != nil {
+		req.Offset = *offset
+	}
+	if size != nil {
+		req.Size = *size
+	}
+	return req
+}
+
+// PageFetcher fetches a page from a list API
+type PageFetcher func(ctx context.Context, req PageRequest) (*Page, error)
+
+type pageResult struct {
+	page *Page
+	err  error
+}
+
+// pendingPage is a page being fetched with the context of the Next call which scheduled it, res is nil until the
+// fetch is started
+type pendingPage struct {
+	ctx context.Context
+	req PageRequest
+	res chan pageResult
+}
+
+// Iterator walks through the items of a list API page by page, fetching the pages as the items are consumed.
+// An Iterator is not safe for concurrent use
+type Iterator struct {
+	fetch    PageFetcher
+	next     PageRequest
+	cursor   bool
+	prefetch int
+
+	pending []*pendingPage
+	items   reflect.Value
+	pos     int
+	total   *int
+	done    bool
+	err     error
+}
+
+// NewIterator returns an Iterator over a list API paginating with page numbers or offsets, starting at the page
+// described by first. A zero Page starts at page 1 and a zero Size is DefaultPageSize. When Offset is 0 it is derived
+// from Page and Size; the following pages keep the same Size.
+// The Iterator stops once Total items have been read when the list API reports it, otherwise after an empty or short page
+func NewIterator(fetch PageFetcher, first PageRequest) *Iterator {
+	if first.Page <= 0 {
+		first.Page = 1
+	}
+	if first.Size <= 0 {
+		first.Size = DefaultPageSize
+	}
+	if first.Offset <= 0 {
+		first.Offset = (first.Page - 1) * first.Size
+	}
+	first.Cursor = nil
+	return &Iterator{fetch: fetch, next: first}
+}
+
+// NewCursorIterator returns an Iterator over a list API paginating with page IDs, each page pointing to the next one.
+// The Iterator stops after the page without a Next page ID
+func NewCursorIterator(fetch PageFetcher, size int) *Iterator {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	return &Iterator{fetch: fetch, next: PageRequest{Page: 1, Size: size}, cursor: true}
+}
+
+// Prefetch makes the Iterator fetch up to pages pages ahead concurrently while the current page is consumed, from the
+// next page fetched on. The pages are fetched with the context of the Next call scheduling them, a page canceled with it
+// is fetched again by the following call. It is ignored by the cursor Iterators, whose pages can only be fetched one
+// after the other
+func (it *Iterator) Prefetch(pages int) *Iterator {
+	if pages < 0 {
+		pages = 0
+	}
+	it.prefetch = pages
+	return it
+}
+
+// Next stores the next item in the value pointed to by out, fetching the next page when needed.
+// It returns io.EOF after the last item
+func (it *Iterator) Next(ctx context.Context, out interface{}) error {
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return fmt.Errorf("ctpx-sdk-go/common: Next needs a non nil pointer, not %T", out)
+	}
+
+	item, err := it.nextItem(ctx)
+	if err != nil {
+		return err
+	}
+	if !item.Type().AssignableTo(dst.Elem().Type()) {
+		return fmt.Errorf("ctpx-sdk-go/common: cannot store an item of type %s in %T", item.Type(), out)
+	}
+	dst.Elem().Set(item)
+	return nil
+}
+
+// All appends the remaining items to the slice pointed to by out
+func (it *Iterator) All(ctx context.Context, out interface{}) error {
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() || dst.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("ctpx-sdk-go/common: All needs a pointer to a slice, not %T", out)
+	}
+
+	slice := dst.Elem()
+	for {
+		item, err := it.nextItem(ctx)
+		if err == io.EOF {
+			dst.Elem().Set(slice)
+			return nil
+		}
+		if err != nil {
+			dst.Elem().Set(slice)
+			return err
+		}
+		if !item.Type().AssignableTo(slice.Type().Elem()) {
+			return fmt.Errorf("ctpx-sdk-go/common: cannot append an item of type %s to %T", item.Type(), out)
+		}
+		slice = reflect.Append(slice, item)
+	}
+}
+
+// Total returns the number of items across all the pages, once a page reporting it has been fetched
+func (it *Iterator) Total() (int, bool) {
+	if it.total == nil {
+		return 0, false
+	}
+	return *it.total, true
+}
+
+func (it *Iterator) nextItem(ctx context.Context) (reflect.Value, error) {
+	for it.err == nil && (!it.items.IsValid() || it.pos >= it.items.Len()) {
+		if it.done {
+			return reflect.Value{}, io.EOF
+		}
+		if err := it.nextPage(ctx); err != nil {
+			if err == ctx.Err() { //the page is still pending, the next call may fetch it with another context
+				return reflect.Value{}, err
+			}
+			it.err = err
+		}
+	}
+	if it.err != nil {
+		return reflect.Value{}, it.err
+	}
+
+	item := it.items.Index(it.pos)
+	it.pos++
+	return item, nil
+}
+
+func (it *Iterator) nextPage(ctx context.Context) error {
+	if len(it.pending) == 0 {
+		it.schedule(ctx, 1)
+	}
+	if len(it.pending) == 0 {
+		it.items, it.pos, it.done = reflect.Value{}, 0, true
+		return nil
+	}
+
+	var (
+		pending *pendingPage
+		res     pageResult
+	)
+	for {
+		pending = it.pending[0]
+		if pending.res == nil {
+			it.start(ctx, pending)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case res = <-pending.res:
+		}
+		if res.err == nil || pending.ctx.Err() == nil {
+			break
+		}
+		//canceled with the context of an earlier call rather than failed by the list API
+		pending.res = nil
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+	it.pending = it.pending[1:]
+	if res.err != nil {
+		return res.err
+	}
+
+	page := res.page
+	if page == nil {
+		page = &Page{}
+	}
+	items := reflect.ValueOf(page.Items)
+	if page.Items == nil {
+		items = reflect.ValueOf([]interface{}{})
+	}
+	if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+		return fmt.Errorf("ctpx-sdk-go/common: page items must be a slice, not %T", page.Items)
+	}
+	it.items, it.pos = items, 0
+	if page.Total != nil {
+		total := *page.Total
+		it.total = &total
+	}
+
+	if it.cursor {
+		it.done = page.Next == nil
+		it.next.Cursor = page.Next
+		it.next.Page++
+		it.next.Offset += items.Len()
+	} else if it.total != nil {
+		it.done = items.Len() == 0 || pending.req.Offset+items.Len() >= *it.total
+		if !it.done && items.Len() < pending.req.Size {
+			//the list API caps the size of the pages, the next ones start after the items it returned
+			it.pending = nil
+			it.next.Page, it.next.Offset = pending.req.Page+1, pending.req.Offset+items.Len()
+		}
+	} else {
+		it.done = items.Len() == 0 || items.Len() < pending.req.Size
+	}
+	if it.done {
+		it.pending = nil
+		return nil
+	}
+
+	if !it.cursor {
+		it.schedule(ctx, it.prefetch)
+	}
+	return nil
+}
+
+// schedule starts fetching the next pages until ahead pages are being fetched
+func (it *Iterator) schedule(ctx context.Context, ahead int) {
+	for len(it.pending) < ahead {
+		if it.total != nil && it.next.Offset >= *it.total {
+			return
+		}
+
+		pending := &pendingPage{req: it.next}
+		it.start(ctx, pending)
+		it.pending = append(it.pending, pending)
+
+		if !it.cursor {
+			it.next.Page++
+			it.next.Offset += it.next.Size
+		}
+	}
+}
+
+// start fetches the pending page with ctx
+func (it *Iterator) start(ctx context.Context, pending *pendingPage) {
+	res := make(chan pageResult, 1)
+	pending.ctx, pending.res = ctx, res
+	go func(req PageRequest) {
+		page, err := it.fetch(ctx, req)
+		res <- pageResult{page: page, err: err}
+	}(pending.req)
+}
@@ -0,0 +1,210 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pagedList serves the numbers from 0 to len-1 by offset and size
+type pagedList struct {
+	len   int
+	total bool
+	err   error
+
+	mu       sync.Mutex
+	requests []PageRequest
+}
+
+func (l *pagedList) fetch(_ context.Context, req PageRequest) (*Page, error) {
+	l.mu.Lock()
+	l.requests = append(l.requests, req)
+	l.mu.Unlock()
+	if l.err != nil {
+		return nil, l.err
+	}
+
+	items := []int{}
+	for i := req.Offset; i < req.Offset+req.Size && i < l.len; i++ {
+		items = append(items, i)
+	}
+	page := &Page{Items: items}
+	if l.total {
+		page.Total = IntP(l.len)
+	}
+	return page, nil
+}
+
+func TestIterator_Next(t *testing.T) {
+	list := &pagedList{len: 5}
+	it := NewIterator(list.fetch, PageRequest{Size: 2})
+
+	var items []int
+	for {
+		var item int
+		err := it.Next(context.Background(), &item)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, items)
+	assert.Equal(t, []PageRequest{
+		{Page: 1, Offset: 0, Size: 2},
+		{Page: 2, Offset: 2, Size: 2},
+		{Page: 3, Offset: 4, Size: 2},
+	}, list.requests)
+	_, ok := it.Total()
+	assert.False(t, ok)
+
+	var item int
+	assert.Equal(t, io.EOF, it.Next(context.Background(), &item))
+	assert.Error(t, it.Next(context.Background(), item))
+}
+
+func TestIterator_All(t *testing.T) {
+	list := &pagedList{len: 6, total: true}
+	it := NewIterator(list.fetch, PageRequest{Page: 2, Size: 2})
+
+	items := []int{-1}
+	require.NoError(t, it.All(context.Background(), &items))
+	assert.Equal(t, []int{-1, 2, 3, 4, 5}, items)
+	// the total says page 3 is the last one, no need to ask for an empty page 4
+	assert.Len(t, list.requests, 2)
+
+	total, ok := it.Total()
+	assert.True(t, ok)
+	assert.Equal(t, 6, total)
+
+	var wrong []string
+	assert.Error(t, NewIterator(list.fetch, PageRequest{}).All(context.Background(), &wrong))
+	assert.Error(t, it.All(context.Background(), items))
+}
+
+func TestIterator_Prefetch(t *testing.T) {
+	list := &pagedList{len: 10, total: true}
+	it := NewIterator(list.fetch, PageRequest{Size: 2}).Prefetch(3)
+
+	var item int
+	require.NoError(t, it.Next(context.Background(), &item))
+	assert.Eventually(t, func() bool {
+		list.mu.Lock()
+		defer list.mu.Unlock()
+		return len(list.requests) == 4
+	}, time.Second, time.Millisecond)
+
+	var items []int
+	require.NoError(t, it.All(context.Background(), &items))
+	assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+	assert.Len(t, list.requests, 5)
+}
+
+func TestIterator_ShortPages(t *testing.T) {
+	capped := func(list *pagedList) PageFetcher {
+		return func(ctx context.Context, req PageRequest) (*Page, error) {
+			req.Size = 3
+			return list.fetch(ctx, req)
+		}
+	}
+
+	// the pages of 3 items are short for a size of 5, the total says more items follow
+	list := &pagedList{len: 10, total: true}
+	var items []int
+	require.NoError(t, NewIterator(capped(list), PageRequest{Size: 5}).Prefetch(2).All(context.Background(), &items))
+	assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, items)
+	list.mu.Lock()
+	assert.Contains(t, list.requests, PageRequest{Page: 4, Offset: 9, Size: 3})
+	list.mu.Unlock()
+
+	items = nil
+	require.NoError(t, NewIterator(capped(&pagedList{len: 10}), PageRequest{Size: 5}).All(context.Background(), &items))
+	assert.Equal(t, []int{0, 1, 2}, items)
+}
+
+func TestIterator_PrefetchContext(t *testing.T) {
+	list := &pagedList{len: 10, total: true}
+	release := make(chan struct{})
+	fetch := func(ctx context.Context, req PageRequest) (*Page, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if req.Page > 1 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-release:
+			}
+		}
+		return list.fetch(ctx, req)
+	}
+	it := NewIterator(fetch, PageRequest{Size: 2}).Prefetch(2)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var item int
+	require.NoError(t, it.Next(ctx, &item))
+	// the pages prefetched with ctx are canceled along with it, they are fetched again by the next calls
+	cancel()
+	close(release)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	require.NoError(t, it.Next(ctx, &item))
+	assert.Equal(t, 1, item)
+	assert.Equal(t, context.Canceled, it.Next(ctx, &item))
+
+	var items []int
+	require.NoError(t, it.All(context.Background(), &items))
+	assert.Equal(t, []int{2, 3, 4, 5, 6, 7, 8, 9}, items)
+}
+
+func TestIterator_Errors(t *testing.T) {
+	list := &pagedList{len: 10, err: errors.New("unavailable")}
+	it := NewIterator(list.fetch, PageRequest{})
+
+	var items []int
+	assert.EqualError(t, it.All(context.Background(), &items), "unavailable")
+	var item int
+	assert.EqualError(t, it.Next(context.Background(), &item), "unavailable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	blocked := func(ctx context.Context, _ PageRequest) (*Page, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	assert.Equal(t, context.Canceled, NewIterator(blocked, PageRequest{}).Next(ctx, &item))
+}
+
+func TestCursorIterator(t *testing.T) {
+	pages := map[string]*Page{
+		"":   {Items: []string{"a", "b"}, Next: StringP("p2")},
+		"p2": {Items: []string{}, Next: StringP("p3")},
+		"p3": {Items: []string{"c"}},
+	}
+	var requests []PageRequest
+	fetch := func(_ context.Context, req PageRequest) (*Page, error) {
+		requests = append(requests, req)
+		cursor := ""
+		if req.Cursor != nil {
+			cursor = *req.Cursor
+		}
+		return pages[cursor], nil
+	}
+
+	var items []string
+	require.NoError(t, NewCursorIterator(fetch, 0).Prefetch(2).All(context.Background(), &items))
+	assert.Equal(t, []string{"a", "b", "c"}, items)
+	assert.Equal(t, []PageRequest{
+		{Page: 1, Size: DefaultPageSize},
+		{Page: 2, Offset: 2, Size: DefaultPageSize, Cursor: StringP("p2")},
+		{Page: 3, Offset: 2, Size: DefaultPageSize, Cursor: StringP("p3")},
+	}, requests)
+}
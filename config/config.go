@@ -0,0 +1,424 @@
+// Package config loads the SDK settings from environment variables, a YAML or JSON file and named profiles,
+// and builds every service client from them
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/log"
+	_ "github.com/secureworks/taegis-sdk-go/log/logrus"  //registers the logrus driver
+	_ "github.com/secureworks/taegis-sdk-go/log/zerolog" //registers the zerolog driver
+
+	"github.com/gobuffalo/envy"
+	"golang.org/x/oauth2"
+	"gopkg.in/yaml.v2"
+)
+
+// Environment variables read by Load, they take precedence over the config file
+const (
+	EnvConfigFile   = "CTPX_CONFIG"
+	EnvProfile      = "CTPX_PROFILE"
+	EnvBaseURL      = "CTPX_BASE_URL"
+	EnvWebsocketURL = "CTPX_WEBSOCKET_URL"
+	EnvTenant       = "CTPX_TENANT"
+	EnvToken        = "CTPX_TOKEN"
+	EnvClientID     = "CTPX_CLIENT_ID"
+	EnvClientSecret = "CTPX_CLIENT_SECRET"
+	EnvTokenURL     = "CTPX_TOKEN_URL"
+	EnvTimeout      = "CTPX_TIMEOUT"
+	EnvLogDriver    = "CTPX_LOG_DRIVER"
+	EnvLogLevel     = "CTPX_LOG_LEVEL"
+)
+
+// Service names usable as keys of Config.Services
+const (
+	ServiceAssets          = "assets"
+	ServiceCollectors      = "collectors"
+	ServiceRules           = "rules"
+	ServiceEvents          = "events"
+	ServiceInvestigations  = "investigations"
+	ServicePreferences     = "preferences"
+	ServiceUsers           = "users"
+	ServiceConnectors      = "connectors"
+	ServicePlaybooks       = "playbooks"
+	ServiceConnectorLogger = "connectorLogger"
+)
+
+// legacyServiceEnv are the per service URL environment variables read by the service packages themselves
+var legacyServiceEnv = map[string]string{
+	ServiceInvestigations: "INVESTIGATIONS_URL",
+	ServicePreferences:    "PREFERENCES_URL",
+	ServiceUsers:          "USER_SVC_URL",
+}
+
+const (
+	// DefaultBaseURL is the production GraphQL endpoint
+	DefaultBaseURL = "https://api.ctpx.secureworks.com/graphql"
+	// DefaultTimeout matches the client package default
+	DefaultTimeout = 5 * time.Second
+	// DefaultServiceName identifies the SDK to the services which ask for a caller name
+	DefaultServiceName = "ctpx-sdk-go"
+)
+
+// Config holds the settings shared by every service client
+type Config struct {
+	// BaseURL is the GraphQL endpoint used by every service without an entry in Services
+	BaseURL string `yaml:"base_url,omitempty" json:"base_url,omitempty"`
+	// WebsocketURL is the endpoint for subscriptions, it defaults to BaseURL with a ws or wss scheme
+	WebsocketURL string `yaml:"websocket_url,omitempty" json:"websocket_url,omitempty"`
+	// Services overrides BaseURL per service, keyed by the Service constants
+	Services    map[string]string `yaml:"services,omitempty" json:"services,omitempty"`
+	Tenant      string            `yaml:"tenant,omitempty" json:"tenant,omitempty"`
+	ServiceName string            `yaml:"service_name,omitempty" json:"service_name,omitempty"`
+	Credentials Credentials       `yaml:"credentials,omitempty" json:"credentials,omitempty"`
+	Timeout     time.Duration     `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	Log         Log               `yaml:"log,omitempty" json:"log,omitempty"`
+}
+
+// Credentials are either a static bearer Token or OAuth2 client credentials, client credentials win when both are set
+type Credentials struct {
+	Token        string   `yaml:"token,omitempty" json:"token,omitempty"`
+	ClientID     string   `yaml:"client_id,omitempty" json:"client_id,omitempty"`
+	ClientSecret string   `yaml:"client_secret,omitempty" json:"client_secret,omitempty"`
+	TokenURL     string   `yaml:"token_url,omitempty" json:"token_url,omitempty"`
+	Scopes       []string `yaml:"scopes,omitempty" json:"scopes,omitempty"`
+}
+
+// Log selects the logger given to the clients, no logging happens when Driver is empty
+type Log struct {
+	// Driver is a registered log driver, logrus or zerolog
+	Driver string `yaml:"driver,omitempty" json:"driver,omitempty"`
+	Level  string `yaml:"level,omitempty" json:"level,omitempty"`
+	// LocalDevel enables the human readable output of the driver
+	LocalDevel bool `yaml:"local_devel,omitempty" json:"local_devel,omitempty"`
+}
+
+// File is the layout of a config file. The top level settings apply to every profile,
+// a profile only needs the settings which differ, e.g.
+//
+//	tenant: "12345"
+//	default_profile: prod
+//	profiles:
+//	  prod:
+//	    base_url: https://api.ctpx.secureworks.com/graphql
+//	  dev:
+//	    base_url: https://dev.example.com/graphql
+//	    log:
+//	      driver: logrus
+//	      level: debug
+type File struct {
+	Config         `yaml:",inline"`
+	DefaultProfile string            `yaml:"default_profile,omitempty" json:"default_profile,omitempty"`
+	Profiles       map[string]Config `yaml:"profiles,omitempty" json:"profiles,omitempty"`
+}
+
+// Default returns the production settings
+func Default() *Config {
+	return &Config{
+		BaseURL:     DefaultBaseURL,
+		ServiceName: DefaultServiceName,
+		Timeout:     DefaultTimeout,
+	}
+}
+
+// Load returns the Default settings overridden by the file named in CTPX_CONFIG, using the profile named in CTPX_PROFILE,
+// and then by the CTPX_ environment variables. The legacy INVESTIGATIONS_URL, PREFERENCES_URL and USER_SVC_URL
+// variables are honoured as service overrides
+func Load() (*Config, error) {
+	cfg := Default()
+	if path := envy.Get(EnvConfigFile, ""); path != "" {
+		var err error
+		if cfg, err = LoadFile(path, envy.Get(EnvProfile, "")); err != nil {
+			return nil, err
+		}
+	} else if profile := envy.Get(EnvProfile, ""); profile != "" {
+		return nil, fmt.Errorf("ctpx-sdk-go/config: profile %q set without a config file", profile)
+	}
+
+	if err := cfg.applyEnv(func(k string) string { return envy.Get(k, "") }); err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// LoadFile reads a YAML or JSON config file, see File, and returns the Default settings overridden by it.
+// An empty profile selects the default_profile of the file, if any
+func LoadFile(path, profile string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ctpx-sdk-go/config: %w", err)
+	}
+	return Parse(data, profile)
+}
+
+// Parse is LoadFile for file contents, JSON is parsed as YAML
+func Parse(data []byte, profile string) (*Config, error) {
+	var f File
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return nil, fmt.Errorf("ctpx-sdk-go/config: invalid config: %w", err)
+	}
+
+	cfg := Default()
+	cfg.merge(&f.Config)
+
+	if profile == "" {
+		profile = f.DefaultProfile
+	}
+	if profile != "" {
+		p, ok := f.Profiles[profile]
+		if !ok {
+			return nil, fmt.Errorf("ctpx-sdk-go/config: unknown profile %q, available profiles: %s", profile, strings.Join(profileNames(f.Profiles), ", "))
+		}
+		cfg.merge(&p)
+	}
+	return cfg, cfg.Validate()
+}
+
+func profileNames(profiles map[string]Config) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// merge overrides c with the settings set in o
+func (c *Config) merge(o *Config) {
+	setStr := func(dst *string, v string) {
+		if v != "" {
+			*dst = v
+		}
+	}
+	setStr(&c.BaseURL, o.BaseURL)
+	setStr(&c.WebsocketURL, o.WebsocketURL)
+	setStr(&c.Tenant, o.Tenant)
+	setStr(&c.ServiceName, o.ServiceName)
+	setStr(&c.Credentials.Token, o.Credentials.Token)
+	setStr(&c.Credentials.ClientID, o.Credentials.ClientID)
+	setStr(&c.Credentials.ClientSecret, o.Credentials.ClientSecret)
+	setStr(&c.Credentials.TokenURL, o.Credentials.TokenURL)
+	setStr(&c.Log.Driver, o.Log.Driver)
+	setStr(&c.Log.Level, o.Log.Level)
+	if o.Credentials.Scopes != nil {
+		c.Credentials.Scopes = o.Credentials.Scopes
+	}
+	if o.Timeout != 0 {
+		c.Timeout = o.Timeout
+	}
+	if o.Log.LocalDevel {
+		c.Log.LocalDevel = true
+	}
+	for service, u := range o.Services {
+		if c.Services == nil {
+			c.Services = map[string]string{}
+		}
+		c.Services[service] = u
+	}
+}
+
+func (c *Config) applyEnv(env func(string) string) error {
+	o := Config{
+		BaseURL:      env(EnvBaseURL),
+		WebsocketURL: env(EnvWebsocketURL),
+		Tenant:       env(EnvTenant),
+		Credentials: Credentials{
+			Token:        env(EnvToken),
+			ClientID:     env(EnvClientID),
+			ClientSecret: env(EnvClientSecret),
+			TokenURL:     env(EnvTokenURL),
+		},
+		Log: Log{
+			Driver: env(EnvLogDriver),
+			Level:  env(EnvLogLevel),
+		},
+	}
+	if v := env(EnvTimeout); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("ctpx-sdk-go/config: invalid %s: %w", EnvTimeout, err)
+		}
+		o.Timeout = d
+	}
+	for service, k := range legacyServiceEnv {
+		if v := env(k); v != "" {
+			if o.Services == nil {
+				o.Services = map[string]string{}
+			}
+			o.Services[service] = v
+		}
+	}
+	c.merge(&o)
+	return nil
+}
+
+// Validate checks the URLs and that the client credentials are complete
+func (c *Config) Validate() error {
+	check := func(name, raw string, schemes ...string) error {
+		if raw == "" {
+			return nil
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			return fmt.Errorf("ctpx-sdk-go/config: invalid %s: %w", name, err)
+		}
+		for _, s := range schemes {
+			if u.Scheme == s {
+				return nil
+			}
+		}
+		return fmt.Errorf("ctpx-sdk-go/config: invalid %s %q: scheme must be one of %s", name, raw, strings.Join(schemes, ", "))
+	}
+
+	if c.BaseURL == "" {
+		return fmt.Errorf("ctpx-sdk-go/config: base_url is required")
+	}
+	if err := check("base_url", c.BaseURL, "http", "https"); err != nil {
+		return err
+	}
+	if err := check("websocket_url", c.WebsocketURL, "ws", "wss"); err != nil {
+		return err
+	}
+	for service, u := range c.Services {
+		if err := check("url for service "+service, u, "http", "https"); err != nil {
+			return err
+		}
+	}
+
+	creds := c.Credentials
+	if creds.ClientID != "" || creds.ClientSecret != "" {
+		if creds.ClientID == "" || creds.ClientSecret == "" || creds.TokenURL == "" {
+			return fmt.Errorf("ctpx-sdk-go/config: client credentials need client_id, client_secret and token_url")
+		}
+		if err := check("token_url", creds.TokenURL, "http", "https"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ServiceURL returns the URL of service, its Services entry if there is one or else BaseURL
+func (c *Config) ServiceURL(service string) string {
+	if u, ok := c.Services[service]; ok && u != "" {
+		return u
+	}
+	return c.BaseURL
+}
+
+// SubscriptionURL returns WebsocketURL, or BaseURL with its scheme changed to ws or wss
+func (c *Config) SubscriptionURL() (*url.URL, error) {
+	if c.WebsocketURL != "" {
+		return url.Parse(c.WebsocketURL)
+	}
+	u, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("ctpx-sdk-go/config: invalid protocol: %s", u.Scheme)
+	}
+	return u, nil
+}
+
+// Logger opens the configured log driver, it returns a no-op logger when none is set
+func (c *Config) Logger() (log.Logger, error) {
+	if c.Log.Driver == "" {
+		return log.Noop(), nil
+	}
+	lc := log.DefaultConfig(nil)
+	if c.Log.Level != "" {
+		lc.Level = log.LevelFromString(c.Log.Level)
+		if lc.Level == log.INFO && !strings.EqualFold(c.Log.Level, "info") { //unknown levels parse as the default
+			return nil, fmt.Errorf("ctpx-sdk-go/config: invalid log level %q", c.Log.Level)
+		}
+	}
+	lc.LocalDevel = c.Log.LocalDevel
+	logger, err := log.Open(c.Log.Driver, lc)
+	if err != nil {
+		return nil, fmt.Errorf("ctpx-sdk-go/config: %w", err)
+	}
+	return logger, nil
+}
+
+// TokenSource returns the token source of the credentials, nil when there are none.
+// A client credentials token source should be shared by every client so the token is fetched once
+func (c *Config) TokenSource() oauth2.TokenSource {
+	creds := c.Credentials
+	switch {
+	case creds.ClientID != "":
+		return client.NewClientCredentialsTokenSource(client.ClientCredentials{
+			ClientID:     creds.ClientID,
+			ClientSecret: creds.ClientSecret,
+			TokenURL:     creds.TokenURL,
+			Scopes:       creds.Scopes,
+		})
+	case creds.Token != "":
+		return oauth2.StaticTokenSource(&oauth2.Token{AccessToken: creds.Token})
+	default:
+		return nil
+	}
+}
+
+// ClientOptions returns the client options for the timeout, tenant, credentials and logger
+func (c *Config) ClientOptions() ([]client.Option, error) {
+	logger, err := c.Logger()
+	if err != nil {
+		return nil, err
+	}
+	return c.clientOptions(logger, c.TokenSource()), nil
+}
+
+func (c *Config) clientOptions(logger log.Logger, ts oauth2.TokenSource) []client.Option {
+	opts := []client.Option{client.WithLogger(logger)}
+	if c.Timeout > 0 {
+		opts = append(opts, client.WithHTTPTimeout(c.Timeout))
+	}
+	if c.Tenant != "" {
+		opts = append(opts, client.WithTenant(c.Tenant))
+	}
+	if ts != nil {
+		opts = append(opts, client.WithTokenSource(ts))
+	}
+	return opts
+}
+
+// NewClient returns a client.Client with the ClientOptions, followed by opts
+func (c *Config) NewClient(opts ...client.Option) (*client.Client, error) {
+	cOpts, err := c.ClientOptions()
+	if err != nil {
+		return nil, err
+	}
+	return client.NewClient(append(cOpts, opts...)...), nil
+}
+
+// SubscriptionOptions returns the subscription options for the tenant, credentials and logger
+func (c *Config) SubscriptionOptions() ([]graphql.SubscriptionOption, error) {
+	logger, err := c.Logger()
+	if err != nil {
+		return nil, err
+	}
+	return c.subscriptionOptions(logger, c.TokenSource()), nil
+}
+
+func (c *Config) subscriptionOptions(logger log.Logger, ts oauth2.TokenSource) []graphql.SubscriptionOption {
+	opts := []graphql.SubscriptionOption{graphql.SubscriptionWithLog(logger)}
+	if c.Tenant != "" {
+		opts = append(opts, graphql.SubscriptionWithTenant(c.Tenant))
+	}
+	if ts != nil {
+		opts = append(opts, graphql.SubscriptionWithTokenSource(ts))
+	}
+	return opts
+}
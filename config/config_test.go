@@ -0,0 +1,167 @@
+package config
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/envy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/orchestration/connectorLogger"
+)
+
+const testFile = `
+tenant: "1234"
+timeout: 10s
+credentials:
+  client_id: id
+  client_secret: secret
+  token_url: https://auth.example.com/token
+default_profile: prod
+profiles:
+  prod:
+    services:
+      users: https://users.example.com/graphql
+  dev:
+    base_url: http://localhost:8080/graphql
+    timeout: 1m
+    log:
+      driver: logrus
+      level: debug
+`
+
+func TestParseProfiles(t *testing.T) {
+	cfg, err := Parse([]byte(testFile), "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultBaseURL, cfg.BaseURL)
+	assert.Equal(t, "1234", cfg.Tenant)
+	assert.Equal(t, 10*time.Second, cfg.Timeout)
+	assert.Equal(t, "https://users.example.com/graphql", cfg.ServiceURL(ServiceUsers))
+	assert.Equal(t, DefaultBaseURL, cfg.ServiceURL(ServiceAssets))
+
+	cfg, err = Parse([]byte(testFile), "dev")
+	require.NoError(t, err)
+	assert.Equal(t, "http://localhost:8080/graphql", cfg.BaseURL)
+	assert.Equal(t, "1234", cfg.Tenant)
+	assert.Equal(t, time.Minute, cfg.Timeout)
+	assert.Equal(t, "id", cfg.Credentials.ClientID)
+	assert.Equal(t, Log{Driver: "logrus", Level: "debug"}, cfg.Log)
+
+	u, err := cfg.SubscriptionURL()
+	require.NoError(t, err)
+	assert.Equal(t, "ws://localhost:8080/graphql", u.String())
+
+	_, err = Parse([]byte(testFile), "staging")
+	assert.EqualError(t, err, `ctpx-sdk-go/config: unknown profile "staging", available profiles: dev, prod`)
+}
+
+func TestParseJSON(t *testing.T) {
+	cfg, err := Parse([]byte(`{
+	"base_url": "https://eu.example.com/graphql",
+	"websocket_url": "wss://eu.example.com/subscriptions",
+	"credentials": {"token": "token"}
+}`), "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://eu.example.com/graphql", cfg.BaseURL)
+	assert.Equal(t, "token", cfg.Credentials.Token)
+
+	u, err := cfg.SubscriptionURL()
+	require.NoError(t, err)
+	assert.Equal(t, "wss://eu.example.com/subscriptions", u.String())
+}
+
+func TestParseInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field":       "base_ur1: https://example.com",
+		"bad scheme":          "base_url: ftp://example.com",
+		"bad websocket":       "websocket_url: https://example.com",
+		"partial credentials": "credentials: {client_id: id}",
+		"bad service url":     "services: {users: 'localhost'}",
+	} {
+		_, err := Parse([]byte(data), "")
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte(testFile), 0600))
+
+	envy.Temp(func() {
+		envy.Set(EnvConfigFile, path)
+		envy.Set(EnvProfile, "dev")
+		envy.Set(EnvTenant, "5678")
+		envy.Set(EnvTimeout, "3s")
+		envy.Set("PREFERENCES_URL", "https://prefs.example.com/graphql")
+
+		cfg, err := Load()
+		require.NoError(t, err)
+		assert.Equal(t, "http://localhost:8080/graphql", cfg.BaseURL)
+		assert.Equal(t, "5678", cfg.Tenant)
+		assert.Equal(t, 3*time.Second, cfg.Timeout)
+		assert.Equal(t, "https://prefs.example.com/graphql", cfg.ServiceURL(ServicePreferences))
+
+		envy.Set(EnvTimeout, "soon")
+		_, err = Load()
+		assert.Error(t, err)
+
+		envy.Set(EnvConfigFile, "")
+		envy.Set(EnvTimeout, "")
+		_, err = Load()
+		assert.EqualError(t, err, `ctpx-sdk-go/config: profile "dev" set without a config file`)
+	})
+}
+
+func TestLogger(t *testing.T) {
+	cfg := Default()
+	_, err := cfg.Logger()
+	require.NoError(t, err)
+
+	cfg.Log = Log{Driver: "zerolog", Level: "warn"}
+	_, err = cfg.Logger()
+	require.NoError(t, err)
+
+	cfg.Log.Level = "loud"
+	_, err = cfg.Logger()
+	assert.Error(t, err)
+
+	cfg.Log = Log{Driver: "unknown"}
+	_, err = cfg.Logger()
+	assert.Error(t, err)
+}
+
+func TestNewServices(t *testing.T) {
+	var gotTenant, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotTenant = r.Header.Get(common.XTenantContextHeader)
+		gotAuth = r.Header.Get(common.AuthorizationHeader)
+		_, _ = w.Write([]byte(`{"data":{"getAllConnectorLogs":{}}}`))
+	}))
+	defer srv.Close()
+
+	cfg := Default()
+	cfg.Services = map[string]string{ServiceConnectorLogger: srv.URL}
+	cfg.Tenant = "1234"
+	cfg.Credentials.Token = "token"
+
+	services, err := cfg.NewServices()
+	require.NoError(t, err)
+	assert.NotNil(t, services.Assets)
+	assert.NotNil(t, services.Users)
+	assert.Len(t, services.SubscriptionOptions, 3)
+
+	_, err = services.ConnectorLogger.GetAllConnectorLogs(connectorLogger.ConnectorLogQueryInput{}, common.NewPaginationOptions(1, 1))
+	require.NoError(t, err)
+	assert.Equal(t, "1234", gotTenant)
+	assert.Equal(t, "Bearer token", gotAuth)
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"github.com/secureworks/taegis-sdk-go/assets"
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/collectors"
+	"github.com/secureworks/taegis-sdk-go/detect/rules"
+	"github.com/secureworks/taegis-sdk-go/events"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/investigations"
+	"github.com/secureworks/taegis-sdk-go/orchestration/connectorLogger"
+	"github.com/secureworks/taegis-sdk-go/orchestration/connectors"
+	"github.com/secureworks/taegis-sdk-go/orchestration/playbooks"
+	"github.com/secureworks/taegis-sdk-go/preferences"
+	"github.com/secureworks/taegis-sdk-go/users"
+)
+
+// Services holds a client for every SDK service, all built from the same Config
+type Services struct {
+	Assets          *assets.Client
+	Collectors      *collectors.Client
+	Rules           *rules.Client
+	Events          events.EventsSvc
+	Investigations  *investigations.InvestigationSvc
+	Preferences     *preferences.PreferencesSvc
+	Users           users.IUserSvc
+	Connectors      connectors.Service
+	Playbooks       playbooks.Service
+	ConnectorLogger connectorLogger.Service
+
+	// SubscriptionOptions carry the tenant, credentials and logger for the subscription methods of the services
+	SubscriptionOptions []graphql.SubscriptionOption
+}
+
+// NewServices builds every service client from c, opts are added after the ClientOptions.
+// The clients share one logger and one token source. The subscriptions of events, connectors and playbooks
+// use the websocket URL derived from their service URL, WebsocketURL only applies to SubscriptionURL
+func (c *Config) NewServices(opts ...client.Option) (*Services, error) {
+	logger, err := c.Logger()
+	if err != nil {
+		return nil, err
+	}
+	ts := c.TokenSource()
+	cOpts := append(c.clientOptions(logger, ts), opts...)
+	shared := client.NewClient(cOpts...)
+
+	return &Services{
+		Assets:              assets.New(c.ServiceURL(ServiceAssets), cOpts...),
+		Collectors:          collectors.NewWithClient(c.ServiceURL(ServiceCollectors), c.Tenant, shared),
+		Rules:               rules.NewWithClient(c.ServiceURL(ServiceRules), c.Tenant, shared),
+		Events:              events.New(c.ServiceURL(ServiceEvents), cOpts...),
+		Investigations:      investigations.NewInvestigationSvcWithURL(shared, c.ServiceName, c.ServiceURL(ServiceInvestigations)),
+		Preferences:         preferences.NewPreferencesSvcWithURL(shared, c.ServiceName, c.ServiceURL(ServicePreferences)),
+		Users:               users.NewUserSvcWithURL(shared, c.ServiceURL(ServiceUsers)),
+		Connectors:          connectors.New(c.ServiceURL(ServiceConnectors), cOpts...),
+		Playbooks:           playbooks.New(c.ServiceURL(ServicePlaybooks), cOpts...),
+		ConnectorLogger:     connectorLogger.New(c.ServiceURL(ServiceConnectorLogger), cOpts...),
+		SubscriptionOptions: c.subscriptionOptions(logger, ts),
+	}, nil
+}
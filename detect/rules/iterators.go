@@ -0,0 +1,63 @@
+package rules
+
+import (
+	"context"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+)
+
+// GetRulesIterator returns an Iterator over the *Rule items of GetRules, starting at page with pages of count rules.
+// The total is the rules count, asked for along with the first page
+func (c *Client) GetRulesIterator(page *int, count *int, ruleType *RuleType) *common.Iterator {
+	first := common.FirstPage(page, count)
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		rules, err := c.GetRulesCtx(ctx, &req.Page, &req.Size, ruleType)
+		if err != nil {
+			return nil, err
+		}
+		p := &common.Page{Items: rules}
+		if req.Page == first.Page {
+			total, err := c.GetRulesCountCtx(ctx, ruleType)
+			if err != nil {
+				return nil, err
+			}
+			p.Total = &total
+		}
+		return p, nil
+	}, first)
+}
+
+// GetDeletedRulesIterator returns an Iterator over the *Rule items of GetDeletedRules, starting at page with pages of
+// count rules
+func (c *Client) GetDeletedRulesIterator(page *int, count *int, ruleType *RuleType) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		rules, err := c.GetDeletedRulesCtx(ctx, &req.Page, &req.Size, ruleType)
+		if err != nil {
+			return nil, err
+		}
+		return &common.Page{Items: rules}, nil
+	}, common.FirstPage(page, count))
+}
+
+// GetRulesForEventIterator returns an Iterator over the *Rule items of GetRulesForEvent, starting at params.Page with
+// pages of params.Count rules. The total is the rules count for the event type, asked for along with the first page
+func (c *Client) GetRulesForEventIterator(params *GetRulesForEventArguments) *common.Iterator {
+	first := common.FirstPage(params.Page, params.Count)
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		args := *params
+		args.Page, args.Count = &req.Page, &req.Size
+		rules, err := c.GetRulesForEventCtx(ctx, &args)
+		if err != nil {
+			return nil, err
+		}
+		p := &common.Page{Items: rules}
+		if req.Page == first.Page {
+			total, err := c.GetRulesForEventCountCtx(ctx, params.EventType, params.RuleType)
+			if err != nil {
+				return nil, err
+			}
+			p.Total = &total
+		}
+		return p, nil
+	}, first)
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+)
+
+func TestClient_GetRulesIterator(t *testing.T) {
+	const total = 6
+	var pages []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Page     int    `json:"page"`
+				Count    int    `json:"count"`
+				RuleType string `json:"ruleType"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, string(RuleTypeRegex), req.Variables.RuleType)
+
+		if strings.Contains(req.Query, "rulesCount") {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"rulesCount": total}})
+			return
+		}
+		pages = append(pages, req.Variables.Page)
+		rules := []map[string]interface{}{}
+		for i := (req.Variables.Page - 1) * req.Variables.Count; i < req.Variables.Page*req.Variables.Count && i < total; i++ {
+			rules = append(rules, map[string]interface{}{"id": fmt.Sprint(i)})
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"rules": rules}})
+	}))
+	defer srv.Close()
+
+	ruleType := RuleTypeRegex
+	it := New(srv.URL, "123456789").GetRulesIterator(nil, common.IntP(2), &ruleType)
+
+	var rules []*Rule
+	require.NoError(t, it.All(context.Background(), &rules))
+	require.Len(t, rules, total)
+	assert.Equal(t, "5", rules[5].ID)
+	// the count tells the full third page is the last one
+	assert.Equal(t, []int{1, 2, 3}, pages)
+	n, ok := it.Total()
+	assert.True(t, ok)
+	assert.Equal(t, total, n)
+}
@@ -0,0 +1,58 @@
+package rules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+// TestClient_Schema checks the queries of every method against the bundled schema
+func TestClient_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL, "tenantID")
+
+	page, count := 1, 10
+	ruleType := RuleTypeRedql
+	eventType := RuleEventTypeProcess
+	name := "test"
+	yes := true
+	var severity float32 = 0.5
+	visibility := RuleVisibilityVisible
+	rule := RuleInput{
+		EventType: &eventType, Name: &name, Description: &name, Visibility: &visibility, ResultVisibility: &visibility,
+		Severity: &severity, Confidence: &severity, CreateAlert: &yes, Tags: []string{"tag"},
+		AttackCategories: []string{"category"}, EndpointPlatform: []RuleEndpointPlatform{RuleEndpointPlatformPlatformLinux},
+		References: []RuleReferenceInput{{Description: "test", URL: "https://example.com"}},
+	}
+	filter := RuleFilterInput{
+		Key: "key", Pattern: ".*", Inverted: &yes, CaseSensitive: &yes,
+		Count:      &RuleTermCountInput{Comparison: RuleCountComparisonGreaterThan, Value: 1},
+		TestShould: []string{"value"}, TestShouldNot: []string{"other"},
+	}
+	redQLFilter := RuleRedQLFilterInput{
+		Query:         "FROM process",
+		TestShould:    []RuleRedQLFilterTestInput{{FieldName: "field", FieldValue: "value"}},
+		TestShouldNot: []RuleRedQLFilterTestInput{{FieldName: "field", FieldValue: "other"}},
+	}
+
+	_, _ = c.GetRules(&page, &count, &ruleType)
+	_, _ = c.GetDeletedRules(&page, &count, &ruleType)
+	_, _ = c.GetRulesCount(&ruleType)
+	_, _ = c.GetRulesForEvent(&GetRulesForEventArguments{EventType: eventType, Page: &page, Count: &count, RuleType: &ruleType})
+	_, _ = c.GetRulesForEventCount(eventType, &ruleType)
+	_, _ = c.GetRule("id")
+	_, _ = c.GetFilterKeys(eventType)
+	_, _ = c.GetChangesSince(time.Now(), &eventType, &ruleType)
+	_, _ = c.CreateRule(rule, []RuleFilterInput{filter})
+	_, _ = c.AddFilterToRule("id", filter)
+	_, _ = c.UpdateRule("id", rule)
+	_, _ = c.DeleteRule("id")
+	_, _ = c.UpdateFilter("id", filter)
+	_, _ = c.DeleteFilter("id")
+	_, _ = c.CreateRedQLRule(rule, redQLFilter)
+	_, _ = c.UpdateRedQLFilter("id", redQLFilter)
+	_, _ = c.DisableRule("id")
+	_, _ = c.EnableRule("id")
+}
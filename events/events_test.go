@@ -1,6 +1,7 @@
 package events
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -34,3 +35,30 @@ func TestEventsSvc_GetEvents(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "123", event[0].ID)
 }
+
+// TestEventsSvc_Schema checks the queries of every method against the bundled schema
+func TestEventsSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL, client.WithHTTPTimeout(5*time.Second))
+
+	_, _ = c.GetEvents([]string{"id"})
+	_, _ = c.GetEventQuery("id")
+	_, _ = c.GetEventQueries()
+	_, _ = c.DeleteEventQuery("id")
+
+	ctx := context.Background()
+	yes, size := true, 10
+	sub, err := c.EventQuery(ctx, "FROM process", common.Object{"key": "value"}, &EventQueryOptions{
+		TimestampAscending: &yes, PageSize: &size, MaxRows: &size,
+	})
+	if assert.NoError(t, err) {
+		_, _ = sub.Next(ctx) //waits for the server to complete the subscription once validated
+		_ = sub.Close()
+	}
+	sub, err = c.EventPage(ctx, "id")
+	if assert.NoError(t, err) {
+		_, _ = sub.Next(ctx)
+		_ = sub.Close()
+	}
+}
@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// EventQueryIterator returns an Iterator over the results of the query across its pages, see NewEventQueryIterator
+func (s *eventsSvc) EventQueryIterator(query string, metadata common.Object, qopts *EventQueryOptions, sopts ...graphql.SubscriptionOption) *common.Iterator {
+	return NewEventQueryIterator(s, query, metadata, qopts, sopts...)
+}
+
+// NewEventQueryIterator returns an Iterator over the *EventQueryResults items of the query, the first page coming from
+// the EventQuery subscription and the next ones from EventPage, following the next page IDs
+func NewEventQueryIterator(s Subscriptions, query string, metadata common.Object, qopts *EventQueryOptions, sopts ...graphql.SubscriptionOption) *common.Iterator {
+	size := 0
+	if qopts != nil && qopts.PageSize != nil {
+		size = *qopts.PageSize
+	}
+	return common.NewCursorIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		var (
+			sub Subscription
+			err error
+		)
+		if req.Cursor == nil {
+			sub, err = s.EventQuery(ctx, query, metadata, qopts, sopts...)
+		} else {
+			sub, err = s.EventPage(ctx, *req.Cursor, sopts...)
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer sub.Close()
+
+		results, next, err := sub.GetAllEventResults(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// GetAllEventResults ends the page early without error when ctx is done
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return &common.Page{Items: results, Next: next}, nil
+	}, size)
+}
@@ -0,0 +1,68 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// pagedSubscriptions serves the results of pages by page ID, "" being the EventQuery page
+type pagedSubscriptions struct {
+	pages map[string]Results
+	ids   []string
+}
+
+func (p *pagedSubscriptions) EventQuery(_ context.Context, _ string, _ common.Object, _ *EventQueryOptions, _ ...graphql.SubscriptionOption) (Subscription, error) {
+	return p.page("")
+}
+
+func (p *pagedSubscriptions) EventPage(_ context.Context, id string, _ ...graphql.SubscriptionOption) (Subscription, error) {
+	return p.page(id)
+}
+
+func (p *pagedSubscriptions) page(id string) (Subscription, error) {
+	p.ids = append(p.ids, id)
+	results, ok := p.pages[id]
+	if !ok {
+		return nil, errors.New("invalid page identifier")
+	}
+	return pageSubscription(results), nil
+}
+
+type pageSubscription Results
+
+func (s pageSubscription) Next(context.Context) (*EventQueryResults, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s pageSubscription) GetAllEventResults(context.Context) (Results, *string, error) {
+	return Results(s), Results(s).GetNextPageID(), nil
+}
+
+func (s pageSubscription) Close() error {
+	return nil
+}
+
+func TestEventQueryIterator(t *testing.T) {
+	last := &EventQueryResults{Result: &EventQueryResult{ID: "last"}}
+	subs := &pagedSubscriptions{pages: map[string]Results{
+		"":           {testEventQueryResultsOne, testEventQueryResultsTwo},
+		testNextPage: {last},
+	}}
+
+	var results []*EventQueryResults
+	require.NoError(t, NewEventQueryIterator(subs, "query", nil, nil).All(context.Background(), &results))
+	assert.Equal(t, []*EventQueryResults{testEventQueryResultsOne, testEventQueryResultsTwo, last}, results)
+	assert.Equal(t, []string{"", testNextPage}, subs.ids)
+
+	delete(subs.pages, testNextPage)
+	results = nil
+	assert.EqualError(t, NewEventQueryIterator(subs, "query", nil, nil).All(context.Background(), &results), "invalid page identifier")
+	assert.Len(t, results, 2)
+}
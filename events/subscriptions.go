@@ -207,6 +207,8 @@ func (s *subscription) Next(ctx context.Context) (*EventQueryResults, error) {
 		switch t := msg.Payload.(type) {
 		case graphql.KeepAliveMessage:
 			return &EventQueryResults{}, nil
+		case graphql.ReconnectedMessage:
+			return nil, graphql.ErrReconnected
 		case *eventQueryResult:
 			return t.EventQueryResults, nil
 		case *eventPageResult:
@@ -0,0 +1,152 @@
+package graphql
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// BackpressurePolicy selects what a subscription does with a message when the consumer is too slow
+// and the Messages channel is full
+type BackpressurePolicy int
+
+const (
+	// BackpressureFail ends the subscription, the default
+	BackpressureFail BackpressurePolicy = iota
+	// BackpressureBlock waits for the consumer, see SubscriptionBlockWhenFull
+	BackpressureBlock
+	// BackpressureDropOldest discards the oldest buffered message to make room, see SubscriptionDropOldest
+	BackpressureDropOldest
+	// BackpressureDropNewest discards the new message, see SubscriptionDropNewest
+	BackpressureDropNewest
+	// BackpressureSpill queues the messages in a file until the consumer catches up, see SubscriptionSpillToDisk
+	BackpressureSpill
+)
+
+// SubscriptionWithBufferSize sets the size of the Messages channel, defaults to 1024
+func SubscriptionWithBufferSize(n int) SubscriptionOption {
+	return func(s *Subscription) {
+		if n > 0 {
+			s.bufferSize = n
+		}
+	}
+}
+
+// SubscriptionBlockWhenFull waits for the consumer to read a message when the channel is full.
+// The subscription ends if ctx is done first. While blocked no message is read from the websocket,
+// so the server may drop the connection, and the other subscriptions of a Multiplexer websocket are blocked too
+func SubscriptionBlockWhenFull(ctx context.Context) SubscriptionOption {
+	return func(s *Subscription) {
+		s.backpressure = BackpressureBlock
+		s.blockCtx = ctx
+	}
+}
+
+// SubscriptionDropOldest discards the oldest buffered message when the channel is full, see Subscription.Dropped
+func SubscriptionDropOldest(s *Subscription) {
+	s.backpressure = BackpressureDropOldest
+}
+
+// SubscriptionDropNewest discards the received message when the channel is full, see Subscription.Dropped
+func SubscriptionDropNewest(s *Subscription) {
+	s.backpressure = BackpressureDropNewest
+}
+
+// SubscriptionSpillToDisk queues the messages in a temporary file of dir when the channel is full, and moves them
+// to the channel in order as the consumer catches up. The file holds up to maxBytes, 0 for no limit, the messages
+// which do not fit are dropped. Data payloads are stored as JSON and decoded again with the response creator.
+// Queued messages are still delivered when the server ends the subscription, except for the subscriptions
+// of a Multiplexer, and are discarded on Shutdown
+func SubscriptionSpillToDisk(dir string, maxBytes int64) SubscriptionOption {
+	return func(s *Subscription) {
+		s.backpressure = BackpressureSpill
+		s.spillDir = dir
+		s.spillMax = maxBytes
+	}
+}
+
+// Dropped returns the number of messages discarded because the consumer was too slow
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription) drop() {
+	atomic.AddUint64(&s.dropped, 1)
+	s.metrics.SubscriptionMessageDropped(s.operation)
+}
+
+// initMessages creates the Messages channel and the spill queue
+func (s *Subscription) initMessages() error {
+	s.ch = make(chan *Message, s.bufferSize)
+	if s.backpressure == BackpressureSpill {
+		var err error
+		if s.spill, err = newSpillQueue(s, s.spillDir, s.spillMax); err != nil {
+			return errors.Wrap(err, "failed creating spill queue")
+		}
+	}
+	return nil
+}
+
+// closeMessages delivers the spilled messages unless the subscription is shut down, then closes the channel
+func (s *Subscription) closeMessages() {
+	if s.spill != nil {
+		s.spill.close()
+	}
+	close(s.ch)
+}
+
+func (s *Subscription) informMessageReceived(m *Message) error {
+	if s.deliverMessage != nil {
+		return s.deliverMessage(m)
+	}
+	return s.deliver(m)
+}
+
+// deliver sends m to the Messages channel according to the backpressure policy
+func (s *Subscription) deliver(m *Message) error {
+	if s.backpressure == BackpressureSpill { //keeps the order of the queued messages
+		return s.spill.push(m)
+	}
+	select {
+	case s.ch <- m:
+		return nil
+	default:
+	}
+
+	switch s.backpressure {
+	case BackpressureBlock:
+		ctx := s.blockCtx
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		select {
+		case s.ch <- m:
+			return nil
+		case <-ctx.Done():
+			s.drop()
+			return errors.Wrap(ctx.Err(), errSubscriptionFailedSendingMessage.Error())
+		case <-s.closed:
+			return errSubscriptionCompleted
+		}
+	case BackpressureDropOldest:
+		for {
+			select {
+			case s.ch <- m:
+				return nil
+			default:
+			}
+			select {
+			case <-s.ch:
+				s.drop()
+			default:
+			}
+		}
+	case BackpressureDropNewest:
+		s.drop()
+		return nil
+	default:
+		s.drop()
+		return errSubscriptionFailedSendingMessage
+	}
+}
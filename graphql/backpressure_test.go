@@ -0,0 +1,227 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// slowConsumer subscribes to a mock server sending outputs without reading them until the server is done,
+// the returned func shuts the subscription and the server down
+func slowConsumer(t *testing.T, outputs []interface{}, opts ...graphql.SubscriptionOption) (*graphql.Subscription, func()) {
+	q := "subscription test"
+	vars := map[string]interface{}{"test": "test"}
+	s := graphql.NewMockSubServer(t, q, vars, outputs...)
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+
+	sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+		return new(int)
+	}, append(opts, graphql.SubscriptionWithVars(vars), graphql.SubscriptionWithBufferSize(1))...)
+	if err != nil {
+		cancel()
+		s.Close()
+		require.NoError(t, err)
+	}
+	return sub, func() {
+		_ = sub.Shutdown(context.TODO())
+		cancel()
+		s.Close()
+	}
+}
+
+func receiveInts(t *testing.T, sub *graphql.Subscription, n int) []int {
+	var out []int
+	for i := 0; i < n; i++ {
+		select {
+		case m, ok := <-sub.Messages():
+			require.True(t, ok)
+			require.NoError(t, m.Err)
+			out = append(out, *m.Payload.(*int))
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "channel timed out")
+		}
+	}
+	return out
+}
+
+func TestBackpressure_Fail(t *testing.T) {
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3})
+	defer done()
+	assert.Eventually(t, func() bool { return sub.Dropped() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1}, receiveInts(t, sub, 1))
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+}
+
+func TestBackpressure_DropNewest(t *testing.T) {
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3}, graphql.SubscriptionDropNewest)
+	defer done()
+	assert.Eventually(t, func() bool { return sub.Dropped() == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1}, receiveInts(t, sub, 1))
+}
+
+func TestBackpressure_DropOldest(t *testing.T) {
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3}, graphql.SubscriptionDropOldest)
+	defer done()
+	assert.Eventually(t, func() bool { return sub.Dropped() == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{3}, receiveInts(t, sub, 1))
+}
+
+func TestBackpressure_Block(t *testing.T) {
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3}, graphql.SubscriptionBlockWhenFull(context.Background()))
+	defer done()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3}, receiveInts(t, sub, 3))
+	assert.Zero(t, sub.Dropped())
+}
+
+func TestBackpressure_BlockContextDone(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3}, graphql.SubscriptionBlockWhenFull(ctx))
+	defer done()
+	assert.Eventually(t, func() bool { return sub.Dropped() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1}, receiveInts(t, sub, 1))
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+}
+
+func TestBackpressure_Spill(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3, 4, 5}, graphql.SubscriptionSpillToDisk(dir, 0))
+	defer done()
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, receiveInts(t, sub, 5))
+	assert.Zero(t, sub.Dropped())
+
+	require.NoError(t, sub.Shutdown(context.TODO()))
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, files)
+}
+
+func TestBackpressure_SpillFull(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	sub, done := slowConsumer(t, []interface{}{1, 2, 3, 4}, graphql.SubscriptionSpillToDisk(dir, 64))
+	defer done()
+	assert.Eventually(t, func() bool { return sub.Dropped() > 0 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2}, receiveInts(t, sub, 2))
+}
+
+// newStepServer sends a data message with every value received from send
+func newStepServer(t *testing.T, send <-chan int) *httptest.Server {
+	var upgrader websocket.Upgrader
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var msg struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "connection_ack"}))
+		require.NoError(t, conn.ReadJSON(&msg))
+		for n := range send {
+			require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "data", "id": msg.ID, "payload": map[string]interface{}{"data": n}}))
+		}
+	}))
+	return s
+}
+
+func TestBackpressure_SpillLiveBytes(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	send := make(chan int)
+	defer close(send)
+	s := newStepServer(t, send)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	//a record is 31 bytes, the file holds 2
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithBufferSize(1), graphql.SubscriptionSpillToDisk(dir, 64))
+	require.NoError(t, err)
+	defer func() { _ = sub.Shutdown(context.TODO()) }()
+
+	for n := 1; n <= 3; n++ {
+		send <- n
+	}
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []int{1}, receiveInts(t, sub, 1))
+	time.Sleep(50 * time.Millisecond)
+
+	//2 was delivered, its room is reused
+	send <- 4
+	time.Sleep(50 * time.Millisecond)
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.LessOrEqual(t, files[0].Size(), int64(64))
+
+	assert.Equal(t, []int{2, 3, 4}, receiveInts(t, sub, 3))
+	assert.Zero(t, sub.Dropped())
+}
+
+func TestBackpressure_SpillSentinelErrors(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spill")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	release := make(chan struct{})
+	defer close(release)
+	s := newKeepAliveServer(t, 1, release)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithBufferSize(1), graphql.SubscriptionSpillToDisk(dir, 0),
+		graphql.SubscriptionWithKeepAliveTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	//the timeout message is spilled behind the data message
+	time.Sleep(300 * time.Millisecond)
+	var msgs []*graphql.Message
+	for m := range sub.Messages() {
+		msgs = append(msgs, m)
+	}
+	require.Len(t, msgs, 2)
+	assert.Equal(t, 1, *msgs[0].Payload.(*int))
+	assert.True(t, errors.Is(msgs[1].Err, graphql.ErrKeepAliveTimeout))
+}
@@ -0,0 +1,512 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/formatter"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// BatchMode selects how the requests of a Batch are sent
+type BatchMode int
+
+const (
+	// BatchArray sends the requests as a JSON array, the server responds with an array of responses
+	BatchArray BatchMode = iota
+	// BatchMerge merges the requests into one document, prefixing their root fields, variables and fragments,
+	// for servers which do not accept arrays. The requests must all be queries or all be mutations
+	BatchMerge
+)
+
+const (
+	defaultLoaderWait     = 5 * time.Millisecond
+	defaultLoaderMaxBatch = 50
+)
+
+// BatchItem is a request added to a Batch, Err is set once the batch is executed
+type BatchItem struct {
+	Request *Request
+	Output  interface{}
+	Err     error
+}
+
+// BatchOption sets optional behaviour of a Batch
+type BatchOption func(b *Batch)
+
+// BatchWithMode sets how the requests are sent, defaults to BatchArray
+func BatchWithMode(mode BatchMode) BatchOption {
+	return func(b *Batch) {
+		b.mode = mode
+	}
+}
+
+// Batch collects requests to send them in a single HTTP request. Every response is decoded into the output
+// of its request and errors are reported per request, in the Err of each BatchItem
+type Batch struct {
+	mode  BatchMode
+	items []*BatchItem
+}
+
+// NewBatch returns an empty batch
+func NewBatch(opts ...BatchOption) *Batch {
+	b := &Batch{}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Add adds req to the batch, its response is decoded into out if not nil
+func (b *Batch) Add(req *Request, out interface{}) *BatchItem {
+	item := &BatchItem{Request: req, Output: out}
+	b.items = append(b.items, item)
+	return item
+}
+
+// Items returns the requests of the batch in the order they were added
+func (b *Batch) Items() []*BatchItem {
+	return b.items
+}
+
+// Err combines the errors of the failed requests, it is nil when every request succeeded
+func (b *Batch) Err() error {
+	var err error
+	for i, item := range b.items {
+		if item.Err != nil {
+			err = multierror.Append(err, fmt.Errorf("operation %d: %w", i, item.Err))
+		}
+	}
+	return err
+}
+
+// ExecuteContext sends the batch with cli. The headers of the requests are merged, so requests for different
+// tenants or tokens must go in different batches. The returned error is set when the whole batch failed,
+// in which case it is also the Err of every item. Errors of single requests are only reported by their BatchItem
+func (b *Batch) ExecuteContext(ctx context.Context, cli HTTPClient, serverURL string) error {
+	err := b.execute(ctx, cli, serverURL)
+	if err != nil {
+		for _, item := range b.items {
+			item.Err = err
+		}
+	}
+	return err
+}
+
+func (b *Batch) execute(ctx context.Context, cli HTTPClient, serverURL string) error {
+	if ctx == nil || cli == nil || serverURL == "" {
+		return errors.New("ctpx-sdk-go/graphql: nil ctx, client or empty server url to Batch.ExecuteContext")
+	}
+	if len(b.items) == 0 {
+		return nil
+	}
+
+	header := http.Header{}
+	for i, item := range b.items {
+		if item.Request == nil {
+			return fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d has no request", i)
+		}
+		for k, v := range item.Request.Header {
+			if existing, ok := header[k]; ok && strings.Join(existing, ",") != strings.Join(v, ",") {
+				return fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d sets a different %s header", i, k)
+			}
+			header[k] = v
+		}
+	}
+
+	var (
+		payload interface{}
+		merged  *mergedBatch
+	)
+	switch b.mode {
+	case BatchMerge:
+		var err error
+		if merged, err = mergeRequests(b.items); err != nil {
+			return err
+		}
+		payload = merged.request
+	default:
+		reqs := make([]batchRequest, len(b.items))
+		for i, item := range b.items {
+			reqs[i] = batchRequest{Query: item.Request.Query, Variables: item.Request.Variables}
+		}
+		payload = reqs
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, 256))
+	if err := json.NewEncoder(buf).Encode(payload); err != nil {
+		return err
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, serverURL, buf)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		request.Header[k] = v
+	}
+
+	resp, err := cli.Do(request)
+	if err != nil {
+		return fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "server connection error", Err: err})
+	}
+	defer resp.Body.Close()
+	var statusErr error
+	if resp.StatusCode >= http.StatusBadRequest {
+		statusErr = fmt.Errorf("ctpx-sdk-go/graphql: %w", &HTTPError{StatusCode: resp.StatusCode})
+	}
+
+	var responses []rawResponse
+	if merged != nil {
+		var raw rawResponse
+		err = json.NewDecoder(resp.Body).Decode(&raw)
+		if err == nil {
+			responses, err = merged.split(raw)
+		}
+	} else {
+		err = json.NewDecoder(resp.Body).Decode(&responses)
+		if err == nil && len(responses) != len(b.items) {
+			err = fmt.Errorf("got %d responses for %d requests", len(responses), len(b.items))
+		}
+	}
+	if err != nil {
+		err = fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "error decoding response", Err: err})
+		if statusErr != nil {
+			return multierror.Append(statusErr, err)
+		}
+		return err
+	}
+
+	for i, item := range b.items {
+		var outErr error
+		if statusErr != nil {
+			outErr = multierror.Append(outErr, statusErr)
+		}
+		if err := responses[i].decode(item.Output); err != nil {
+			item.Err = multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "error decoding response", Err: err}))
+			continue
+		}
+		item.Err = responses[i].err(outErr, item.Request.partialData)
+	}
+	return nil
+}
+
+type batchRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// mergedBatch is the single request made of the requests of a BatchMerge batch
+type mergedBatch struct {
+	request  batchRequest
+	prefixes []string
+}
+
+func batchPrefix(i int) string {
+	return fmt.Sprintf("b%d_", i)
+}
+
+func mergeRequests(items []*BatchItem) (*mergedBatch, error) {
+	m := &mergedBatch{request: batchRequest{Variables: map[string]interface{}{}}}
+	merged := &ast.QueryDocument{}
+	op := &ast.OperationDefinition{Name: "Batch"}
+	merged.Operations = ast.OperationList{op}
+
+	for i, item := range items {
+		doc, gqlErr := parser.ParseQuery(&ast.Source{Input: item.Request.Query})
+		if gqlErr != nil {
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d: %w", i, gqlErr)
+		}
+		if len(doc.Operations) != 1 {
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d must have exactly one operation", i)
+		}
+		src := doc.Operations[0]
+		switch {
+		case src.Operation == ast.Subscription:
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d is a subscription", i)
+		case i > 0 && src.Operation != op.Operation:
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d is a %s, cannot be merged with a %s", i, src.Operation, op.Operation)
+		case len(src.Directives) > 0:
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d has operation directives, which cannot be merged", i)
+		}
+		op.Operation = src.Operation
+
+		prefix := batchPrefix(i)
+		m.prefixes = append(m.prefixes, prefix)
+		for _, def := range src.VariableDefinitions {
+			def.Variable = prefix + def.Variable
+		}
+		op.VariableDefinitions = append(op.VariableDefinitions, src.VariableDefinitions...)
+		for k, v := range item.Request.Variables {
+			m.request.Variables[prefix+k] = v
+		}
+
+		if err := aliasRootSelections(src.SelectionSet, prefix); err != nil {
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: batch operation %d: %w", i, err)
+		}
+		renameSelections(src.SelectionSet, prefix)
+		op.SelectionSet = append(op.SelectionSet, src.SelectionSet...)
+
+		for _, frag := range doc.Fragments {
+			frag.Name = prefix + frag.Name
+			renameDirectives(frag.Directives, prefix)
+			renameSelections(frag.SelectionSet, prefix)
+		}
+		merged.Fragments = append(merged.Fragments, doc.Fragments...)
+	}
+
+	var query bytes.Buffer
+	formatter.NewFormatter(&query).FormatQueryDocument(merged)
+	m.request.Query = query.String()
+	return m, nil
+}
+
+// aliasRootSelections prefixes the response keys of the root fields, including those of root inline fragments
+func aliasRootSelections(set ast.SelectionSet, prefix string) error {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			sel.Alias = prefix + sel.Alias
+		case *ast.InlineFragment:
+			if err := aliasRootSelections(sel.SelectionSet, prefix); err != nil {
+				return err
+			}
+		case *ast.FragmentSpread:
+			return fmt.Errorf("fragment spread ...%s at the root of the operation cannot be merged", sel.Name)
+		}
+	}
+	return nil
+}
+
+// renameSelections prefixes the variables and fragment spreads used in set
+func renameSelections(set ast.SelectionSet, prefix string) {
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			for _, arg := range sel.Arguments {
+				renameValue(arg.Value, prefix)
+			}
+			renameDirectives(sel.Directives, prefix)
+			renameSelections(sel.SelectionSet, prefix)
+		case *ast.InlineFragment:
+			renameDirectives(sel.Directives, prefix)
+			renameSelections(sel.SelectionSet, prefix)
+		case *ast.FragmentSpread:
+			sel.Name = prefix + sel.Name
+			renameDirectives(sel.Directives, prefix)
+		}
+	}
+}
+
+func renameDirectives(directives ast.DirectiveList, prefix string) {
+	for _, d := range directives {
+		for _, arg := range d.Arguments {
+			renameValue(arg.Value, prefix)
+		}
+	}
+}
+
+func renameValue(v *ast.Value, prefix string) {
+	if v == nil {
+		return
+	}
+	if v.Kind == ast.Variable {
+		v.Raw = prefix + v.Raw
+	}
+	for _, child := range v.Children {
+		renameValue(child.Value, prefix)
+	}
+}
+
+// split demultiplexes the response of the merged request into one response per request.
+// Errors without a path to a prefixed field are reported to every request
+func (m *mergedBatch) split(raw rawResponse) ([]rawResponse, error) {
+	out := make([]rawResponse, len(m.prefixes))
+
+	if raw.hasData() {
+		var data map[string]json.RawMessage
+		if err := json.Unmarshal(raw.Data, &data); err != nil {
+			return nil, err
+		}
+		parts := make([]map[string]json.RawMessage, len(m.prefixes))
+		for k, v := range data {
+			if i := m.index(k); i >= 0 {
+				if parts[i] == nil {
+					parts[i] = map[string]json.RawMessage{}
+				}
+				parts[i][strings.TrimPrefix(k, m.prefixes[i])] = v
+			}
+		}
+		for i, part := range parts {
+			if part == nil {
+				continue
+			}
+			b, err := json.Marshal(part)
+			if err != nil {
+				return nil, err
+			}
+			out[i].Data = b
+		}
+	}
+
+	for _, e := range raw.Errors {
+		i := -1
+		if len(e.Path) > 0 {
+			i = m.index(e.Path[0])
+		}
+		if i < 0 {
+			for j := range out {
+				out[j].Errors = append(out[j].Errors, e)
+			}
+			continue
+		}
+		e.Path = append([]string{strings.TrimPrefix(e.Path[0], m.prefixes[i])}, e.Path[1:]...)
+		out[i].Errors = append(out[i].Errors, e)
+	}
+	return out, nil
+}
+
+func (m *mergedBatch) index(key string) int {
+	for i, prefix := range m.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoaderOption sets optional behaviour of a Loader
+type LoaderOption func(l *Loader)
+
+// LoaderWait sets how long a Loader collects requests before sending them, defaults to 5ms
+func LoaderWait(d time.Duration) LoaderOption {
+	return func(l *Loader) {
+		l.wait = d
+	}
+}
+
+// LoaderMaxBatch sends a batch as soon as it has n requests, defaults to 50
+func LoaderMaxBatch(n int) LoaderOption {
+	return func(l *Loader) {
+		if n > 0 {
+			l.maxBatch = n
+		}
+	}
+}
+
+// LoaderMode sets the BatchMode of the batches, defaults to BatchArray
+func LoaderMode(mode BatchMode) LoaderOption {
+	return func(l *Loader) {
+		l.mode = mode
+	}
+}
+
+// Loader coalesces the requests made within a short window into batches, dataloader style.
+// Requests with different headers, such as tenants, go in different batches
+type Loader struct {
+	cli       HTTPClient
+	serverURL string
+	wait      time.Duration
+	maxBatch  int
+	mode      BatchMode
+
+	mu      sync.Mutex
+	pending map[string]*pendingBatch
+}
+
+type pendingBatch struct {
+	batch *Batch
+	done  chan struct{}
+}
+
+// NewLoader returns a Loader sending its batches to serverURL with cli
+func NewLoader(cli HTTPClient, serverURL string, opts ...LoaderOption) *Loader {
+	l := &Loader{
+		cli:       cli,
+		serverURL: serverURL,
+		wait:      defaultLoaderWait,
+		maxBatch:  defaultLoaderMaxBatch,
+		pending:   map[string]*pendingBatch{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Query adds req to the current batch and waits for its response, which is decoded into out.
+// Batches are sent detached from ctx, when ctx is done Query returns its error without waiting for the batch
+func (l *Loader) Query(ctx context.Context, req *Request, out interface{}) error {
+	if ctx == nil || req == nil {
+		return errors.New("ctpx-sdk-go/graphql: nil ctx or request to Loader.Query")
+	}
+	key := headerKey(req.Header)
+	var data json.RawMessage
+
+	l.mu.Lock()
+	p, ok := l.pending[key]
+	if !ok {
+		p = &pendingBatch{batch: NewBatch(BatchWithMode(l.mode)), done: make(chan struct{})}
+		l.pending[key] = p
+		time.AfterFunc(l.wait, func() { l.flush(key, p) })
+	}
+	item := p.batch.Add(req, &data)
+	if len(p.batch.items) >= l.maxBatch {
+		delete(l.pending, key)
+		go l.send(p)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if item.Err != nil && !IsPartialData(item.Err) {
+		return item.Err
+	}
+	if out != nil && len(data) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "error decoding response", Err: err})
+		}
+	}
+	return item.Err
+}
+
+func (l *Loader) flush(key string, p *pendingBatch) {
+	l.mu.Lock()
+	if l.pending[key] != p { //already sent for reaching the max batch size
+		l.mu.Unlock()
+		return
+	}
+	delete(l.pending, key)
+	l.mu.Unlock()
+	l.send(p)
+}
+
+func (l *Loader) send(p *pendingBatch) {
+	_ = p.batch.ExecuteContext(context.Background(), l.cli, l.serverURL)
+	close(p.done)
+}
+
+func headerKey(header http.Header) string {
+	keys := make([]string, 0, len(header))
+	for k := range header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&b, "%s=%s\n", k, strings.Join(header[k], ","))
+	}
+	return b.String()
+}
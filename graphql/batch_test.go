@@ -0,0 +1,194 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+type countOutput struct {
+	Count struct {
+		Total int `json:"total"`
+	} `json:"count"`
+}
+
+type tagsOutput struct {
+	Tags []string `json:"tags"`
+}
+
+func TestBatch_Array(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqs []graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		require.Len(t, reqs, 2)
+		assert.Equal(t, "query { count { total } }", reqs[0].Query)
+		assert.Equal(t, "tenant", r.Header.Get("X-Tenant-Context"))
+		_, _ = w.Write([]byte(`[{"data":{"count":{"total":3}}},{"data":null,"errors":[{"message":"no tags","path":["tags"],"extensions":{"code":"NOT_FOUND"}}]}]`))
+	}))
+	defer server.Close()
+
+	var (
+		count countOutput
+		tags  tagsOutput
+		b     = graphql.NewBatch()
+	)
+	countItem := b.Add(graphql.NewRequest("query { count { total } }", graphql.RequestWithTenant("tenant")), &count)
+	tagsItem := b.Add(graphql.NewRequest("query { tags }", graphql.RequestWithTenant("tenant")), &tags)
+	require.NoError(t, b.ExecuteContext(context.Background(), client.NewClient(), server.URL))
+
+	assert.NoError(t, countItem.Err)
+	assert.Equal(t, 3, count.Count.Total)
+	assert.True(t, errors.Is(tagsItem.Err, graphql.ErrNotFound))
+	assert.True(t, errors.Is(b.Err(), graphql.ErrNotFound))
+}
+
+func TestBatch_ArrayResponseMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"data":{"count":{"total":3}}}]`))
+	}))
+	defer server.Close()
+
+	b := graphql.NewBatch()
+	item := b.Add(graphql.NewRequest("query { count { total } }"), nil)
+	b.Add(graphql.NewRequest("query { tags }"), nil)
+	err := b.ExecuteContext(context.Background(), client.NewClient(), server.URL)
+
+	var transportErr *graphql.TransportError
+	require.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, err, item.Err)
+}
+
+func TestBatch_Merge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, `query Batch ($b0_type: String, $b1_first: Int) {
+	b0_count: assetCount(type: $b0_type) {
+		total
+	}
+	b1_tags: allTags(first: $b1_first) {
+		... b1_TagFields
+	}
+}
+fragment b1_TagFields on Tag {
+	name
+}
+`, req.Query)
+		assert.Equal(t, map[string]interface{}{"b0_type": "endpoint", "b1_first": float64(2)}, req.Variables)
+		_, _ = w.Write([]byte(`{"data":{"b0_count":{"total":3},"b1_tags":null},"errors":[{"message":"no tags","path":["b1_tags"]}]}`))
+	}))
+	defer server.Close()
+
+	var (
+		count countOutput
+		tags  tagsOutput
+		b     = graphql.NewBatch(graphql.BatchWithMode(graphql.BatchMerge))
+	)
+	countReq := graphql.NewRequest(`query Count($type: String) { count: assetCount(type: $type) { total } }`)
+	countReq.Var("type", "endpoint")
+	tagsReq := graphql.NewRequest(`query($first: Int) { tags: allTags(first: $first) { ...TagFields } } fragment TagFields on Tag { name }`)
+	tagsReq.Var("first", 2)
+	countItem := b.Add(countReq, &count)
+	tagsItem := b.Add(tagsReq, &tags)
+	require.NoError(t, b.ExecuteContext(context.Background(), client.NewClient(), server.URL))
+
+	assert.NoError(t, countItem.Err)
+	assert.Equal(t, 3, count.Count.Total)
+
+	var gqlErr graphql.Error
+	require.True(t, errors.As(tagsItem.Err, &gqlErr))
+	assert.Equal(t, []string{"tags"}, gqlErr.Path)
+	assert.Nil(t, tags.Tags)
+}
+
+func TestBatch_MergeInvalid(t *testing.T) {
+	for name, queries := range map[string][]string{
+		"mixed operations": {"query { a }", "mutation { b }"},
+		"subscription":     {"subscription { a }"},
+		"root spread":      {"query { ...F } fragment F on Query { a }"},
+		"parse error":      {"query { a"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := graphql.NewBatch(graphql.BatchWithMode(graphql.BatchMerge))
+			for _, q := range queries {
+				b.Add(graphql.NewRequest(q), nil)
+			}
+			assert.Error(t, b.ExecuteContext(context.Background(), client.NewClient(), "http://localhost"))
+		})
+	}
+}
+
+func TestBatch_ConflictingHeaders(t *testing.T) {
+	b := graphql.NewBatch()
+	b.Add(graphql.NewRequest("query { a }", graphql.RequestWithTenant("one")), nil)
+	b.Add(graphql.NewRequest("query { b }", graphql.RequestWithTenant("two")), nil)
+	assert.Error(t, b.ExecuteContext(context.Background(), client.NewClient(), "http://localhost"))
+}
+
+func TestLoader(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		body, _ := ioutil.ReadAll(r.Body)
+		var reqs []graphql.Request
+		require.NoError(t, json.Unmarshal(body, &reqs))
+		out := make([]graphql.Response, len(reqs))
+		for i, req := range reqs {
+			out[i].Data = map[string]interface{}{"count": map[string]interface{}{"total": req.Variables["n"]}}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	}))
+	defer server.Close()
+
+	loader := graphql.NewLoader(client.NewClient(), server.URL, graphql.LoaderWait(20*time.Millisecond))
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := graphql.NewRequest("query($n: Int) { count(n: $n) { total } }")
+			req.Var("n", i)
+			var out countOutput
+			assert.NoError(t, loader.Query(context.Background(), req, &out))
+			assert.Equal(t, i, out.Count.Total)
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestLoader_MaxBatch(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		var reqs []graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&reqs))
+		_ = json.NewEncoder(w).Encode(make([]graphql.Response, len(reqs)))
+	}))
+	defer server.Close()
+
+	loader := graphql.NewLoader(client.NewClient(), server.URL, graphql.LoaderWait(time.Hour), graphql.LoaderMaxBatch(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, loader.Query(context.Background(), graphql.NewRequest("query { a }"), nil))
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
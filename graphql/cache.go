@@ -0,0 +1,254 @@
+package graphql
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+)
+
+// CacheStore keeps the responses of a QueryCache. NewMemoryCache returns an in-memory store, external stores
+// shared between processes implement it too. It must be safe for concurrent use
+type CacheStore interface {
+	// Get returns the value of key, ok is false when the key is missing or expired
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	// Set stores value under key for ttl
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// DeletePrefix removes every key starting with prefix
+	DeletePrefix(ctx context.Context, prefix string) error
+}
+
+// QueryCache caches the responses of the query operations it is configured for, see CacheOperation. Mutations
+// are never cached and invalidate the operations given to CacheInvalidateOn. Concurrent requests for the same
+// missing entry are sent once, the others wait for its response. The cache key is made of the query, the variables
+// and the tenant of the operation when it reaches the cache, interceptors setting the tenant have to run before it.
+// It is used as an interceptor, see WithCache
+type QueryCache struct {
+	store         CacheStore
+	ttls          map[string]time.Duration
+	invalidations map[string][]string
+
+	mu sync.Mutex
+	//calls are the requests in flight by cache key
+	calls map[string]*cacheCall
+	//generation is incremented by the invalidations, so the requests in flight do not store stale responses
+	generation uint64
+}
+
+var errCallIncomplete = errors.New("ctpx-sdk-go/graphql: cached request did not complete")
+
+type cacheCall struct {
+	done chan struct{}
+	data []byte
+	err  error
+}
+
+// CacheOption configures a QueryCache
+type CacheOption func(*QueryCache)
+
+// CacheOperation caches the responses of the query operation, named as by client.OperationName, for ttl
+func CacheOperation(operation string, ttl time.Duration) CacheOption {
+	return func(c *QueryCache) {
+		c.ttls[operation] = ttl
+	}
+}
+
+// CacheInvalidateOn removes the cached responses of operations when the mutation operation is executed
+func CacheInvalidateOn(mutation string, operations ...string) CacheOption {
+	return func(c *QueryCache) {
+		c.invalidations[mutation] = append(c.invalidations[mutation], operations...)
+	}
+}
+
+// NewQueryCache returns a QueryCache keeping its responses in store
+func NewQueryCache(store CacheStore, opts ...CacheOption) *QueryCache {
+	c := &QueryCache{
+		store:         store,
+		ttls:          map[string]time.Duration{},
+		invalidations: map[string][]string{},
+		calls:         map[string]*cacheCall{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// WithCache caches the responses of the operations executed with the client
+func WithCache(c *QueryCache) client.Option {
+	return WithInterceptors(c.Interceptor())
+}
+
+// Interceptor returns the Interceptor serving the operations from the cache
+func (c *QueryCache) Interceptor() Interceptor {
+	return func(ctx context.Context, op *Operation, next Invoker) error {
+		ttl, ok := c.ttls[op.Name]
+		if op.Type != OperationQuery || !ok || op.Output == nil {
+			err := next(ctx, op)
+			if op.Type == OperationMutation { //even when failed, the mutation may have been applied
+				_ = c.Invalidate(ctx, c.invalidations[op.Name]...)
+			}
+			return err
+		}
+
+		key, err := cacheKey(op)
+		if err != nil {
+			return next(ctx, op)
+		}
+		if data, ok, err := c.store.Get(ctx, key); err == nil && ok && json.Unmarshal(data, op.Output) == nil {
+			return nil
+		}
+
+		c.mu.Lock()
+		if call, ok := c.calls[key]; ok {
+			c.mu.Unlock()
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+			if call.err == nil {
+				return json.Unmarshal(call.data, op.Output)
+			}
+			return next(ctx, op)
+		}
+		//the waiters send their own request unless the call completes, next may panic
+		call := &cacheCall{done: make(chan struct{}), err: errCallIncomplete}
+		c.calls[key] = call
+		generation := c.generation
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.calls, key)
+			c.mu.Unlock()
+			close(call.done)
+		}()
+
+		err = next(ctx, op)
+		if call.err = err; err == nil {
+			call.data, call.err = json.Marshal(op.Output)
+		}
+
+		c.mu.Lock()
+		current := generation == c.generation
+		c.mu.Unlock()
+		if call.err == nil && current {
+			_ = c.store.Set(ctx, key, call.data, ttl)
+		}
+		return err
+	}
+}
+
+// Invalidate removes the cached responses of operations
+func (c *QueryCache) Invalidate(ctx context.Context, operations ...string) error {
+	if len(operations) == 0 {
+		return nil
+	}
+	c.mu.Lock()
+	c.generation++
+	c.mu.Unlock()
+	for _, operation := range operations {
+		if err := c.store.DeletePrefix(ctx, operation+":"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// cacheKey is the operation name, for invalidation, and the hash of the query, variables and tenant
+func cacheKey(op *Operation) (string, error) {
+	b, err := json.Marshal(struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+		Tenant    string                 `json:"tenant"`
+	}{op.Request.Query, op.Request.Variables, op.Tenant()})
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return op.Name + ":" + hex.EncodeToString(sum[:]), nil
+}
+
+// MemoryCache is an in-memory CacheStore evicting the least recently used entries
+type MemoryCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+type memoryEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding up to size entries, 0 for no limit
+func NewMemoryCache(size int) *MemoryCache {
+	return &MemoryCache{size: size, entries: map[string]*list.Element{}, lru: list.New()}
+}
+
+// Get returns the value of key, unless expired
+func (m *MemoryCache) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := e.Value.(*memoryEntry)
+	if time.Now().After(entry.expires) {
+		m.remove(e)
+		return nil, false, nil
+	}
+	m.lru.MoveToFront(e)
+	return entry.value, true, nil
+}
+
+// Set stores value under key for ttl, evicting the least recently used entry when full
+func (m *MemoryCache) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := &memoryEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if e, ok := m.entries[key]; ok {
+		e.Value = entry
+		m.lru.MoveToFront(e)
+		return nil
+	}
+	m.entries[key] = m.lru.PushFront(entry)
+	if m.size > 0 && m.lru.Len() > m.size {
+		m.remove(m.lru.Back())
+	}
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix
+func (m *MemoryCache) DeletePrefix(_ context.Context, prefix string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, e := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			m.remove(e)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, expired ones included
+func (m *MemoryCache) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.lru.Len()
+}
+
+func (m *MemoryCache) remove(e *list.Element) {
+	m.lru.Remove(e)
+	delete(m.entries, e.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,196 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	m := graphql.NewMemoryCache(2)
+	require.NoError(t, m.Set(ctx, "a:1", []byte("1"), time.Minute))
+	require.NoError(t, m.Set(ctx, "a:2", []byte("2"), time.Minute))
+	_, ok, _ := m.Get(ctx, "a:1")
+	assert.True(t, ok)
+
+	//a:2 is the least recently used
+	require.NoError(t, m.Set(ctx, "b:1", []byte("3"), time.Minute))
+	_, ok, _ = m.Get(ctx, "a:2")
+	assert.False(t, ok)
+	assert.Equal(t, 2, m.Len())
+
+	require.NoError(t, m.DeletePrefix(ctx, "a:"))
+	_, ok, _ = m.Get(ctx, "a:1")
+	assert.False(t, ok)
+	v, ok, _ := m.Get(ctx, "b:1")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("3"), v)
+
+	require.NoError(t, m.Set(ctx, "c:1", []byte("4"), -time.Second))
+	_, ok, _ = m.Get(ctx, "c:1")
+	assert.False(t, ok)
+}
+
+// countingServer answers every query with its number of requests, after delay
+func countingServer(delay time.Duration) (*httptest.Server, *int32) {
+	var n int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count := atomic.AddInt32(&n, 1)
+		time.Sleep(delay)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"count": count}})
+	}))
+	return srv, &n
+}
+
+func TestQueryCache(t *testing.T) {
+	srv, n := countingServer(0)
+	defer srv.Close()
+	cache := graphql.NewQueryCache(graphql.NewMemoryCache(0),
+		graphql.CacheOperation("regions", time.Minute),
+		graphql.CacheInvalidateOn("addRegion", "regions"),
+	)
+	c := client.NewClient(graphql.WithCache(cache))
+
+	query := func(q string, opts ...graphql.RequestOption) int32 {
+		var out struct {
+			Count int32 `json:"count"`
+		}
+		require.NoError(t, graphql.ExecuteQuery(c, srv.URL, graphql.NewRequest(q, opts...), &out))
+		return out.Count
+	}
+	tenant := graphql.RequestWithTenant
+
+	assert.Equal(t, int32(1), query("query regions { regions }", tenant("1")))
+	assert.Equal(t, int32(1), query("query regions { regions }", tenant("1")))
+	//the tenant is part of the key
+	assert.Equal(t, int32(2), query("query regions { regions }", tenant("2")))
+	//operations not configured are not cached
+	assert.Equal(t, int32(3), query("query charts { charts }"))
+	assert.Equal(t, int32(4), query("query charts { charts }"))
+	//mutations invalidate the related operations
+	assert.Equal(t, int32(5), query("mutation addRegion { addRegion }"))
+	assert.Equal(t, int32(6), query("query regions { regions }", tenant("1")))
+	assert.Equal(t, int32(6), query("query regions { regions }", tenant("1")))
+	assert.Equal(t, int32(6), atomic.LoadInt32(n))
+}
+
+type tenantKey struct{}
+
+func TestQueryCache_InterceptedTenant(t *testing.T) {
+	srv, n := countingServer(0)
+	defer srv.Close()
+	cache := graphql.NewQueryCache(graphql.NewMemoryCache(0), graphql.CacheOperation("regions", time.Minute))
+	//the tenant of the context is set on the request before the cache builds its key
+	withTenant := func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+		if tenant, ok := ctx.Value(tenantKey{}).(string); ok {
+			graphql.RequestWithTenant(tenant)(op.Request)
+		}
+		return next(ctx, op)
+	}
+	c := client.NewClient(graphql.WithInterceptors(withTenant), graphql.WithCache(cache))
+
+	query := func(tenant string) int32 {
+		var out struct {
+			Count int32 `json:"count"`
+		}
+		ctx := context.WithValue(context.Background(), tenantKey{}, tenant)
+		require.NoError(t, graphql.ExecuteQueryContext(ctx, &graphql.QueryConfig{
+			ServerURL: srv.URL,
+			HClient:   c,
+			Request:   graphql.NewRequest("query regions { regions }"),
+			Output:    &out,
+		}))
+		return out.Count
+	}
+
+	assert.Equal(t, int32(1), query("1"))
+	assert.Equal(t, int32(1), query("1"))
+	assert.Equal(t, int32(2), query("2"))
+	assert.Equal(t, int32(2), query("2"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(n))
+}
+
+func TestQueryCache_Stampede(t *testing.T) {
+	srv, n := countingServer(100 * time.Millisecond)
+	defer srv.Close()
+	cache := graphql.NewQueryCache(graphql.NewMemoryCache(10), graphql.CacheOperation("regions", time.Minute))
+	c := client.NewClient(graphql.WithCache(cache))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var out struct {
+				Count int32 `json:"count"`
+			}
+			req := graphql.NewRequest("query regions { regions }")
+			req.Var("page", 1)
+			assert.NoError(t, graphql.ExecuteQuery(c, srv.URL, req, &out))
+			assert.Equal(t, int32(1), out.Count)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(n))
+}
+
+func TestQueryCache_PanickedRequest(t *testing.T) {
+	srv, n := countingServer(0)
+	defer srv.Close()
+	cache := graphql.NewQueryCache(graphql.NewMemoryCache(0), graphql.CacheOperation("regions", time.Minute))
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls int32
+	panicking := func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			close(started)
+			<-release
+			panic("request failed")
+		}
+		return next(ctx, op)
+	}
+	c := client.NewClient(graphql.WithCache(cache), graphql.WithInterceptors(panicking))
+
+	query := func() (int32, error) {
+		var out struct {
+			Count int32 `json:"count"`
+		}
+		err := graphql.ExecuteQuery(c, srv.URL, graphql.NewRequest("query regions { regions }"), &out)
+		return out.Count, err
+	}
+
+	go func() {
+		defer func() { _ = recover() }()
+		_, _ = query()
+	}()
+	<-started
+
+	//the waiter of the panicked request sends its own
+	done := make(chan int32)
+	go func() {
+		count, err := query()
+		assert.NoError(t, err)
+		done <- count
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+
+	select {
+	case count := <-done:
+		assert.Equal(t, int32(1), count)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "waiter blocked")
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(n))
+}
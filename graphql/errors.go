@@ -0,0 +1,100 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/secureworks/taegis-sdk-go/internal/sentinel"
+)
+
+// Sentinel errors matched with errors.Is by HTTPError, by Error through its extensions code
+// and by the errors of every service package
+var (
+	ErrValidation   = errors.New("ctpx-sdk-go/graphql: invalid request")
+	ErrUnauthorized = errors.New("ctpx-sdk-go/graphql: unauthorized")
+	ErrForbidden    = errors.New("ctpx-sdk-go/graphql: forbidden")
+	ErrNotFound     = errors.New("ctpx-sdk-go/graphql: not found")
+	ErrServer       = errors.New("ctpx-sdk-go/graphql: server error")
+	// ErrRateLimited also matches the client side rate limiting errors of client.WithRateLimit
+	ErrRateLimited = sentinel.ErrRateLimited
+	// ErrReconnected is returned by the Next method of service subscriptions after a ReconnectedMessage,
+	// messages published while the subscription was down are lost
+	ErrReconnected = errors.New("ctpx-sdk-go/graphql: subscription reconnected, messages may have been missed")
+	// ErrKeepAliveTimeout is the error of the last message of a subscription whose server went silent,
+	// see SubscriptionWithKeepAliveTimeout
+	ErrKeepAliveTimeout = errors.New("ctpx-sdk-go/graphql: subscription keepalive timed out")
+)
+
+// Error codes found in the extensions of graphql errors
+const (
+	CodeUnauthenticated  = "UNAUTHENTICATED"
+	CodeForbidden        = "FORBIDDEN"
+	CodeNotFound         = "NOT_FOUND"
+	CodeBadUserInput     = "BAD_USER_INPUT"
+	CodeValidationFailed = "GRAPHQL_VALIDATION_FAILED"
+	CodeParseFailed      = "GRAPHQL_PARSE_FAILED"
+	CodeRateLimited      = "RATE_LIMITED"
+	CodeInternal         = "INTERNAL_SERVER_ERROR"
+)
+
+var codeSentinels = map[string]error{
+	CodeUnauthenticated:  ErrUnauthorized,
+	CodeForbidden:        ErrForbidden,
+	CodeNotFound:         ErrNotFound,
+	CodeBadUserInput:     ErrValidation,
+	CodeValidationFailed: ErrValidation,
+	CodeParseFailed:      ErrValidation,
+	CodeRateLimited:      ErrRateLimited,
+	CodeInternal:         ErrServer,
+}
+
+// StatusSentinel returns the sentinel error of an HTTP status code, nil for codes without one
+func StatusSentinel(code int) error {
+	switch {
+	case code == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case code == http.StatusForbidden:
+		return ErrForbidden
+	case code == http.StatusNotFound:
+		return ErrNotFound
+	case code == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case code >= http.StatusInternalServerError:
+		return ErrServer
+	case code >= http.StatusBadRequest:
+		return ErrValidation
+	default:
+		return nil
+	}
+}
+
+// HTTPError is returned when the server responds with a status of 400 or more
+type HTTPError struct {
+	StatusCode int
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("server responded with an error: %d", e.StatusCode)
+}
+
+// Is matches the StatusSentinel of the status code
+func (e *HTTPError) Is(target error) bool {
+	s := StatusSentinel(e.StatusCode)
+	return s != nil && s == target
+}
+
+// TransportError is returned when the request could not be sent or its response could not be read
+type TransportError struct {
+	// Op describes what failed
+	Op  string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,77 @@
+package graphql_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+func TestErrors_ExtensionsAndPath(t *testing.T) {
+	body := []byte(`{"data":null,"errors":[{"message":"asset not found","path":["assets",3,"name"],"extensions":{"code":"NOT_FOUND","id":"a1"}}]}`)
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, body)
+	defer server.Close()
+
+	err := graphql.ExecuteQuery(client.NewClient(), server.URL, graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader())), nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, graphql.ErrNotFound))
+	assert.False(t, errors.Is(err, graphql.ErrForbidden))
+
+	var gqlErr graphql.Error
+	require.True(t, errors.As(err, &gqlErr))
+	assert.Equal(t, []string{"assets", "3", "name"}, gqlErr.Path)
+	assert.Equal(t, graphql.CodeNotFound, gqlErr.Code())
+	assert.Equal(t, "a1", gqlErr.Extensions["id"])
+	assert.Equal(t, "message: asset not found (path assets/3/name)", gqlErr.Error())
+}
+
+func TestErrors_HTTPStatus(t *testing.T) {
+	for status, sentinel := range map[int]error{
+		http.StatusBadRequest:         graphql.ErrValidation,
+		http.StatusUnauthorized:       graphql.ErrUnauthorized,
+		http.StatusForbidden:          graphql.ErrForbidden,
+		http.StatusNotFound:           graphql.ErrNotFound,
+		http.StatusTooManyRequests:    graphql.ErrRateLimited,
+		http.StatusServiceUnavailable: graphql.ErrServer,
+	} {
+		server := testutils.NewMockGQLServer(t, newHeader(), status, []byte(`{}`))
+
+		err := graphql.ExecuteQuery(client.NewClient(), server.URL, graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader())), nil)
+		assert.True(t, errors.Is(err, sentinel), "status %d", status)
+
+		var httpErr *graphql.HTTPError
+		if assert.True(t, errors.As(err, &httpErr)) {
+			assert.Equal(t, status, httpErr.StatusCode)
+		}
+		server.Close()
+	}
+}
+
+func TestErrors_Transport(t *testing.T) {
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, []byte(`{}`))
+	server.Close()
+
+	err := graphql.ExecuteQuery(client.NewClient(), server.URL, graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader())), nil)
+	var transportErr *graphql.TransportError
+	require.True(t, errors.As(err, &transportErr))
+	assert.Equal(t, "server connection error", transportErr.Op)
+	assert.False(t, errors.Is(err, graphql.ErrServer))
+}
+
+func TestErrors_ClientRateLimit(t *testing.T) {
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, []byte(`{}`))
+	defer server.Close()
+
+	c := client.NewClient(client.WithRateLimit(client.RateLimitSettings{Global: client.RateLimit{Rate: 0.001, Burst: 1}, FailFast: true}))
+	req := func() error {
+		return graphql.ExecuteQuery(c, server.URL, graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader())), nil)
+	}
+	require.NoError(t, req())
+	assert.True(t, errors.Is(req(), graphql.ErrRateLimited))
+}
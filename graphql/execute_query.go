@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 
+	"github.com/secureworks/taegis-sdk-go/internal/operation"
 	"github.com/secureworks/taegis-sdk-go/log"
 
 	"github.com/secureworks/taegis-sdk-go/common"
@@ -32,7 +33,13 @@ type QueryConfig struct {
 	EscapeHTML bool
 	LimitRead  int64
 	Output     interface{}
-	logger     log.Logger
+	//PersistedQueries enables Automatic Persisted Queries, the cache can be shared by every config of a service
+	PersistedQueries *PersistedQueries
+	//Schema validates the request offline before it is sent, see LoadSchema
+	Schema *Schema
+	//Interceptors wrap the execution of the request, after the interceptors of the client, see WithInterceptors
+	Interceptors []Interceptor
+	logger       log.Logger
 }
 
 func (qc *QueryConfig) isValid() bool {
@@ -43,6 +50,7 @@ func (qc *QueryConfig) isValid() bool {
 //ExecuteQueryContext takes a context for HTTP request control and the given QueryConfig.
 //It executes the graphql request against the proveded HTTPClient, returning an error or unmarshalling into QueryConfig.Output if provided.
 //HClient, ServerURL, and Request are required in QueryConfig.
+//Errors can be inspected with errors.As for *HTTPError, *TransportError and Error, or matched with errors.Is against ErrNotFound and the other sentinels.
 func ExecuteQueryContext(ctx context.Context, qc *QueryConfig) error {
 	_, err := executeQueryContext(ctx, qc, false)
 	return err
@@ -56,19 +64,122 @@ func executeQueryContext(ctx context.Context, qc *QueryConfig, enforceTenant boo
 	if ctx == nil || !qc.isValid() {
 		return "", errors.New("ctpx-sdk-go/graphql: nil ctx or config to ExecuteQueryContext")
 	}
+	interceptors := qc.interceptors()
+	if len(interceptors) == 0 {
+		return qc.execute(ctx, enforceTenant)
+	}
+
+	var (
+		tenant   string
+		executed bool
+	)
+	op := &Operation{
+		Name:    operation.Name(qc.Request.Query),
+		Type:    operation.Type(qc.Request.Query),
+		Request: qc.Request.clone(),
+		Output:  qc.Output,
+		config:  qc,
+	}
+	err := chainInterceptors(interceptors, func(ctx context.Context, op *Operation) error {
+		if op.Request == nil {
+			return errors.New("ctpx-sdk-go/graphql: nil request to ExecuteQueryContext")
+		}
+		c := *qc
+		c.Request, c.Output = op.Request, op.Output
+		var err error
+		executed = true
+		tenant, err = c.execute(ctx, enforceTenant)
+		return err
+	})(ctx, op)
+	if err != nil || executed {
+		return tenant, err
+	}
+	//short-circuited by an interceptor
+	tenant = op.Tenant()
+	if enforceTenant && tenant == "" {
+		return "", errors.New("ctpx-sdk-go/graphql: request or client must specify tenant option")
+	}
+	return tenant, nil
+}
+
+//execute sends the request of a valid config and decodes the response
+func (qc *QueryConfig) execute(ctx context.Context, enforceTenant bool) (string, error) {
+	if qc.Schema != nil {
+		if err := qc.Schema.Validate(qc.Request); err != nil {
+			return "", err
+		}
+	}
+
+	var (
+		body interface{} = qc.Request
+		apq  *persistedQuery
+	)
+	if qc.PersistedQueries != nil {
+		apq = qc.PersistedQueries.newQuery(qc.ServerURL, qc.Request)
+		if apq != nil {
+			body = apq.body()
+		}
+	}
+
+	request, err := qc.newHTTPRequest(ctx, body)
+	if err != nil {
+		return "", err
+	}
+
+	tenant := request.Header.Get(common.XTenantContextHeader)
+	if enforceTenant && tenant == "" {
+		//check if client has a tenant defined
+		tenant = qc.HClient.Header().Get(common.XTenantContextHeader)
+		if tenant == "" {
+			return "", errors.New("ctpx-sdk-go/graphql: request or client must specify tenant option")
+		}
+	}
+
+	raw, jsonBody, outErr, err := qc.do(request)
+	if err == nil && apq != nil && apq.retry(raw) {
+		if request, err = qc.newHTTPRequest(ctx, apq.body()); err != nil {
+			return "", err
+		}
+		raw, jsonBody, outErr, err = qc.do(request)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := raw.decode(qc.Output); err != nil {
+		outErr = multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "error decoding response", Err: err}))
+		return "", outErr
+	}
+	graphqlResp := Response{
+		Data:  qc.Output,
+		Error: raw.Errors,
+	}
+	outErr = raw.err(outErr, qc.Request.partialData)
 
+	if qc.logger != nil {
+		qc.logger.Debug().WithError(outErr).WithFields(map[string]interface{}{
+			"json": jsonBody,
+			"resp": graphqlResp,
+		}).Msg("graphql resp")
+	}
+
+	return tenant, outErr
+}
+
+//newHTTPRequest encodes body into a POST request with the headers of the request and config
+func (qc *QueryConfig) newHTTPRequest(ctx context.Context, body interface{}) (*http.Request, error) {
 	buf := bytes.NewBuffer(make([]byte, 0, 256))
 	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(qc.EscapeHTML)
 
-	err := enc.Encode(qc.Request)
+	err := enc.Encode(body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	request, err := http.NewRequestWithContext(ctx, http.MethodPost, qc.ServerURL, buf)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	for k := range qc.Request.Header { //RequestOption headers
@@ -80,58 +191,67 @@ func executeQueryContext(ctx context.Context, qc *QueryConfig, enforceTenant boo
 			request.Header.Add(k, qc.Header.Get(k))
 		}
 	}
+	return request, nil
+}
 
-	tenant := request.Header.Get(common.XTenantContextHeader)
-	if enforceTenant && tenant == "" {
-		//check if client has a tenant defined
-		tenant = qc.HClient.Header().Get(common.XTenantContextHeader)
-		if tenant == "" {
-			return "", errors.New("ctpx-sdk-go/graphql: request or client must specify tenant option")
-		}
-	}
-
+//do sends the request and reads the response without decoding its data. outErr holds the status error
+//and err is set when the whole response is unusable
+func (qc *QueryConfig) do(request *http.Request) (raw rawResponse, jsonBody string, outErr error, err error) {
 	resp, err := qc.HClient.Do(request)
 	if err != nil {
-		return "", fmt.Errorf("ctpx-sdk-go/graphql: server connection error: %w", err)
+		return raw, "", nil, fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "server connection error", Err: err})
 	}
 	defer resp.Body.Close()
-	var outErr error
 	if resp.StatusCode >= http.StatusBadRequest {
-		outErr = multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: server responded with an error: %d", resp.StatusCode))
-	}
-
-	graphqlResp := Response{
-		Data: qc.Output,
+		outErr = multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: %w", &HTTPError{StatusCode: resp.StatusCode}))
 	}
 
 	var (
-		r        io.Reader = resp.Body
-		jsonBody bytes.Buffer
+		r   io.Reader = resp.Body
+		buf bytes.Buffer
 	)
 
-	r = io.TeeReader(r, &jsonBody)
+	r = io.TeeReader(r, &buf)
 
 	if qc.LimitRead > 0 {
 		r = io.LimitReader(r, qc.LimitRead)
 	}
 
-	if err := json.NewDecoder(r).Decode(&graphqlResp); err != nil {
-		outErr = multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: error decoding response: %w", err))
-		return "", outErr
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return raw, "", nil, multierror.Append(outErr, fmt.Errorf("ctpx-sdk-go/graphql: %w", &TransportError{Op: "error decoding response", Err: err}))
 	}
+	return raw, buf.String(), outErr, nil
+}
 
-	for _, e := range graphqlResp.Error {
-		outErr = multierror.Append(outErr, e)
-	}
+//rawResponse is a graphql response with its data left undecoded
+type rawResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []Error         `json:"errors"`
+}
 
-	if qc.logger != nil {
-		qc.logger.Debug().WithError(outErr).WithFields(map[string]interface{}{
-			"json": jsonBody.String(),
-			"resp": graphqlResp,
-		}).Msg("graphql resp")
+//hasData reports whether the data of the response is set and not null
+func (r *rawResponse) hasData() bool {
+	return len(r.Data) > 0 && !bytes.Equal(r.Data, []byte("null"))
+}
+
+//decode unmarshals the data into out, if any
+func (r *rawResponse) decode(out interface{}) error {
+	if out == nil || !r.hasData() {
+		return nil
 	}
+	return json.Unmarshal(r.Data, out)
+}
 
-	return tenant, outErr
+//err adds the graphql errors to outErr, or returns a *PartialDataError when partialData is set,
+//outErr is nil and the response has data
+func (r *rawResponse) err(outErr error, partialData bool) error {
+	if partialData && outErr == nil && len(r.Errors) > 0 && r.hasData() {
+		return &PartialDataError{Errors: r.Errors}
+	}
+	for _, e := range r.Errors {
+		outErr = multierror.Append(outErr, e)
+	}
+	return outErr
 }
 
 //ExecuteQuery is shorthand for ExecuteQueryContext with the given args.
@@ -0,0 +1,160 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const defaultFanOutConcurrency = 10
+
+// TenantFunc is run once per tenant by FanOut, the returned value is kept as the TenantResult.Result
+type TenantFunc func(ctx context.Context, tenantID string) (interface{}, error)
+
+// TenantResult is the outcome of a TenantFunc for a single tenant
+type TenantResult struct {
+	TenantID string
+	Result   interface{}
+	Err      error
+	Duration time.Duration
+}
+
+// FanOutResults holds one TenantResult per tenant, in the order the tenants were given to FanOut
+type FanOutResults []TenantResult
+
+// Succeeded returns the results of the tenants which did not fail
+func (r FanOutResults) Succeeded() FanOutResults {
+	out := make(FanOutResults, 0, len(r))
+	for _, res := range r {
+		if res.Err == nil {
+			out = append(out, res)
+		}
+	}
+	return out
+}
+
+// Errors returns the error of every failed tenant keyed by tenant id
+func (r FanOutResults) Errors() map[string]error {
+	out := map[string]error{}
+	for _, res := range r {
+		if res.Err != nil {
+			out[res.TenantID] = res.Err
+		}
+	}
+	return out
+}
+
+// Err combines the errors of all failed tenants, it is nil when every tenant succeeded
+func (r FanOutResults) Err() error {
+	var err error
+	for _, res := range r {
+		if res.Err != nil {
+			err = multierror.Append(err, fmt.Errorf("tenant %s: %w", res.TenantID, res.Err))
+		}
+	}
+	return err
+}
+
+// FanOutOption sets optional behaviour of FanOut
+type FanOutOption func(f *fanOut)
+
+// FanOutConcurrency sets how many tenants are processed at once, defaults to 10
+func FanOutConcurrency(n int) FanOutOption {
+	return func(f *fanOut) {
+		if n > 0 {
+			f.concurrency = n
+		}
+	}
+}
+
+// FanOutProgress sets a callback called every time a tenant completes, with the number of completed tenants and the total.
+// Calls are serialised so the callback does not need to be safe for concurrent use
+func FanOutProgress(cb func(res TenantResult, done, total int)) FanOutOption {
+	return func(f *fanOut) {
+		f.progress = cb
+	}
+}
+
+type fanOut struct {
+	concurrency int
+	progress    func(res TenantResult, done, total int)
+}
+
+// FanOut runs fn for every tenant with bounded concurrency. A failing tenant does not stop the others,
+// each error is kept in its TenantResult. When ctx is done the tenants not started yet fail with the
+// context error and the results of the completed tenants are still returned
+func FanOut(ctx context.Context, tenants []string, fn TenantFunc, opts ...FanOutOption) FanOutResults {
+	f := &fanOut{concurrency: defaultFanOutConcurrency}
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	results := make(FanOutResults, len(tenants))
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		sem      = make(chan struct{}, f.concurrency)
+		complete = func(i int, res TenantResult) {
+			results[i] = res
+			mu.Lock()
+			defer mu.Unlock()
+			done++
+			if f.progress != nil {
+				f.progress(res, done, len(tenants))
+			}
+		}
+	)
+
+	for i, tenant := range tenants {
+		select {
+		case <-ctx.Done():
+			complete(i, TenantResult{TenantID: tenant, Err: ctx.Err()})
+			continue
+		case sem <- struct{}{}:
+		}
+		if err := ctx.Err(); err != nil { //cancellation wins over a free slot
+			<-sem
+			complete(i, TenantResult{TenantID: tenant, Err: err})
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, tenant string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			start := time.Now()
+			out, err := fn(ctx, tenant)
+			complete(i, TenantResult{TenantID: tenant, Result: out, Err: err, Duration: time.Since(start)})
+		}(i, tenant)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// FanOutQuery executes the request of qc once per tenant with ExecuteQueryContextWithTenant.
+// qc.Output is ignored, each response is decoded into a new value from newOutput which becomes the TenantResult.Result
+func FanOutQuery(ctx context.Context, qc *QueryConfig, tenants []string, newOutput func() interface{}, opts ...FanOutOption) FanOutResults {
+	return FanOut(ctx, tenants, func(ctx context.Context, tenantID string) (interface{}, error) {
+		if !qc.isValid() {
+			_, err := ExecuteQueryContextWithTenant(ctx, qc)
+			return nil, err
+		}
+		tqc := *qc
+		tqc.Request = qc.Request.clone()
+		tqc.Request.Header.Set(common.XTenantContextHeader, tenantID)
+		tqc.Output = newOutput()
+		if _, err := ExecuteQueryContextWithTenant(ctx, &tqc); err != nil {
+			return tqc.Output, err
+		}
+		return tqc.Output, nil
+	}, opts...)
+}
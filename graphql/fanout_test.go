@@ -0,0 +1,93 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tenantCount struct {
+	Count  int    `json:"count"`
+	Tenant string `json:"tenant"`
+}
+
+func newTenantServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant := r.Header.Get(common.XTenantContextHeader)
+		resp := graphql.Response{}
+		if tenant == "broken" {
+			resp.Error = []graphql.Error{{Message: "tenant is broken"}}
+		} else {
+			resp.Data = tenantCount{Count: len(tenant), Tenant: tenant}
+		}
+		assert.NoError(t, json.NewEncoder(w).Encode(resp))
+	}))
+}
+
+func TestFanOutQuery(t *testing.T) {
+	srv := newTenantServer(t)
+	defer srv.Close()
+
+	var progress []int
+	tenants := []string{"a", "bb", "broken", "dddd"}
+	results := graphql.FanOutQuery(context.Background(), &graphql.QueryConfig{
+		HClient:   client.NewClient(),
+		ServerURL: srv.URL,
+		Request:   graphql.NewRequest("query { count tenant }", graphql.RequestWithTenant("ignored")),
+	}, tenants, func() interface{} {
+		return &tenantCount{}
+	}, graphql.FanOutConcurrency(2), graphql.FanOutProgress(func(res graphql.TenantResult, done, total int) {
+		assert.Equal(t, len(tenants), total)
+		progress = append(progress, done)
+	}))
+
+	require.Len(t, results, len(tenants))
+	for i, res := range results {
+		assert.Equal(t, tenants[i], res.TenantID)
+	}
+	assert.Equal(t, &tenantCount{Count: 4, Tenant: "dddd"}, results[3].Result)
+	assert.Len(t, results.Succeeded(), 3)
+	assert.Contains(t, results.Errors(), "broken")
+	assert.Contains(t, results.Err().Error(), "tenant broken")
+	assert.Equal(t, []int{1, 2, 3, 4}, progress)
+}
+
+func TestFanOutConcurrencyAndCancel(t *testing.T) {
+	var running, maxRunning int32
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tenants := []string{"1", "2", "3", "4", "5", "6"}
+	results := graphql.FanOut(ctx, tenants, func(ctx context.Context, tenantID string) (interface{}, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		if tenantID == "2" {
+			cancel()
+		}
+		return tenantID, nil
+	}, graphql.FanOutConcurrency(2))
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+	assert.Equal(t, "1", results[0].Result)
+	assert.NoError(t, results[1].Err)
+	assert.True(t, errors.Is(results[5].Err, context.Canceled))
+	assert.Error(t, results.Err())
+}
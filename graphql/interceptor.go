@@ -0,0 +1,119 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/internal/operation"
+)
+
+// Operation types of Operation.Type
+const (
+	OperationQuery        = operation.Query
+	OperationMutation     = operation.Mutation
+	OperationSubscription = operation.Subscription
+)
+
+// Operation is a GraphQL operation going through the interceptors of ExecuteQueryContext
+type Operation struct {
+	// Name is the operation name, see client.OperationName
+	Name string
+	// Type is OperationQuery, OperationMutation or OperationSubscription
+	Type string
+	// Request is a copy of the request of the QueryConfig, interceptors may change it before calling next
+	Request *Request
+	// Output is the pointer the response data is decoded into
+	Output interface{}
+
+	config *QueryConfig
+}
+
+// Tenant returns the tenant the operation is executed for, from the headers of its request, of the config or of
+// the client. It reflects the changes of the interceptors run so far
+func (op *Operation) Tenant() string {
+	headers := []http.Header{op.config.Header, op.config.HClient.Header()}
+	if op.Request != nil {
+		headers = append([]http.Header{op.Request.Header}, headers...)
+	}
+	for _, h := range headers {
+		if tenant := h.Get(common.XTenantContextHeader); tenant != "" {
+			return tenant
+		}
+	}
+	return ""
+}
+
+// Invoker executes an operation, it is the rest of the interceptor chain
+type Invoker func(ctx context.Context, op *Operation) error
+
+// Interceptor wraps the execution of an operation. It may change the operation before calling next, inspect
+// the output and the error after, or short-circuit the request by returning without calling next, filling
+// op.Output itself if it has a response
+type Interceptor func(ctx context.Context, op *Operation, next Invoker) error
+
+type interceptorsKey struct{}
+
+// valuer is a client holding values, like *client.Client
+type valuer interface {
+	Value(key interface{}) interface{}
+}
+
+// WithInterceptors adds interceptors to every operation executed with the client. They run before the
+// interceptors of the QueryConfig, in the given order
+func WithInterceptors(interceptors ...Interceptor) client.Option {
+	return func(c *client.Client) {
+		existing, _ := c.Value(interceptorsKey{}).([]Interceptor)
+		client.WithValue(interceptorsKey{}, append(existing[:len(existing):len(existing)], interceptors...))(c)
+	}
+}
+
+// chainInterceptors returns an Invoker running interceptors in order, then invoker
+func chainInterceptors(interceptors []Interceptor, invoker Invoker) Invoker {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, op *Operation) error {
+			return interceptor(ctx, op, next)
+		}
+	}
+	return invoker
+}
+
+// interceptors returns the interceptors of the client, then of the config
+func (qc *QueryConfig) interceptors() []Interceptor {
+	var interceptors []Interceptor
+	if c, ok := qc.HClient.(valuer); ok {
+		interceptors, _ = c.Value(interceptorsKey{}).([]Interceptor)
+	}
+	if len(qc.Interceptors) == 0 {
+		return interceptors
+	}
+	return append(interceptors[:len(interceptors):len(interceptors)], qc.Interceptors...)
+}
+
+// MessageHandler handles a message received by a subscription
+type MessageHandler func(m *Message) error
+
+// MessageInterceptor wraps the delivery of the messages of a subscription to its Messages channel, operation being
+// the subscription operation name. It may change or inspect the message before calling next, or drop it by
+// returning without calling next. An error ends the subscription
+type MessageInterceptor func(operation string, m *Message, next MessageHandler) error
+
+// SubscriptionWithInterceptors runs interceptors, in order, on every message received by the subscription
+func SubscriptionWithInterceptors(interceptors ...MessageInterceptor) SubscriptionOption {
+	return func(s *Subscription) {
+		s.interceptors = append(s.interceptors, interceptors...)
+	}
+}
+
+// chainMessageInterceptors returns a MessageHandler running the interceptors of s in order, then handler
+func (s *Subscription) chainMessageInterceptors(handler MessageHandler) MessageHandler {
+	for i := len(s.interceptors) - 1; i >= 0; i-- {
+		interceptor, next := s.interceptors[i], handler
+		handler = func(m *Message) error {
+			return interceptor(s.operation, m, next)
+		}
+	}
+	return handler
+}
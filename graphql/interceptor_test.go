@@ -0,0 +1,160 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+func TestExecuteQueryContext_Interceptors(t *testing.T) {
+	var vars []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req graphql.Request
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		vars = append(vars, req.Variables)
+		_, _ = w.Write([]byte(`{"data":{"value":"server"}}`))
+	}))
+	defer srv.Close()
+
+	var calls []string
+	record := func(name string) graphql.Interceptor {
+		return func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+			calls = append(calls, name+" "+op.Type+" "+op.Name)
+			return next(ctx, op)
+		}
+	}
+	c := client.NewClient(graphql.WithInterceptors(record("client")), graphql.WithInterceptors(record("client2")))
+
+	var out struct {
+		Value string `json:"value"`
+	}
+	req := graphql.NewRequest("# comment\nmutation setValue($id: ID!) { setValue(id: $id) }")
+	req.Var("id", "1")
+	err := graphql.ExecuteQueryContext(context.Background(), &graphql.QueryConfig{
+		ServerURL: srv.URL,
+		HClient:   c,
+		Request:   req,
+		Output:    &out,
+		Interceptors: []graphql.Interceptor{
+			record("config"),
+			func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+				op.Request.Var("id", "2")
+				err := next(ctx, op)
+				assert.Equal(t, "server", op.Output.(*struct {
+					Value string `json:"value"`
+				}).Value)
+				return err
+			},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "server", out.Value)
+	assert.Equal(t, []string{"client mutation setValue", "client2 mutation setValue", "config mutation setValue"}, calls)
+	assert.Equal(t, []map[string]interface{}{{"id": "2"}}, vars)
+	//the request of the config is left as is
+	assert.Equal(t, "1", req.Variables["id"])
+}
+
+func TestExecuteQueryContext_InterceptorShortCircuit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Fail(t, "request sent")
+	}))
+	defer srv.Close()
+
+	var out string
+	qc := &graphql.QueryConfig{
+		ServerURL: srv.URL,
+		HClient:   client.NewClient(),
+		Request:   graphql.NewRequest("{ value }"),
+		Output:    &out,
+		Interceptors: []graphql.Interceptor{func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+			assert.Equal(t, graphql.OperationQuery, op.Type)
+			*op.Output.(*string) = "cached"
+			return nil
+		}},
+	}
+	require.NoError(t, graphql.ExecuteQueryContext(context.Background(), qc))
+	assert.Equal(t, "cached", out)
+
+	//the tenant is still required, and returned, without a request
+	_, err := graphql.ExecuteQueryContextWithTenant(context.Background(), qc)
+	assert.Error(t, err)
+	qc.Request = graphql.NewRequest("{ value }", graphql.RequestWithTenant("1"))
+	tenant, err := graphql.ExecuteQueryContextWithTenant(context.Background(), qc)
+	require.NoError(t, err)
+	assert.Equal(t, "1", tenant)
+
+	errDenied := errors.New("denied")
+	qc.Interceptors = []graphql.Interceptor{func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+		return errDenied
+	}}
+	assert.Equal(t, errDenied, graphql.ExecuteQueryContext(context.Background(), qc))
+}
+
+func TestExecuteQueryContext_InterceptorOperationType(t *testing.T) {
+	var types []string
+	qc := &graphql.QueryConfig{
+		ServerURL: "http://localhost",
+		HClient:   client.NewClient(),
+		Interceptors: []graphql.Interceptor{func(ctx context.Context, op *graphql.Operation, next graphql.Invoker) error {
+			types = append(types, op.Type)
+			return nil
+		}},
+	}
+	for _, query := range []string{
+		"fragment assetFields on Asset { id }\nmutation { updateAsset(id: \"1\") { ...assetFields } }",
+		"# comment\nquery assets { assets { id } }",
+		"{ assets { id } }",
+	} {
+		qc.Request = graphql.NewRequest(query)
+		require.NoError(t, graphql.ExecuteQueryContext(context.Background(), qc))
+	}
+	assert.Equal(t, []string{graphql.OperationMutation, graphql.OperationQuery, graphql.OperationQuery}, types)
+}
+
+func TestSubscriptionWithInterceptors(t *testing.T) {
+	q := "subscription test"
+	vars := map[string]interface{}{"test": "test"}
+	s := graphql.NewMockSubServer(t, q, vars, 1, 2, 3, 4)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithVars(vars), graphql.SubscriptionWithInterceptors(
+		func(operation string, m *graphql.Message, next graphql.MessageHandler) error {
+			assert.Equal(t, "test", operation)
+			if n, ok := m.Payload.(*int); ok && *n%2 == 0 {
+				return nil
+			}
+			return next(m)
+		},
+		func(operation string, m *graphql.Message, next graphql.MessageHandler) error {
+			if n, ok := m.Payload.(*int); ok {
+				*n *= 10
+			}
+			return next(m)
+		},
+	))
+	require.NoError(t, err)
+	defer func() { _ = sub.Shutdown(context.TODO()) }()
+
+	assert.Equal(t, []int{10, 30}, receiveInts(t, sub, 2))
+}
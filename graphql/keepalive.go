@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// SubscriptionWithKeepAliveTimeout treats the connection as dead when nothing is read from the websocket for timeout,
+// catching the half-open connections which would otherwise block Messages forever. Websocket pings are sent every
+// half timeout so an idle but healthy server keeps the connection alive with its pongs.
+// A dead connection is redialed with SubscriptionWithReconnect, otherwise the subscription ends with ErrKeepAliveTimeout
+func SubscriptionWithKeepAliveTimeout(timeout time.Duration) SubscriptionOption {
+	return func(s *Subscription) {
+		s.kaTimeout = timeout
+	}
+}
+
+// watch enforces the keepalive timeout on conn until the returned func is called
+func (s *Subscription) watch(conn *websocket.Conn) (stop func()) {
+	if s.kaTimeout <= 0 {
+		return func() {}
+	}
+	s.extendDeadline(conn)
+	conn.SetPongHandler(func(string) error {
+		s.extendDeadline(conn)
+		return nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(s.kaTimeout / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(s.kaTimeout)); err != nil {
+					s.log.WithError(err).Debug().Msg("failed sending ping")
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// extendDeadline pushes the read deadline of conn by the keepalive timeout, called on every message and pong
+func (s *Subscription) extendDeadline(conn *websocket.Conn) {
+	if s.kaTimeout <= 0 {
+		return
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(s.kaTimeout)); err != nil {
+		s.log.WithError(err).Debug().Msg("failed setting read deadline")
+	}
+}
+
+// isKeepAliveTimeout reports whether err is a read which exceeded the keepalive deadline
+func (s *Subscription) isKeepAliveTimeout(err error) bool {
+	if s.kaTimeout <= 0 || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
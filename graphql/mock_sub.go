@@ -5,32 +5,79 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/gorilla/websocket"
 
 	"github.com/stretchr/testify/require"
 )
 
+// mockAckDelay is how long the mock server waits before acknowledging connection_init, failing the test when
+// the client sends anything meanwhile
+const mockAckDelay = 20 * time.Millisecond
+
+// NewMockSubServer serves a subscription which checks the query and variables, then sends the outputs,
+// errors are sent as graphql errors. It speaks ProtocolGraphQLTransportWS when the client requests it
+// and ProtocolGraphQLWS otherwise
 func NewMockSubServer(t *testing.T, expectedQuery string, expectedVars map[string]interface{}, outputs ...interface{}) *httptest.Server {
 	var wsUpgrader = websocket.Upgrader{
 		ReadBufferSize:  1024,
 		WriteBufferSize: 1024,
+		Subprotocols:    []string{string(ProtocolGraphQLTransportWS), string(ProtocolGraphQLWS)},
 	}
 
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		conn, err := wsUpgrader.Upgrade(w, r, nil)
 		require.NoError(t, err)
 
-		msg := &operationMessage{}
-		err = conn.ReadJSON(msg)
-		require.NoError(t, err)
+		transportWS := conn.Subprotocol() == string(ProtocolGraphQLTransportWS)
+		keepAlive, subscribe, data := connectionKaMsg, startMsg, dataMsg
+		if transportWS {
+			keepAlive, subscribe, data = pingMsg, subscribeMsg, nextMsg
+		}
+
+		msgs, done := make(chan *operationMessage), make(chan struct{})
+		defer close(done)
+		go func() {
+			for {
+				msg := &operationMessage{}
+				if err := conn.ReadJSON(msg); err != nil {
+					close(msgs)
+					return
+				}
+				select {
+				case msgs <- msg:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		msg, ok := <-msgs
+		require.True(t, ok)
+		require.Equal(t, connectionInitMsg, msg.Type)
+		//nothing may be sent before the ack, graphql-transport-ws servers close the connection with 4401
+		select {
+		case msg, ok := <-msgs:
+			if ok {
+				t.Errorf("got %s message before connection_ack", msg.Type)
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4401, "Unauthorized"))
+			}
+			return
+		case <-time.After(mockAckDelay):
+		}
 		err = conn.WriteJSON(&operationMessage{Type: connectionAckMsg})
 		require.NoError(t, err)
-		err = conn.WriteJSON(&operationMessage{Type: connectionKaMsg})
+		err = conn.WriteJSON(&operationMessage{Type: keepAlive})
 		require.NoError(t, err)
 
-		err = conn.ReadJSON(msg)
-		require.NoError(t, err)
+		for msg.Type != subscribe {
+			msg, ok = <-msgs
+			require.True(t, ok)
+			if msg.Type != pongMsg {
+				require.Equal(t, subscribe, msg.Type)
+			}
+		}
 		req := &Request{}
 		err = json.Unmarshal(msg.Payload, req)
 		require.NoError(t, err)
@@ -38,7 +85,7 @@ func NewMockSubServer(t *testing.T, expectedQuery string, expectedVars map[strin
 		require.Equal(t, expectedVars, req.Variables)
 
 		resp := &Response{}
-		result := &operationMessage{Type: dataMsg}
+		result := &operationMessage{Type: data, ID: msg.ID}
 
 		for _, output := range outputs {
 			switch v := output.(type) {
@@ -0,0 +1,343 @@
+package graphql
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+const stopMsg = "stop" // Client -> Server, ends an operation of the graphql-ws protocol
+
+// Multiplexer runs many subscriptions over shared websockets, pass it to NewSubscription and the service
+// subscription methods with SubscriptionWithMultiplexer. Subscriptions share a websocket when their url,
+// headers and protocol are the same, each one is an operation with its own id. Shutting down a subscription
+// stops its operation, the websocket is closed when its last operation ends.
+// The connection options of the first subscription of a websocket, such as the token source and the keepalive timeout,
+// are used for it.
+// SubscriptionWithReconnect does not apply to multiplexed subscriptions, they all end when their websocket drops
+type Multiplexer struct {
+	mu    sync.Mutex
+	conns map[string]*muxConn
+}
+
+// NewMultiplexer returns a Multiplexer without any open websocket
+func NewMultiplexer() *Multiplexer {
+	return &Multiplexer{conns: map[string]*muxConn{}}
+}
+
+// SubscriptionWithMultiplexer runs the subscription over a websocket shared through m
+func SubscriptionWithMultiplexer(m *Multiplexer) SubscriptionOption {
+	return func(s *Subscription) {
+		s.multiplexer = m
+	}
+}
+
+// Connections returns the number of open websockets
+func (m *Multiplexer) Connections() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.conns)
+}
+
+// muxConn is a websocket shared by the operations of many subscriptions
+type muxConn struct {
+	m        *Multiplexer
+	key      string
+	conn     *websocket.Conn
+	protocol SubscriptionProtocol
+	// first is the subscription which dialed the websocket, its logger and write lock are used
+	first *Subscription
+	// ready is closed once the websocket is acknowledged by the server, or err is set
+	ready chan struct{}
+	err   error
+
+	mu     sync.Mutex
+	subs   map[string]*Subscription
+	nextID int
+	closed bool
+}
+
+// muxKey identifies the websocket of s. The expiry of the cookies is left out, SubscriptionWithTenant and
+// SubscriptionWithToken set it from the current time
+func muxKey(s *Subscription) string {
+	header := s.header.Clone()
+	for i, cookie := range header["Cookie"] {
+		parts := strings.Split(cookie, "; ")
+		kept := parts[:0]
+		for _, part := range parts {
+			if !strings.HasPrefix(part, "Expires=") {
+				kept = append(kept, part)
+			}
+		}
+		header["Cookie"][i] = strings.Join(kept, "; ")
+	}
+	key := s.u.String() + "\n" + string(s.protocol) + "\n" + headerKey(header)
+	if s.negotiate {
+		key += "negotiate"
+	}
+	return key
+}
+
+// subscribe starts the operation of s on a shared websocket, dialing it if needed
+func (m *Multiplexer) subscribe(ctx context.Context, s *Subscription) (*Subscription, error) {
+	key := muxKey(s)
+	if err := s.initMessages(); err != nil {
+		return nil, err
+	}
+
+	var mc *muxConn
+	for mc == nil {
+		var err error
+		if mc, err = m.conn(ctx, key, s); err != nil {
+			s.closeMessages()
+			return nil, err
+		}
+		mc.mu.Lock()
+		if mc.closed { //its last operation ended meanwhile, dial another one
+			mc.mu.Unlock()
+			mc = nil
+			continue
+		}
+		mc.nextID++
+		s.id = strconv.Itoa(mc.nextID)
+		s.mux = mc
+		mc.subs[s.id] = s
+		mc.mu.Unlock()
+	}
+
+	s.metrics.SubscriptionStarted(s.operation)
+	msg, err := s.startMessage(mc.protocol, s.id)
+	if err == nil {
+		err = mc.first.writeJSON(mc.conn, msg)
+	}
+	if err != nil {
+		mc.end(s, false)
+		s.log.WithError(err).WithFields(map[string]interface{}{
+			"url":   s.u.String(),
+			"query": s.query,
+			"vars":  s.vars,
+		}).Error().Msg("failed connecting to sub")
+		return nil, err
+	}
+	return s, nil
+}
+
+// conn returns the websocket of key, dialing it with s when missing. The dial runs outside m.mu, the other
+// subscriptions of key wait for it and for the ack of the server
+func (m *Multiplexer) conn(ctx context.Context, key string, s *Subscription) (*muxConn, error) {
+	m.mu.Lock()
+	mc, ok := m.conns[key]
+	if !ok {
+		mc = &muxConn{m: m, key: key, first: s, subs: map[string]*Subscription{}, ready: make(chan struct{})}
+		m.conns[key] = mc
+	}
+	m.mu.Unlock()
+
+	if !ok {
+		if mc.err = mc.dial(ctx); mc.err != nil {
+			m.mu.Lock()
+			if m.conns[key] == mc {
+				delete(m.conns, key)
+			}
+			m.mu.Unlock()
+		} else {
+			go mc.read()
+		}
+		close(mc.ready)
+	}
+
+	select {
+	case <-mc.ready:
+		return mc, mc.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// dial opens the websocket and waits for the ack of connection_init, no operation may be sent before
+func (mc *muxConn) dial(ctx context.Context) error {
+	conn, err := mc.first.dial(ctx)
+	if err != nil {
+		return err
+	}
+	if err = mc.first.writeJSON(conn, operationMessage{Type: connectionInitMsg}); err == nil {
+		err = mc.first.readAck(ctx, conn)
+	}
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	mc.conn, mc.protocol = conn, mc.first.protocolOf(conn)
+	return nil
+}
+
+// end removes s from the websocket and closes its channel, the stop message is sent when stop is set.
+// The websocket is closed when s was its last operation
+func (mc *muxConn) end(s *Subscription, stop bool) {
+	s.closeOnce.Do(func() { close(s.closed) }) //unblocks a send of SubscriptionBlockWhenFull holding mc.mu
+	mc.m.mu.Lock()
+	mc.mu.Lock()
+	if _, ok := mc.subs[s.id]; !ok {
+		mc.mu.Unlock()
+		mc.m.mu.Unlock()
+		return
+	}
+	delete(mc.subs, s.id)
+	s.closeMessages()
+	last := len(mc.subs) == 0 && !mc.closed
+	if last {
+		mc.closed = true
+		if mc.m.conns[mc.key] == mc {
+			delete(mc.m.conns, mc.key)
+		}
+	}
+	mc.mu.Unlock()
+	mc.m.mu.Unlock()
+	s.metrics.SubscriptionEnded(s.operation)
+
+	if stop {
+		msgType := stopMsg
+		if mc.protocol == ProtocolGraphQLTransportWS {
+			msgType = completedMsg
+		}
+		if err := mc.first.writeJSON(mc.conn, operationMessage{Type: msgType, ID: s.id}); err != nil {
+			s.log.WithError(err).Warn().Msg("failed sending stop message")
+		}
+	}
+	if last {
+		mc.first.log.Debug().Msg("last multiplexed sub ended, closing websocket")
+		err := mc.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(disconnectionDeadline))
+		if err != nil {
+			mc.first.log.WithError(err).Warn().Msg("failed sending close message")
+		}
+		_ = mc.conn.Close()
+	}
+}
+
+// read routes the messages of the websocket to the subscriptions by operation id
+func (mc *muxConn) read() {
+	log := mc.first.log
+	log.Debug().Msg("started multiplexed reader goroutine")
+	defer mc.first.watch(mc.conn)()
+	for {
+		var op operationMessage
+		if err := mc.conn.ReadJSON(&op); err != nil {
+			mc.endAll(err)
+			return
+		}
+		mc.first.extendDeadline(mc.conn)
+		log.WithFields(map[string]interface{}{
+			"payload": string(op.Payload),
+			"op":      op.Type,
+			"id":      op.ID,
+		}).Debug().Msg("multiplexed subscription got message")
+
+		switch op.Type {
+		case connectionKaMsg, pingMsg:
+			if op.Type == pingMsg {
+				if err := mc.first.writeJSON(mc.conn, operationMessage{Type: pongMsg}); err != nil {
+					mc.endAll(errors.Wrap(err, "failed sending pong"))
+					return
+				}
+			}
+			for _, s := range mc.subscriptions() {
+				if s.sendKAMsgs {
+					mc.inform(s, &Message{Payload: KeepAliveMessage{}})
+				}
+			}
+		case pongMsg:
+		case dataMsg, nextMsg:
+			s := mc.subscription(op.ID)
+			if s == nil {
+				continue
+			}
+			msg, err := s.dataMessage(op.Payload)
+			if err != nil {
+				s.log.WithError(err).Warn().Msg("multiplexed sub ended")
+				mc.end(s, true)
+				continue
+			}
+			mc.inform(s, msg)
+		case errorMsg:
+			if s := mc.subscription(op.ID); s != nil {
+				mc.inform(s, &Message{Err: operationError(mc.protocol, op.Payload)})
+				mc.end(s, false)
+			}
+		case completedMsg:
+			if s := mc.subscription(op.ID); s != nil {
+				mc.end(s, false)
+			}
+		default:
+			log.Warn().WithFields(map[string]interface{}{"op": op.Type}).Msg("unexpected message type")
+		}
+	}
+}
+
+// inform sends msg to s, ending s when its buffer is full like a single subscription
+func (mc *muxConn) inform(s *Subscription, msg *Message) {
+	mc.mu.Lock()
+	if _, ok := mc.subs[s.id]; !ok {
+		mc.mu.Unlock()
+		return
+	}
+	err := s.informMessageReceived(msg)
+	mc.mu.Unlock()
+	if err != nil {
+		s.log.WithError(err).Warn().Msg("multiplexed sub ended")
+		mc.end(s, true)
+	}
+}
+
+func (mc *muxConn) subscription(id string) *Subscription {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	return mc.subs[id]
+}
+
+func (mc *muxConn) subscriptions() []*Subscription {
+	mc.mu.Lock()
+	defer mc.mu.Unlock()
+	subs := make([]*Subscription, 0, len(mc.subs))
+	for _, s := range mc.subs {
+		subs = append(subs, s)
+	}
+	return subs
+}
+
+// endAll ends every subscription when the websocket fails
+func (mc *muxConn) endAll(err error) {
+	mc.mu.Lock()
+	closed := mc.closed
+	mc.closed = true //no operation may be added anymore
+	mc.mu.Unlock()
+	if !closed {
+		mc.first.log.WithError(err).Warn().Msg("multiplexed reader ended")
+	}
+	for _, s := range mc.subscriptions() {
+		if mc.first.isKeepAliveTimeout(err) {
+			mc.inform(s, &Message{Err: ErrKeepAliveTimeout})
+		}
+		mc.end(s, false)
+	}
+	mc.m.mu.Lock()
+	if mc.m.conns[mc.key] == mc {
+		delete(mc.m.conns, mc.key)
+	}
+	mc.m.mu.Unlock()
+	_ = mc.conn.Close()
+}
+
+// shutdown stops the operation of s
+func (mc *muxConn) shutdown(s *Subscription) error {
+	if mc.subscription(s.id) == nil {
+		return errors.New("subscription is already down")
+	}
+	s.log.Debug().Msg("multiplexed sub close called")
+	mc.end(s, true)
+	return nil
+}
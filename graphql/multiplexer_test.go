@@ -0,0 +1,231 @@
+package graphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+// newMuxServer answers every started operation with its id as data and reports the stopped operations
+// and closed websockets
+func newMuxServer(t *testing.T) (s *httptest.Server, dials *int32, stopped chan string, closed chan struct{}) {
+	dials = new(int32)
+	stopped = make(chan string, 10)
+	closed = make(chan struct{}, 10)
+	upgrader := websocket.Upgrader{Subprotocols: []string{string(graphql.ProtocolGraphQLTransportWS), string(graphql.ProtocolGraphQLWS)}}
+	s = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(dials, 1)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		transportWS := conn.Subprotocol() == string(graphql.ProtocolGraphQLTransportWS)
+		//the ack is delayed, operations started before it fail the test
+		var (
+			mu    sync.Mutex
+			acked bool
+		)
+		for {
+			var msg struct {
+				ID   string `json:"id"`
+				Type string `json:"type"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				closed <- struct{}{}
+				return
+			}
+			switch msg.Type {
+			case "connection_init":
+				time.AfterFunc(20*time.Millisecond, func() {
+					mu.Lock()
+					defer mu.Unlock()
+					acked = conn.WriteJSON(map[string]string{"type": "connection_ack"}) == nil
+				})
+			case "start", "subscribe":
+				mu.Lock()
+				if !acked {
+					mu.Unlock()
+					t.Errorf("got %s message before connection_ack", msg.Type)
+					_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4401, "Unauthorized"))
+					return
+				}
+				assert.Equal(t, transportWS, msg.Type == "subscribe")
+				id, _ := strconv.Atoi(msg.ID)
+				dataType := "data"
+				if transportWS {
+					dataType = "next"
+				}
+				require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": dataType, "id": msg.ID, "payload": map[string]interface{}{"data": id}}))
+				mu.Unlock()
+			case "stop", "complete":
+				assert.Equal(t, transportWS, msg.Type == "complete")
+				stopped <- msg.ID
+			}
+		}
+	}))
+	return s, dials, stopped, closed
+}
+
+func TestMultiplexer(t *testing.T) {
+	for name, opts := range subscriptionProtocols {
+		t.Run(name, func(t *testing.T) {
+			s, dials, stopped, closed := newMuxServer(t)
+			defer s.Close()
+
+			u, err := url.Parse(s.URL)
+			require.NoError(t, err)
+			u.Scheme = "ws"
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			mux := graphql.NewMultiplexer()
+			subscribe := func() *graphql.Subscription {
+				sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+					return new(int)
+				}, append(opts, graphql.SubscriptionWithMultiplexer(mux), graphql.SubscriptionWithTenant("tenant"))...)
+				require.NoError(t, err)
+				return sub
+			}
+			first, second := subscribe(), subscribe()
+
+			for i, sub := range []*graphql.Subscription{first, second} {
+				m := <-sub.Messages()
+				require.NoError(t, m.Err)
+				assert.Equal(t, i+1, *m.Payload.(*int))
+			}
+			assert.Equal(t, int32(1), atomic.LoadInt32(dials))
+			assert.Equal(t, 1, mux.Connections())
+
+			require.NoError(t, first.Shutdown(ctx))
+			assert.Equal(t, "1", <-stopped)
+			_, ok := <-first.Messages()
+			assert.False(t, ok)
+			assert.Error(t, first.Shutdown(ctx))
+			assert.Equal(t, 1, mux.Connections())
+
+			require.NoError(t, second.Shutdown(ctx))
+			assert.Equal(t, "2", <-stopped)
+			select {
+			case <-closed:
+			case <-ctx.Done():
+				require.FailNow(t, "websocket not closed")
+			}
+			assert.Equal(t, 0, mux.Connections())
+		})
+	}
+}
+
+func TestMultiplexer_ConcurrentSubscribe(t *testing.T) {
+	s, dials, _, _ := newMuxServer(t)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	//the subscriptions started while the websocket is dialed wait for it instead of dialing their own
+	mux := graphql.NewMultiplexer()
+	subs := make([]*graphql.Subscription, 5)
+	var wg sync.WaitGroup
+	for i := range subs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+				return new(int)
+			}, graphql.SubscriptionWithMultiplexer(mux))
+			assert.NoError(t, err)
+			subs[i] = sub
+		}(i)
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(dials))
+
+	ids := map[int]bool{}
+	for _, sub := range subs {
+		require.NotNil(t, sub)
+		m := <-sub.Messages()
+		require.NoError(t, m.Err)
+		ids[*m.Payload.(*int)] = true
+		require.NoError(t, sub.Shutdown(ctx))
+	}
+	assert.Len(t, ids, len(subs))
+	assert.Equal(t, 0, mux.Connections())
+}
+
+func TestMultiplexer_SeparateHeaders(t *testing.T) {
+	s, dials, _, _ := newMuxServer(t)
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	mux := graphql.NewMultiplexer()
+	for _, tenant := range []string{"one", "two"} {
+		sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+			return new(int)
+		}, graphql.SubscriptionWithMultiplexer(mux), graphql.SubscriptionWithTenant(tenant))
+		require.NoError(t, err)
+		defer sub.Shutdown(ctx)
+		m := <-sub.Messages()
+		require.NoError(t, m.Err)
+		assert.Equal(t, 1, *m.Payload.(*int))
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(dials))
+	assert.Equal(t, 2, mux.Connections())
+}
+
+func TestMultiplexer_ConnectionLost(t *testing.T) {
+	var upgrader websocket.Upgrader
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		var msg map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "connection_ack"}))
+		require.NoError(t, conn.ReadJSON(&msg))
+		_ = conn.Close()
+	}))
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	mux := graphql.NewMultiplexer()
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithMultiplexer(mux))
+	require.NoError(t, err)
+
+	select {
+	case _, ok := <-sub.Messages():
+		assert.False(t, ok)
+	case <-ctx.Done():
+		require.FailNow(t, "channel not closed")
+	}
+	assert.Equal(t, 0, mux.Connections())
+}
@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+)
+
+// PartialDataError is returned for requests made with RequestWithPartialData when the response has data and errors.
+// The output holds the data which resolved, Errors tell which paths failed
+type PartialDataError struct {
+	Errors []Error
+}
+
+func (e *PartialDataError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("ctpx-sdk-go/graphql: partial data, %d error(s): %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is matches the sentinel errors of any of the graphql errors
+func (e *PartialDataError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if err.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+// ByPath returns the errors keyed by their path joined with "/", e.g. "assetsByIds/3"
+func (e *PartialDataError) ByPath() map[string][]Error {
+	out := map[string][]Error{}
+	for _, err := range e.Errors {
+		p := path.Join(err.Path...)
+		out[p] = append(out[p], err)
+	}
+	return out
+}
+
+// IsPartialData reports whether err is a *PartialDataError, in which case the output of the request was decoded
+func IsPartialData(err error) bool {
+	var pd *PartialDataError
+	return errors.As(err, &pd)
+}
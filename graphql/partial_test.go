@@ -0,0 +1,69 @@
+package graphql_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+const partialBody = `{"data":{"assetsByIds":[{"id":"a1"},null,{"id":"a3"}]},"errors":[{"message":"asset not found","path":["assetsByIds",1],"extensions":{"code":"NOT_FOUND"}}]}`
+
+type partialOutput struct {
+	AssetsByIds []*struct {
+		ID string `json:"id"`
+	} `json:"assetsByIds"`
+}
+
+func TestPartialData(t *testing.T) {
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, []byte(partialBody))
+	defer server.Close()
+
+	var out partialOutput
+	req := graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader()), graphql.RequestWithPartialData())
+	err := graphql.ExecuteQuery(client.NewClient(), server.URL, req, &out)
+	require.Error(t, err)
+	assert.True(t, graphql.IsPartialData(err))
+	assert.True(t, errors.Is(err, graphql.ErrNotFound))
+
+	require.Len(t, out.AssetsByIds, 3)
+	assert.Equal(t, "a1", out.AssetsByIds[0].ID)
+	assert.Nil(t, out.AssetsByIds[1])
+	assert.Equal(t, "a3", out.AssetsByIds[2].ID)
+
+	var pd *graphql.PartialDataError
+	require.True(t, errors.As(err, &pd))
+	require.Len(t, pd.Errors, 1)
+	assert.Equal(t, "asset not found", pd.ByPath()["assetsByIds/1"][0].Message)
+	assert.Equal(t, "ctpx-sdk-go/graphql: partial data, 1 error(s): message: asset not found (path assetsByIds/1)", err.Error())
+}
+
+func TestPartialData_Disabled(t *testing.T) {
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, []byte(partialBody))
+	defer server.Close()
+
+	var out partialOutput
+	err := graphql.ExecuteQuery(client.NewClient(), server.URL, graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader())), &out)
+	require.Error(t, err)
+	assert.False(t, graphql.IsPartialData(err))
+	assert.True(t, errors.Is(err, graphql.ErrNotFound))
+}
+
+func TestPartialData_NullData(t *testing.T) {
+	body := []byte(`{"data":null,"errors":[{"message":"boom"}]}`)
+	server := testutils.NewMockGQLServer(t, newHeader(), http.StatusOK, body)
+	defer server.Close()
+
+	var out partialOutput
+	req := graphql.NewRequest("testQuery", graphql.RequestWithHeader(newHeader()), graphql.RequestWithPartialData())
+	err := graphql.ExecuteQuery(client.NewClient(), server.URL, req, &out)
+	require.Error(t, err)
+	assert.False(t, graphql.IsPartialData(err))
+	assert.Nil(t, out.AssetsByIds)
+}
@@ -0,0 +1,115 @@
+package graphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// Error messages and codes of servers supporting Automatic Persisted Queries
+const (
+	MsgPersistedQueryNotFound     = "PersistedQueryNotFound"
+	MsgPersistedQueryNotSupported = "PersistedQueryNotSupported"
+
+	CodePersistedQueryNotFound     = "PERSISTED_QUERY_NOT_FOUND"
+	CodePersistedQueryNotSupported = "PERSISTED_QUERY_NOT_SUPPORTED"
+)
+
+// PersistedQueries enables Automatic Persisted Queries when set on a QueryConfig. Requests first send only
+// the SHA-256 hash of their query, and are sent again with the full query when the server does not know the hash,
+// the server then keeps it for the next requests. It remembers the servers which do not support persisted queries,
+// those get plain requests. It is safe for concurrent use
+type PersistedQueries struct {
+	mu          sync.RWMutex
+	unsupported map[string]bool
+}
+
+// NewPersistedQueries returns an empty persisted queries cache
+func NewPersistedQueries() *PersistedQueries {
+	return &PersistedQueries{unsupported: map[string]bool{}}
+}
+
+// QueryHash returns the hex encoded SHA-256 hash of query, as sent to the server
+func QueryHash(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+// Supported reports whether the server at serverURL supports persisted queries, it is true until the server says otherwise
+func (p *PersistedQueries) Supported(serverURL string) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return !p.unsupported[serverURL]
+}
+
+func (p *PersistedQueries) setUnsupported(serverURL string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.unsupported == nil {
+		p.unsupported = map[string]bool{}
+	}
+	p.unsupported[serverURL] = true
+}
+
+// newQuery returns nil when the server does not support persisted queries
+func (p *PersistedQueries) newQuery(serverURL string, req *Request) *persistedQuery {
+	if !p.Supported(serverURL) {
+		return nil
+	}
+	return &persistedQuery{cache: p, serverURL: serverURL, hash: QueryHash(req.Query), req: req}
+}
+
+// persistedQuery tracks the attempts of a single request
+type persistedQuery struct {
+	cache     *PersistedQueries
+	serverURL string
+	hash      string
+	req       *Request
+	// full sends the query along with its hash, plain sends it without the persisted query extension
+	full, plain bool
+}
+
+type persistedRequest struct {
+	Query      string                 `json:"query,omitempty"`
+	Variables  map[string]interface{} `json:"variables"`
+	Extensions *persistedExtensions   `json:"extensions,omitempty"`
+}
+
+type persistedExtensions struct {
+	PersistedQuery struct {
+		Version    int    `json:"version"`
+		SHA256Hash string `json:"sha256Hash"`
+	} `json:"persistedQuery"`
+}
+
+func (q *persistedQuery) body() persistedRequest {
+	body := persistedRequest{Variables: q.req.Variables}
+	if q.full || q.plain {
+		body.Query = q.req.Query
+	}
+	if !q.plain {
+		body.Extensions = &persistedExtensions{}
+		body.Extensions.PersistedQuery.Version = 1
+		body.Extensions.PersistedQuery.SHA256Hash = q.hash
+	}
+	return body
+}
+
+// retry reports whether the request must be sent again with the full query
+func (q *persistedQuery) retry(raw rawResponse) bool {
+	if q.full || q.plain {
+		return false
+	}
+	for _, e := range raw.Errors {
+		switch {
+		case e.Message == MsgPersistedQueryNotSupported || e.Code() == CodePersistedQueryNotSupported:
+			q.cache.setUnsupported(q.serverURL)
+			q.plain = true
+			return true
+		case e.Message == MsgPersistedQueryNotFound || e.Code() == CodePersistedQueryNotFound:
+			q.full = true
+			return true
+		}
+	}
+	return false
+}
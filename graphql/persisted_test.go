@@ -0,0 +1,122 @@
+package graphql_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+type apqBody struct {
+	Query      string `json:"query"`
+	Extensions struct {
+		PersistedQuery *struct {
+			Version    int    `json:"version"`
+			SHA256Hash string `json:"sha256Hash"`
+		} `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// apqServer persists queries like an Apollo server, or rejects persisted queries when unsupported is set
+func apqServer(t *testing.T, unsupported bool) (*httptest.Server, func() []apqBody) {
+	var (
+		mu     sync.Mutex
+		bodies []apqBody
+		stored = map[string]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body apqBody
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		mu.Lock()
+		defer mu.Unlock()
+		bodies = append(bodies, body)
+
+		pq := body.Extensions.PersistedQuery
+		switch {
+		case pq != nil && unsupported:
+			_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotSupported","extensions":{"code":"PERSISTED_QUERY_NOT_SUPPORTED"}}]}`))
+			return
+		case pq != nil && body.Query == "":
+			if _, ok := stored[pq.SHA256Hash]; !ok {
+				_, _ = w.Write([]byte(`{"errors":[{"message":"PersistedQueryNotFound","extensions":{"code":"PERSISTED_QUERY_NOT_FOUND"}}]}`))
+				return
+			}
+		case pq != nil:
+			assert.Equal(t, graphql.QueryHash(body.Query), pq.SHA256Hash)
+			stored[pq.SHA256Hash] = body.Query
+		}
+		_, _ = w.Write([]byte(`{"data":{"count":{"total":3}}}`))
+	}))
+	return server, func() []apqBody {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]apqBody(nil), bodies...)
+	}
+}
+
+func TestPersistedQueries(t *testing.T) {
+	server, bodies := apqServer(t, false)
+	defer server.Close()
+
+	const query = "query { count { total } }"
+	apq := graphql.NewPersistedQueries()
+	execute := func() {
+		var out countOutput
+		err := graphql.ExecuteQueryContext(context.Background(), &graphql.QueryConfig{
+			ServerURL:        server.URL,
+			HClient:          client.NewClient(),
+			Request:          graphql.NewRequest(query),
+			Output:           &out,
+			PersistedQueries: apq,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, out.Count.Total)
+	}
+
+	execute()
+	sent := bodies()
+	require.Len(t, sent, 2)
+	assert.Empty(t, sent[0].Query)
+	assert.Equal(t, query, sent[1].Query)
+
+	execute()
+	sent = bodies()
+	require.Len(t, sent, 3)
+	assert.Empty(t, sent[2].Query)
+	require.NotNil(t, sent[2].Extensions.PersistedQuery)
+	assert.Equal(t, graphql.QueryHash(query), sent[2].Extensions.PersistedQuery.SHA256Hash)
+}
+
+func TestPersistedQueries_NotSupported(t *testing.T) {
+	server, bodies := apqServer(t, true)
+	defer server.Close()
+
+	apq := graphql.NewPersistedQueries()
+	for i := 0; i < 2; i++ {
+		var out countOutput
+		err := graphql.ExecuteQueryContext(context.Background(), &graphql.QueryConfig{
+			ServerURL:        server.URL,
+			HClient:          client.NewClient(),
+			Request:          graphql.NewRequest("query { count { total } }"),
+			Output:           &out,
+			PersistedQueries: apq,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, out.Count.Total)
+	}
+
+	assert.False(t, apq.Supported(server.URL))
+	sent := bodies()
+	require.Len(t, sent, 3)
+	assert.NotNil(t, sent[0].Extensions.PersistedQuery)
+	assert.Nil(t, sent[1].Extensions.PersistedQuery)
+	assert.Nil(t, sent[2].Extensions.PersistedQuery)
+}
@@ -1,39 +1,62 @@
 package graphql
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 )
 
-type readStateFunc func() (json.Unmarshaler, readStateFunc)
+type readStateFunc func(conn *websocket.Conn) (json.Unmarshaler, readStateFunc)
 
-func (s *Subscription) startWSReader() {
+func (s *Subscription) startWSReader(conn *websocket.Conn) {
 	defer func() {
-		close(s.ch)
+		s.metrics.SubscriptionEnded(s.operation)
+		s.closeMessages()
 		close(s.readerDone)
 	}()
 	s.log.Debug().Msg("started reader goroutine")
 
-	currentState := s.readAckState
+	for conn != nil {
+		err := s.read(conn)
+		if !s.shouldReconnect(err) {
+			s.log.WithError(err).Warn().Msg("reader ended")
+			if s.isKeepAliveTimeout(err) && !s.isClosed() {
+				_ = s.informMessageReceived(&Message{Err: ErrKeepAliveTimeout})
+			}
+			return
+		}
+		s.log.WithError(err).Warn().Msg("sub connection lost, reconnecting")
+		conn = s.redial(conn)
+	}
+}
+
+// read runs the read states on conn until reading fails
+func (s *Subscription) read(conn *websocket.Conn) error {
+	defer s.watch(conn)()
+	currentState := s.readSubscriptionMessagesState
 	for currentState != nil {
 		var toRead json.Unmarshaler
-		toRead, currentState = currentState()
-		err := s.conn.ReadJSON(toRead)
+		toRead, currentState = currentState(conn)
+		err := conn.ReadJSON(toRead)
+		s.extendDeadline(conn)
 		if err != nil {
 			if errors.Is(err, errOmitMessage) {
 				continue
 			}
-			s.log.WithError(err).Warn().Msg("reader ended")
-			return
+			return err
 		}
 	}
+	return nil
 }
 
 type readAck struct {
-	s *Subscription
+	s    *Subscription
+	conn *websocket.Conn
 }
 
 func (r *readAck) UnmarshalJSON(data []byte) error {
@@ -42,21 +65,51 @@ func (r *readAck) UnmarshalJSON(data []byte) error {
 		err = errors.Wrap(err, "invalid ack message")
 		return err
 	}
-	if ack.Type != connectionAckMsg {
-		err := fmt.Errorf("expected ack message, got %#v", ack)
-		return err
+	switch ack.Type {
+	case connectionAckMsg:
+		return nil
+	case pingMsg: //graphql-transport-ws servers may ping before acknowledging
+		if err := r.s.writeJSON(r.conn, operationMessage{Type: pongMsg}); err != nil {
+			return errors.Wrap(err, "failed sending pong")
+		}
+		return errOmitMessage
 	}
-
-	return nil
+	return fmt.Errorf("expected ack message, got %#v", ack)
 }
 
-func (s *Subscription) readAckState() (json.Unmarshaler, readStateFunc) {
+// readAck reads conn until the server acknowledges the connection_init message. Nothing else may be sent
+// before, graphql-transport-ws servers close the connection with 4401 otherwise
+func (s *Subscription) readAck(ctx context.Context, conn *websocket.Conn) error {
 	s.log.Debug().Msg("readState:readAck")
-	return &readAck{s: s}, s.readSubscriptionMessagesState
+	done, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done(): //unblocks the read below
+			_ = conn.SetReadDeadline(time.Now())
+		case <-done:
+		}
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	for {
+		err := conn.ReadJSON(&readAck{s: s, conn: conn})
+		switch {
+		case errors.Is(err, errOmitMessage):
+			continue
+		case err != nil && ctx.Err() != nil:
+			return ctx.Err()
+		}
+		return err
+	}
 }
 
 type readMessage struct {
-	s *Subscription
+	s    *Subscription
+	conn *websocket.Conn
 }
 
 func (r *readMessage) UnmarshalJSON(data []byte) error {
@@ -72,28 +125,27 @@ func (r *readMessage) UnmarshalJSON(data []byte) error {
 
 	var msg *Message
 
+	protocol := r.s.protocolOf(r.conn)
 	switch op.Type {
-	case dataMsg:
-		var resp Response
-		resp.Data = r.s.responseCreator()
-
-		if err := json.Unmarshal(op.Payload, &resp); err != nil {
-			return errors.Wrap(err, "failed unmarshalling payload, make sure responseCreator is compatible with the graphql schema")
-		}
-
+	case dataMsg, nextMsg:
 		var err error
-		for _, gqlErr := range resp.Error {
-			err = multierror.Append(err, gqlErr)
+		if msg, err = r.s.dataMessage(op.Payload); err != nil {
+			return err
+		}
+	case connectionKaMsg, pingMsg:
+		if op.Type == pingMsg {
+			if err := r.s.writeJSON(r.conn, operationMessage{Type: pongMsg}); err != nil {
+				return errors.Wrap(err, "failed sending pong")
+			}
 		}
-
-		msg = &Message{Payload: resp.Data, Err: err}
-	case connectionKaMsg:
 		if !r.s.sendKAMsgs {
 			return errOmitMessage
 		}
 		msg = &Message{Payload: KeepAliveMessage{}}
+	case pongMsg:
+		return errOmitMessage
 	case errorMsg:
-		return fmt.Errorf(string(op.Payload))
+		return operationError(protocol, op.Payload)
 	case completedMsg:
 		return errSubscriptionCompleted
 	default:
@@ -107,7 +159,40 @@ func (r *readMessage) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
-func (s *Subscription) readSubscriptionMessagesState() (json.Unmarshaler, readStateFunc) {
+func (s *Subscription) readSubscriptionMessagesState(conn *websocket.Conn) (json.Unmarshaler, readStateFunc) {
 	s.log.Debug().Msg("readState:readSubscriptionMessages")
-	return &readMessage{s: s}, s.readSubscriptionMessagesState
+	return &readMessage{s: s, conn: conn}, s.readSubscriptionMessagesState
+}
+
+// dataMessage decodes the payload of a data message with the response creator
+func (s *Subscription) dataMessage(payload json.RawMessage) (*Message, error) {
+	s.metrics.SubscriptionMessageReceived(s.operation)
+	var resp Response
+	resp.Data = s.responseCreator()
+
+	if err := json.Unmarshal(payload, &resp); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshalling payload, make sure responseCreator is compatible with the graphql schema")
+	}
+
+	var err error
+	for _, gqlErr := range resp.Error {
+		err = multierror.Append(err, gqlErr)
+	}
+
+	return &Message{Payload: resp.Data, Err: err}, nil
+}
+
+// operationError returns the error of an error message
+func operationError(protocol SubscriptionProtocol, payload json.RawMessage) error {
+	if protocol == ProtocolGraphQLTransportWS { //the payload is a list of graphql errors
+		var gqlErrs []Error
+		if err := json.Unmarshal(payload, &gqlErrs); err == nil && len(gqlErrs) > 0 {
+			var err error
+			for _, gqlErr := range gqlErrs {
+				err = multierror.Append(err, gqlErr)
+			}
+			return err
+		}
+	}
+	return fmt.Errorf(string(payload))
 }
@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"path"
@@ -13,10 +14,11 @@ import (
 // This should NOT be instantiated directly, you should use the NewRequest method
 // to make sure your getting everything you need set
 type Request struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
-	Header    http.Header
-	logger    log.Logger
+	Query       string                 `json:"query"`
+	Variables   map[string]interface{} `json:"variables"`
+	Header      http.Header
+	logger      log.Logger
+	partialData bool
 }
 
 // NewRequest creates a default graphql request with empty vars
@@ -42,11 +44,61 @@ func (r *Request) Vars(vars map[string]interface{}) {
 	}
 }
 
+// clone copies the request so its headers and variables can be changed without affecting r
+func (r *Request) clone() *Request {
+	c := &Request{
+		Query:       r.Query,
+		Variables:   make(map[string]interface{}, len(r.Variables)),
+		Header:      r.Header.Clone(),
+		logger:      r.logger,
+		partialData: r.partialData,
+	}
+	for k, v := range r.Variables {
+		c.Variables[k] = v
+	}
+	if c.Header == nil {
+		c.Header = http.Header{}
+	}
+	return c
+}
+
 // Error is the response type for graphql errors from our gateway
 type Error struct {
 	Message   string           `json:"message"`
 	Locations []map[string]int `json:"locations"`
-	Path      []string         `json:"path"`
+	// Path holds the response fields leading to the error, list indices are formatted as strings
+	Path       []string               `json:"path"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// UnmarshalJSON accepts the list indices of a path as numbers
+func (e *Error) UnmarshalJSON(data []byte) error {
+	type plain Error
+	var raw struct {
+		plain
+		Path []interface{} `json:"path"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	*e = Error(raw.plain)
+	e.Path = nil
+	for _, p := range raw.Path {
+		e.Path = append(e.Path, fmt.Sprint(p))
+	}
+	return nil
+}
+
+// Code returns the code in the error extensions, or an empty string
+func (e Error) Code() string {
+	code, _ := e.Extensions["code"].(string)
+	return code
+}
+
+// Is matches the sentinel error of the extensions code, such as ErrNotFound for NOT_FOUND
+func (e Error) Is(target error) bool {
+	s, ok := codeSentinels[e.Code()]
+	return ok && s == target
 }
 
 func (e Error) Error() string {
@@ -89,6 +141,14 @@ func RequestWithLogger(logger log.Logger) RequestOption {
 	}
 }
 
+// RequestWithPartialData keeps the data of a response which also has graphql errors. The output is decoded
+// and a *PartialDataError holding the errors is returned, see IsPartialData
+func RequestWithPartialData() RequestOption {
+	return func(r *Request) {
+		r.partialData = true
+	}
+}
+
 // RequestWithHeader adds a set of headers to the request, any headers that are already present, will be skipped
 func RequestWithHeader(header http.Header) RequestOption {
 	return func(r *Request) {
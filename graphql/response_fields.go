@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	responseFieldsCache sync.Map
+
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// ResponseFieldsOf returns the selection set of the fields v is decoded from, v being a struct or a pointer, slice or
+// array of structs. The fields are selected by their JSON name, nested structs with their own fields, while the
+// structs decoding themselves like time.Time or nulls.String, the maps and the interfaces are leaves.
+// Embedded structs are flattened like encoding/json does.
+//
+// A graphql tag gives the field to select under the JSON name, with its arguments, or "-" to leave the field out:
+//
+//	type Investigation struct {
+//		ID     string `json:"id"`
+//		Recent []struct {
+//			ID string `json:"id"`
+//		} `json:"recent" graphql:"alerts(first: 5)"`
+//		Notes string `json:"notes" graphql:"-"`
+//	}
+//
+// selects id and recent: alerts(first: 5) { id }. The selection sets are cached by type
+func ResponseFieldsOf(v interface{}) (ResponseFields, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return "", errors.New("ctpx-sdk-go/graphql: nil value to ResponseFieldsOf")
+	}
+	if rf, ok := responseFieldsCache.Load(t); ok {
+		return rf.(ResponseFields), nil
+	}
+
+	elem := elemType(t)
+	if isLeaf(elem) {
+		return "", fmt.Errorf("ctpx-sdk-go/graphql: %s has no response fields, it must be a struct or a pointer, slice or array of structs", t)
+	}
+	var b strings.Builder
+	if err := writeResponseFields(&b, elem, 1, map[reflect.Type]bool{}); err != nil {
+		return "", err
+	}
+	rf := ResponseFields(b.String() + "\n")
+	responseFieldsCache.Store(t, rf)
+	return rf, nil
+}
+
+// MustResponseFieldsOf is ResponseFieldsOf panicking on error, to initialize package variables
+func MustResponseFieldsOf(v interface{}) ResponseFields {
+	rf, err := ResponseFieldsOf(v)
+	if err != nil {
+		panic(err)
+	}
+	return rf
+}
+
+func writeResponseFields(b *strings.Builder, t reflect.Type, depth int, parents map[reflect.Type]bool) error {
+	if parents[t] {
+		return fmt.Errorf("ctpx-sdk-go/graphql: recursive type %s in the response fields", t)
+	}
+	parents[t] = true
+	defer delete(parents, t)
+
+	indent := strings.Repeat("\t", depth)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			continue
+		}
+		jsonName := strings.Split(f.Tag.Get("json"), ",")[0]
+		selection := f.Tag.Get("graphql")
+		if jsonName == "-" || selection == "-" {
+			continue
+		}
+
+		elem := elemType(f.Type)
+		if f.Anonymous && jsonName == "" && selection == "" && elem.Kind() == reflect.Struct {
+			if isLeaf(elem) {
+				continue
+			}
+			if err := writeResponseFields(b, elem, depth, parents); err != nil {
+				return err
+			}
+			continue
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+
+		if jsonName == "" {
+			jsonName = f.Name
+		}
+		if selection == "" {
+			selection = jsonName
+		} else if field := strings.TrimSpace(strings.SplitN(selection, "(", 2)[0]); !strings.Contains(field, ":") && field != jsonName {
+			selection = jsonName + ": " + selection
+		}
+
+		b.WriteString("\n" + indent + selection)
+		if isLeaf(elem) {
+			continue
+		}
+		b.WriteString(" {")
+		if err := writeResponseFields(b, elem, depth+1, parents); err != nil {
+			return err
+		}
+		b.WriteString("\n" + indent + "}")
+	}
+	return nil
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t
+}
+
+// isLeaf reports whether t is decoded from a scalar rather than from a selection of fields
+func isLeaf(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return true
+	}
+	p := reflect.PtrTo(t)
+	return p.Implements(jsonUnmarshalerType) || p.Implements(textUnmarshalerType)
+}
@@ -0,0 +1,115 @@
+package graphql_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+type fieldsMeta struct {
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt nulls.Time
+}
+
+type fieldsAlert struct {
+	ID    string      `json:"id"`
+	Score *float64    `json:"score,omitempty"`
+	Tags  common.Tags `json:"tags"`
+}
+
+type fieldsInvestigation struct {
+	fieldsMeta
+	ID          string                 `json:"id"`
+	Description nulls.String           `json:"description"`
+	Alerts      []*fieldsAlert         `json:"alerts"`
+	Recent      []fieldsAlert          `json:"recent" graphql:"alerts(first: 5, orderBy: score)"`
+	Lead        *fieldsAlert           `json:"lead" graphql:"lead: alerts(first: 1)"`
+	Assignee    struct{ Name string }  `json:"assignee"`
+	Metadata    map[string]interface{} `json:"metadata"`
+	Raw         json.RawMessage        `json:"raw"`
+	Any         interface{}            `json:"any"`
+	Ignored     string                 `json:"-"`
+	Local       string                 `graphql:"-"`
+	internal    string
+}
+
+func TestResponseFieldsOf(t *testing.T) {
+	rf, err := graphql.ResponseFieldsOf(&fieldsInvestigation{})
+	require.NoError(t, err)
+	assert.Equal(t, graphql.ResponseFields(`
+	created_at
+	UpdatedAt
+	id
+	description
+	alerts {
+		id
+		score
+		tags
+	}
+	recent: alerts(first: 5, orderBy: score) {
+		id
+		score
+		tags
+	}
+	lead: alerts(first: 1) {
+		id
+		score
+		tags
+	}
+	assignee {
+		Name
+	}
+	metadata
+	raw
+	any
+`), rf)
+
+	for _, v := range []interface{}{fieldsInvestigation{}, []fieldsInvestigation{}, &[]*fieldsInvestigation{}} {
+		other, err := graphql.ResponseFieldsOf(v)
+		require.NoError(t, err)
+		assert.Equal(t, rf, other)
+	}
+}
+
+func TestResponseFieldsOf_Errors(t *testing.T) {
+	type node struct {
+		ID       string  `json:"id"`
+		Children []*node `json:"children"`
+	}
+
+	for _, v := range []interface{}{nil, "id", []int{}, &time.Time{}, node{}} {
+		_, err := graphql.ResponseFieldsOf(v)
+		assert.Error(t, err, "%T", v)
+	}
+	assert.Panics(t, func() { graphql.MustResponseFieldsOf(&node{}) })
+}
+
+func TestResponseFieldsOf_Request(t *testing.T) {
+	var out struct {
+		Investigation struct {
+			ID string `json:"id"`
+		} `json:"investigation" graphql:"investigation(id: $id)"`
+	}
+	rf := graphql.MustResponseFieldsOf(&out)
+	assert.Equal(t, graphql.ResponseFields(`
+	investigation(id: $id) {
+		id
+	}
+`), rf)
+
+	schema, err := graphql.ParseSchema(`
+		type Query { investigation(id: ID!): Investigation }
+		type Investigation { id: ID! }
+	`)
+	require.NoError(t, err)
+	req := graphql.NewRequest(`query ($id: ID!) {` + string(rf) + `}`)
+	req.Var("id", "i1")
+	assert.NoError(t, schema.Validate(req))
+}
@@ -0,0 +1,147 @@
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/go-multierror"
+	"github.com/vektah/gqlparser/v2"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"github.com/vektah/gqlparser/v2/validator"
+)
+
+// Schema validates requests offline against a GraphQL schema, set it on a QueryConfig to check the requests
+// before sending them. It is safe for concurrent use
+type Schema struct {
+	schema *ast.Schema
+}
+
+// LoadSchema parses the SDL files at paths into a single schema, directories are read for their .graphql files
+func LoadSchema(paths ...string) (*Schema, error) {
+	var sources []*ast.Source
+	for _, p := range paths {
+		files := []string{p}
+		if info, err := os.Stat(p); err != nil {
+			return nil, fmt.Errorf("ctpx-sdk-go/graphql: failed loading schema: %w", err)
+		} else if info.IsDir() {
+			if files, err = filepath.Glob(filepath.Join(p, "*.graphql")); err != nil {
+				return nil, fmt.Errorf("ctpx-sdk-go/graphql: failed loading schema: %w", err)
+			}
+		}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("ctpx-sdk-go/graphql: failed loading schema: %w", err)
+			}
+			sources = append(sources, &ast.Source{Name: f, Input: string(b)})
+		}
+	}
+	return parseSchema(sources)
+}
+
+// ParseSchema parses SDL documents into a single schema
+func ParseSchema(sdl ...string) (*Schema, error) {
+	sources := make([]*ast.Source, 0, len(sdl))
+	for i, s := range sdl {
+		sources = append(sources, &ast.Source{Name: fmt.Sprintf("schema%d.graphql", i), Input: s})
+	}
+	return parseSchema(sources)
+}
+
+func parseSchema(sources []*ast.Source) (*Schema, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("ctpx-sdk-go/graphql: empty schema")
+	}
+	s, gqlErr := gqlparser.LoadSchema(sources...)
+	if gqlErr != nil {
+		return nil, fmt.Errorf("ctpx-sdk-go/graphql: invalid schema: %w", gqlErr)
+	}
+	return &Schema{schema: s}, nil
+}
+
+// AST returns the parsed definitions of the schema, for tools such as code generators. It must not be modified
+func (s *Schema) AST() *ast.Schema {
+	return s.schema
+}
+
+// Validate checks the query of req and the types of its variables. The problems are returned as Error values
+// with the GRAPHQL_VALIDATION_FAILED code, like the errors of a server rejecting the request,
+// so they match ErrValidation with errors.Is
+func (s *Schema) Validate(req *Request) error {
+	doc, gqlErrs := gqlparser.LoadQuery(s.schema, req.Query)
+	if len(gqlErrs) > 0 {
+		return validationErrors(gqlErrs...)
+	}
+
+	vars, err := jsonVariables(req.Variables)
+	if err != nil {
+		return fmt.Errorf("ctpx-sdk-go/graphql: invalid variables: %w", err)
+	}
+	for _, op := range doc.Operations {
+		if _, gqlErr := validator.VariableValues(s.schema, op, vars); gqlErr != nil {
+			return validationErrors(gqlErr)
+		}
+	}
+	return nil
+}
+
+func validationErrors(gqlErrs ...*gqlerror.Error) error {
+	var err error
+	for _, gqlErr := range gqlErrs {
+		e := Error{
+			Message:    gqlErr.Message,
+			Extensions: map[string]interface{}{"code": CodeValidationFailed},
+		}
+		for _, l := range gqlErr.Locations {
+			e.Locations = append(e.Locations, map[string]int{"line": l.Line, "column": l.Column})
+		}
+		for _, p := range gqlErr.Path {
+			e.Path = append(e.Path, fmt.Sprint(p))
+		}
+		err = multierror.Append(err, e)
+	}
+	return err
+}
+
+// jsonVariables returns the variables as the server decodes them, integers as int64 and other numbers as float64
+func jsonVariables(vars map[string]interface{}) (map[string]interface{}, error) {
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out map[string]interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return jsonNumbers(out).(map[string]interface{}), nil
+}
+
+func jsonNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return i
+		}
+		f, _ := v.Float64()
+		return f
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = jsonNumbers(e)
+		}
+		if v == nil {
+			return map[string]interface{}{}
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = jsonNumbers(e)
+		}
+	}
+	return v
+}
@@ -0,0 +1,148 @@
+package graphql_test
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+const testSDL = `
+type Query {
+	asset(id: ID!): Asset
+	assets(limit: Int, state: AssetState): [Asset!]
+}
+
+type Asset {
+	id: ID!
+	hostname: String
+	score: Float
+}
+
+enum AssetState {
+	Active
+	Deleted
+}
+`
+
+func TestSchema_Validate(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	require.NoError(t, err)
+
+	for name, tc := range map[string]struct {
+		query string
+		vars  map[string]interface{}
+		err   string
+	}{
+		"Valid": {
+			query: `query ($id: ID!) { asset(id: $id) { id hostname score } }`,
+			vars:  map[string]interface{}{"id": "a1"},
+		},
+		"ValidNumbersAndEnums": {
+			query: `query ($limit: Int, $state: AssetState) { assets(limit: $limit, state: $state) { id } }`,
+			vars:  map[string]interface{}{"limit": 10, "state": "Deleted"},
+		},
+		"UnknownField": {
+			query: `query ($id: ID!) { asset(id: $id) { id hostnme } }`,
+			vars:  map[string]interface{}{"id": "a1"},
+			err:   `Cannot query field "hostnme" on type "Asset"`,
+		},
+		"Syntax": {
+			query: `query { asset(id: "a1") { id }`,
+			err:   "Expected Name, found <EOF>",
+		},
+		"MissingVariable": {
+			query: `query ($id: ID!) { asset(id: $id) { id } }`,
+			err:   "must be defined",
+		},
+		"VariableType": {
+			query: `query ($limit: Int) { assets(limit: $limit) { id } }`,
+			vars:  map[string]interface{}{"limit": 1.5},
+			err:   "cannot use float64 as Int",
+		},
+		"Enum": {
+			query: `query ($state: AssetState) { assets(state: $state) { id } }`,
+			vars:  map[string]interface{}{"state": "Gone"},
+			err:   "Gone is not a valid AssetState",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := schema.Validate(&graphql.Request{Query: tc.query, Variables: tc.vars})
+			if tc.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.err)
+			assert.True(t, errors.Is(err, graphql.ErrValidation))
+
+			var gqlErr graphql.Error
+			require.True(t, errors.As(err, &gqlErr))
+			assert.Equal(t, graphql.CodeValidationFailed, gqlErr.Code())
+		})
+	}
+}
+
+func TestSchema_Load(t *testing.T) {
+	dir, err := ioutil.TempDir("", "schema")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "base.graphql"), []byte(testSDL), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "tags.graphql"), []byte(`extend type Query { tags: [String!] }`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "README.md"), []byte(`not a schema`), 0600))
+
+	schema, err := graphql.LoadSchema(dir)
+	require.NoError(t, err)
+	assert.NoError(t, schema.Validate(&graphql.Request{Query: `{ tags asset(id: "a1") { id } }`}))
+
+	schema, err = graphql.LoadSchema(filepath.Join(dir, "base.graphql"))
+	require.NoError(t, err)
+	assert.Error(t, schema.Validate(&graphql.Request{Query: `{ tags }`}))
+
+	_, err = graphql.LoadSchema(filepath.Join(dir, "missing.graphql"))
+	assert.Error(t, err)
+	_, err = graphql.LoadSchema(filepath.Join(dir, "README.md"))
+	assert.Error(t, err)
+	_, err = graphql.LoadSchema()
+	assert.Error(t, err)
+}
+
+func TestSchema_QueryConfig(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	require.NoError(t, err)
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		_, _ = w.Write([]byte(`{"data":{"asset":{"id":"a1"}}}`))
+	}))
+	defer server.Close()
+
+	execute := func(query string) error {
+		return graphql.ExecuteQueryContext(context.Background(), &graphql.QueryConfig{
+			ServerURL: server.URL,
+			HClient:   client.NewClient(),
+			Request:   graphql.NewRequest(query),
+			Schema:    schema,
+		})
+	}
+
+	err = execute(`{ asset(id: "a1") { id name } }`)
+	assert.True(t, errors.Is(err, graphql.ErrValidation))
+	assert.Equal(t, int32(0), atomic.LoadInt32(&calls))
+
+	require.NoError(t, execute(`{ asset(id: "a1") { id } }`))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
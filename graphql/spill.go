@@ -0,0 +1,329 @@
+package graphql
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"github.com/hashicorp/go-multierror"
+)
+
+const (
+	spillHeaderSize        = 4
+	spillCompactBufferSize = 32 * 1024
+)
+
+// spillQueue is a bounded on-disk FIFO of messages waiting for room in the Messages channel.
+// Records are a 4 bytes big endian length followed by a JSON spilledMessage
+type spillQueue struct {
+	s *Subscription
+
+	mu       sync.Mutex
+	f        *os.File
+	readOff  int64
+	writeOff int64
+	max      int64
+	pending  int
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+type spilledMessage struct {
+	Kind        string              `json:"kind"`
+	Payload     json.RawMessage     `json:"payload,omitempty"`
+	Errors      []Error             `json:"errors,omitempty"`
+	Err         string              `json:"err,omitempty"`
+	Sentinel    string              `json:"sentinel,omitempty"`
+	Reconnected *ReconnectedMessage `json:"reconnected,omitempty"`
+}
+
+// spilledSentinels are the sentinel errors kept by name through the spill queue, so errors.Is still matches them
+var spilledSentinels = map[string]error{
+	"validation":        ErrValidation,
+	"unauthorized":      ErrUnauthorized,
+	"forbidden":         ErrForbidden,
+	"not_found":         ErrNotFound,
+	"server":            ErrServer,
+	"rate_limited":      ErrRateLimited,
+	"reconnected":       ErrReconnected,
+	"keepalive_timeout": ErrKeepAliveTimeout,
+}
+
+// spilledError is an error read back from the spill queue, wrapping its sentinel error
+type spilledError struct {
+	msg      string
+	sentinel error
+}
+
+func (e *spilledError) Error() string { return e.msg }
+
+func (e *spilledError) Unwrap() error { return e.sentinel }
+
+const (
+	spilledData        = "data"
+	spilledKeepAlive   = "ka"
+	spilledReconnected = "reconnected"
+)
+
+func newSpillQueue(s *Subscription, dir string, max int64) (*spillQueue, error) {
+	f, err := ioutil.TempFile(dir, "ctpx-subscription-*.queue")
+	if err != nil {
+		return nil, err
+	}
+	q := &spillQueue{
+		s:    s,
+		f:    f,
+		max:  max,
+		wake: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	go q.forward()
+	return q, nil
+}
+
+// push sends m to the channel, or queues it when the channel is full or older messages are queued
+func (q *spillQueue) push(m *Message) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		select {
+		case q.s.ch <- m:
+			return nil
+		default:
+		}
+	}
+
+	b, err := encodeSpilled(m)
+	if err != nil {
+		q.s.log.WithError(err).Warn().Msg("failed encoding message to spill, dropping it")
+		q.s.drop()
+		return nil
+	}
+	size := int64(spillHeaderSize + len(b))
+	live := q.writeOff - q.readOff
+	if q.max > 0 && live+size > q.max {
+		q.s.drop()
+		return nil
+	}
+	//the delivered records are removed once they outweigh the queued ones, or to stay within max
+	if q.readOff > 0 && (q.readOff > live || q.max > 0 && q.writeOff+size > q.max) {
+		if err := q.compact(); err != nil {
+			return err
+		}
+	}
+	record := make([]byte, size)
+	binary.BigEndian.PutUint32(record, uint32(len(b)))
+	copy(record[spillHeaderSize:], b)
+	if _, err := q.f.WriteAt(record, q.writeOff); err != nil {
+		return err
+	}
+	q.writeOff += size
+	q.pending++
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// peek returns the oldest queued record without removing it
+func (q *spillQueue) peek() ([]byte, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.pending == 0 {
+		return nil, false
+	}
+	header := make([]byte, spillHeaderSize)
+	if _, err := q.f.ReadAt(header, q.readOff); err != nil {
+		q.s.log.WithError(err).Error().Msg("failed reading spill queue")
+		q.reset()
+		return nil, false
+	}
+	b := make([]byte, binary.BigEndian.Uint32(header))
+	if _, err := q.f.ReadAt(b, q.readOff+spillHeaderSize); err != nil {
+		q.s.log.WithError(err).Error().Msg("failed reading spill queue")
+		q.reset()
+		return nil, false
+	}
+	return b, true
+}
+
+// pop removes the oldest queued record of size n
+func (q *spillQueue) pop(n int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.readOff += int64(spillHeaderSize + n)
+	q.pending--
+	if q.pending == 0 {
+		q.reset()
+	}
+}
+
+// compact moves the queued records to the start of the file and truncates it, the mutex must be held
+func (q *spillQueue) compact() error {
+	buf := make([]byte, spillCompactBufferSize)
+	var moved int64
+	for off := q.readOff; off < q.writeOff; off += int64(len(buf)) {
+		if rest := q.writeOff - off; rest < int64(len(buf)) {
+			buf = buf[:rest]
+		}
+		//the records only move backwards, so each chunk is read before being overwritten
+		if _, err := q.f.ReadAt(buf, off); err != nil {
+			return err
+		}
+		if _, err := q.f.WriteAt(buf, moved); err != nil {
+			return err
+		}
+		moved += int64(len(buf))
+	}
+	if err := q.f.Truncate(moved); err != nil {
+		return err
+	}
+	q.readOff, q.writeOff = 0, moved
+	return nil
+}
+
+// reset empties the queue, the mutex must be held
+func (q *spillQueue) reset() {
+	if q.pending > 0 {
+		for i := 0; i < q.pending; i++ {
+			q.s.drop()
+		}
+	}
+	q.pending, q.readOff, q.writeOff = 0, 0, 0
+	_ = q.f.Truncate(0)
+}
+
+// forward moves the queued messages to the channel until stopped with an empty queue or the subscription is shut down
+func (q *spillQueue) forward() {
+	defer close(q.done)
+	for {
+		b, ok := q.peek()
+		if !ok {
+			select {
+			case <-q.wake:
+				continue
+			case <-q.stop:
+				if _, ok := q.peek(); ok { //queued between peek and stop
+					continue
+				}
+				return
+			case <-q.s.closed:
+				return
+			}
+		}
+
+		m, err := q.s.decodeSpilled(b)
+		if err != nil {
+			q.s.log.WithError(err).Warn().Msg("failed decoding spilled message, dropping it")
+			q.s.drop()
+			q.pop(len(b))
+			continue
+		}
+		select {
+		case q.s.ch <- m:
+			q.pop(len(b))
+		case <-q.s.closed:
+			return
+		}
+	}
+}
+
+// close waits for the queued messages to be delivered, or the subscription to be shut down, and removes the file
+func (q *spillQueue) close() {
+	close(q.stop)
+	<-q.done
+	_ = q.f.Close()
+	_ = os.Remove(q.f.Name())
+}
+
+func encodeSpilled(m *Message) ([]byte, error) {
+	var sm spilledMessage
+	switch p := m.Payload.(type) {
+	case KeepAliveMessage:
+		sm.Kind = spilledKeepAlive
+	case ReconnectedMessage:
+		sm.Kind = spilledReconnected
+		sm.Reconnected = &p
+	default:
+		sm.Kind = spilledData
+		if p != nil {
+			payload, err := json.Marshal(p)
+			if err != nil {
+				return nil, err
+			}
+			sm.Payload = payload
+		}
+	}
+
+	if m.Err != nil {
+		sm.Err = m.Err.Error()
+		for name, sentinel := range spilledSentinels {
+			if errors.Is(m.Err, sentinel) {
+				sm.Sentinel = name
+				break
+			}
+		}
+		var merr *multierror.Error
+		if errors.As(m.Err, &merr) {
+			for _, err := range merr.Errors {
+				gqlErr, ok := err.(Error)
+				if !ok {
+					sm.Errors = nil
+					break
+				}
+				sm.Errors = append(sm.Errors, gqlErr)
+			}
+		}
+	}
+	return json.Marshal(sm)
+}
+
+func (s *Subscription) decodeSpilled(b []byte) (*Message, error) {
+	var sm spilledMessage
+	if err := json.Unmarshal(b, &sm); err != nil {
+		return nil, err
+	}
+
+	m := &Message{}
+	switch sm.Kind {
+	case spilledKeepAlive:
+		m.Payload = KeepAliveMessage{}
+	case spilledReconnected:
+		if sm.Reconnected != nil {
+			m.Payload = *sm.Reconnected
+		}
+	default:
+		if len(sm.Payload) > 0 {
+			payload := s.responseCreator()
+			if err := json.Unmarshal(sm.Payload, payload); err != nil {
+				return nil, err
+			}
+			m.Payload = payload
+		}
+	}
+
+	switch {
+	case len(sm.Errors) > 0:
+		var err error
+		for _, gqlErr := range sm.Errors {
+			err = multierror.Append(err, gqlErr)
+		}
+		m.Err = err
+	case spilledSentinels[sm.Sentinel] != nil:
+		m.Err = spilledSentinels[sm.Sentinel]
+		if sm.Err != m.Err.Error() {
+			m.Err = &spilledError{msg: sm.Err, sentinel: m.Err}
+		}
+	case sm.Err != "":
+		m.Err = errors.New(sm.Err)
+	}
+	return m, nil
+}
@@ -3,20 +3,31 @@ package graphql
 import (
 	"context"
 	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/secureworks/taegis-sdk-go/internal/operation"
 	"github.com/secureworks/taegis-sdk-go/log"
 
 	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
 )
 
 const (
 	defaultBufferSize     = 1024
 	disconnectionDeadline = 5 * time.Second
+
+	defaultReconnectBaseDelay = 500 * time.Millisecond
+	defaultReconnectMaxDelay  = 30 * time.Second
+	defaultReconnectJitter    = 0.2
 )
 
 var (
@@ -26,6 +37,8 @@ var (
 )
 
 type Subscription struct {
+	//dropped is first to be 64-bit aligned for atomic operations
+	dropped         uint64
 	ch              chan *Message
 	conn            *websocket.Conn
 	u               *url.URL
@@ -36,7 +49,36 @@ type Subscription struct {
 	responseCreator func() interface{}
 	query           string
 	vars            map[string]interface{}
+	tokenSource     oauth2.TokenSource
+	metrics         SubscriptionInstrumentation
+	operation       string
+	reconnect       *ReconnectPolicy
+	kaTimeout       time.Duration
+	protocol        SubscriptionProtocol
+	negotiate       bool
+	multiplexer     *Multiplexer
+	backpressure    BackpressurePolicy
+	blockCtx        context.Context
+	spillDir        string
+	spillMax        int64
+	spill           *spillQueue
+	trace           log.TraceContext
+	hasTrace        bool
+	interceptors    []MessageInterceptor
+	//deliverMessage runs the interceptors, then deliver
+	deliverMessage MessageHandler
+
+	//mux and id are set for the subscriptions sharing a websocket
+	mux *muxConn
+	id  string
 
+	//guards conn, which is replaced on reconnection
+	mu sync.Mutex
+	//serialises the writes to conn
+	writeMu sync.Mutex
+	//closed when Shutdown is called
+	closed    chan struct{}
+	closeOnce sync.Once
 	//indicates that the reader goroutine is done
 	readerDone chan struct{}
 }
@@ -48,6 +90,15 @@ type Message struct {
 
 type KeepAliveMessage struct{}
 
+// ReconnectedMessage is the payload sent after the websocket was dialed again with SubscriptionWithReconnect.
+// Messages published while the subscription was down are lost
+type ReconnectedMessage struct {
+	// Attempts is the number of dials it took to reconnect
+	Attempts int
+	// Downtime is the time between the connection loss and the reconnection
+	Downtime time.Duration
+}
+
 type SubscriptionOption func(s *Subscription)
 
 func SubscriptionSendKAMessages(s *Subscription) {
@@ -79,12 +130,104 @@ func SubscriptionWithTenant(tenantID string) SubscriptionOption {
 
 func SubscriptionWithToken(token string) SubscriptionOption {
 	return func(s *Subscription) {
-		c := http.Cookie{
-			Name:    "access_token",
-			Value:   token,
-			Expires: time.Now().Add(1 * time.Hour),
-		}
-		s.header.Add("Cookie", c.String())
+		s.header.Add("Cookie", accessTokenCookie(token).String())
+	}
+}
+
+// SubscriptionWithTokenSource gets the access token from ts every time the websocket is dialed,
+// instead of the static token of SubscriptionWithToken
+func SubscriptionWithTokenSource(ts oauth2.TokenSource) SubscriptionOption {
+	return func(s *Subscription) {
+		s.tokenSource = ts
+	}
+}
+
+// SubscriptionInstrumentation receives the metrics of subscriptions.
+// operation is the subscription operation name, see client.OperationName
+type SubscriptionInstrumentation interface {
+	SubscriptionStarted(operation string)
+	SubscriptionEnded(operation string)
+	SubscriptionMessageReceived(operation string)
+	SubscriptionMessageDropped(operation string)
+	SubscriptionReconnected(operation string)
+}
+
+type noopSubscriptionInstrumentation struct{}
+
+func (noopSubscriptionInstrumentation) SubscriptionStarted(string)         {}
+func (noopSubscriptionInstrumentation) SubscriptionEnded(string)           {}
+func (noopSubscriptionInstrumentation) SubscriptionMessageReceived(string) {}
+func (noopSubscriptionInstrumentation) SubscriptionMessageDropped(string)  {}
+func (noopSubscriptionInstrumentation) SubscriptionReconnected(string)     {}
+
+// SubscriptionWithInstrumentation reports the subscription metrics to in
+func SubscriptionWithInstrumentation(in SubscriptionInstrumentation) SubscriptionOption {
+	return func(s *Subscription) {
+		s.metrics = in
+	}
+}
+
+// ReconnectPolicy defines how a subscription redials its websocket when the connection drops
+type ReconnectPolicy struct {
+	// MaxAttempts is the number of dials made before giving up, 0 retries forever
+	MaxAttempts int
+	// BaseDelay is the delay before the first dial, it doubles with every following attempt
+	BaseDelay time.Duration
+	// MaxDelay caps the delay between two attempts
+	MaxDelay time.Duration
+	// Jitter is the fraction (0 to 1) of each delay which is randomised
+	Jitter float64
+}
+
+// DefaultReconnectPolicy returns a ReconnectPolicy retrying forever with an exponential backoff from 500ms up to 30s
+func DefaultReconnectPolicy() ReconnectPolicy {
+	return ReconnectPolicy{
+		BaseDelay: defaultReconnectBaseDelay,
+		MaxDelay:  defaultReconnectMaxDelay,
+		Jitter:    defaultReconnectJitter,
+	}
+}
+
+// backoff returns the delay before the given attempt, attempts are counted from 1
+func (p *ReconnectPolicy) backoff(attempt int) time.Duration {
+	delay := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay -= delay * p.Jitter * rand.Float64() //nolint:gosec // jitter does not need a secure source
+	}
+	return time.Duration(delay)
+}
+
+// SubscriptionWithReconnect redials the websocket when the connection drops, replaying connection_init and the
+// subscription with the same variables and a fresh token from the token source. Every reconnection is reported
+// with a ReconnectedMessage. The subscription ends when the attempts run out
+func SubscriptionWithReconnect(p ReconnectPolicy) SubscriptionOption {
+	return func(s *Subscription) {
+		s.reconnect = &p
+	}
+}
+
+// SubscriptionWithProtocol sets the websocket protocol, defaults to ProtocolGraphQLWS.
+// The protocol is requested with the Sec-WebSocket-Protocol header
+func SubscriptionWithProtocol(p SubscriptionProtocol) SubscriptionOption {
+	return func(s *Subscription) {
+		s.protocol = p
+	}
+}
+
+// SubscriptionNegotiateProtocol offers both protocols to the server, preferring ProtocolGraphQLTransportWS,
+// and uses the one the server picks. Servers which do not pick one get ProtocolGraphQLWS
+func SubscriptionNegotiateProtocol(s *Subscription) {
+	s.negotiate = true
+}
+
+func accessTokenCookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:    "access_token",
+		Value:   token,
+		Expires: time.Now().Add(1 * time.Hour),
 	}
 }
 
@@ -105,39 +248,36 @@ func NewSubscription(ctx context.Context, u *url.URL, query string, responseCrea
 		header:          http.Header{},
 		responseCreator: responseCreator,
 		query:           query,
+		operation:       operation.Name(query),
+		protocol:        ProtocolGraphQLWS,
+		metrics:         noopSubscriptionInstrumentation{},
+		closed:          make(chan struct{}),
 		readerDone:      make(chan struct{}, 1),
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
-
-	var (
-		err  error
-		resp *http.Response
-	)
-	s.conn, resp, err = websocket.DefaultDialer.DialContext(ctx, s.u.String(), s.header)
-	if err != nil {
-		s.conn = nil
-		if resp != nil {
-			return nil, errors.Wrap(err, fmt.Sprintf("websocket error with status: %d", resp.StatusCode))
-		}
-		return nil, errors.Wrap(err, "websocket error")
+	if len(s.interceptors) > 0 {
+		s.deliverMessage = s.chainMessageInterceptors(s.deliver)
+	}
+	s.trace, s.hasTrace = log.TraceContextFromCtx(ctx)
+	if s.multiplexer != nil {
+		return s.multiplexer.subscribe(ctx, s)
 	}
 
-	defer func() {
-		if err != nil {
-			_ = s.Shutdown(ctx)
-		}
-	}()
-
-	s.ch = make(chan *Message, s.bufferSize)
-
-	go s.startWSReader()
-	if err = s.connect(ctx); err != nil {
+	var err error
+	if s.conn, err = s.dial(ctx); err != nil {
+		return nil, err
+	}
+	if err = s.initMessages(); err != nil {
+		_ = s.conn.Close()
 		return nil, err
 	}
 
-	if err != nil {
+	//the reader starts once connected, connect reads the ack itself
+	if err = s.connect(ctx, s.conn); err != nil {
+		_ = s.conn.Close()
+		s.closeMessages()
 		s.log.WithError(err).WithFields(map[string]interface{}{
 			"url":   s.u.String(),
 			"query": s.query,
@@ -146,35 +286,81 @@ func NewSubscription(ctx context.Context, u *url.URL, query string, responseCrea
 		return nil, err
 	}
 
+	s.metrics.SubscriptionStarted(s.operation)
+	go s.startWSReader(s.conn)
 	return s, nil
 }
 
-func (s *Subscription) Messages() <-chan *Message {
-	return s.ch
+// dial opens the websocket, adding a fresh access token cookie when a token source is set
+func (s *Subscription) dial(ctx context.Context) (*websocket.Conn, error) {
+	header := s.header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	log.InjectTraceHeaders(ctx, header)
+	if s.tokenSource != nil {
+		tok, err := s.tokenSource.Token()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed getting access token")
+		}
+		header.Add("Cookie", accessTokenCookie(tok.AccessToken).String())
+	}
+
+	dialer := *websocket.DefaultDialer
+	switch {
+	case s.negotiate:
+		dialer.Subprotocols = []string{string(ProtocolGraphQLTransportWS), string(ProtocolGraphQLWS)}
+	case s.protocol != ProtocolGraphQLWS:
+		dialer.Subprotocols = []string{string(s.protocol)}
+	}
+
+	conn, resp, err := dialer.DialContext(ctx, s.u.String(), header)
+	if err != nil {
+		if resp != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("websocket error with status: %d", resp.StatusCode))
+		}
+		return nil, errors.Wrap(err, "websocket error")
+	}
+	return conn, nil
 }
 
-func (s *Subscription) informMessageReceived(m *Message) error {
-	select {
-	case s.ch <- m:
-		return nil
-	default:
-		return errSubscriptionFailedSendingMessage
+// protocolOf returns the protocol spoken on conn, the one picked by the server if any
+func (s *Subscription) protocolOf(conn *websocket.Conn) SubscriptionProtocol {
+	if p := conn.Subprotocol(); p != "" {
+		return SubscriptionProtocol(p)
 	}
+	if s.negotiate {
+		return ProtocolGraphQLWS
+	}
+	return s.protocol
+}
+
+func (s *Subscription) Messages() <-chan *Message {
+	return s.ch
 }
 
 func (s *Subscription) Shutdown(ctx context.Context) error {
+	if s.mux != nil {
+		return s.mux.shutdown(s)
+	}
+	s.mu.Lock()
 	if s.conn == nil {
+		s.mu.Unlock()
 		return errors.New("subscription is already down")
 	}
 	s.log.Debug().Msg("sub close called")
 
+	s.closeOnce.Do(func() { close(s.closed) })
 	s.disconnect()
+	s.mu.Unlock()
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-s.readerDone:
 	}
+	s.mu.Lock()
 	s.conn = nil
+	s.mu.Unlock()
 	s.log.Debug().Msg("sub goroutines done")
 	s.log.Info().Msg("sub closed")
 	return nil
@@ -189,3 +375,81 @@ func (s *Subscription) disconnect() {
 		s.log.WithError(err).Warn().Msg("failed sending close message")
 	}
 }
+
+func (s *Subscription) isClosed() bool {
+	select {
+	case <-s.closed:
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldReconnect reports whether err, returned by the reader, is a connection loss to recover from
+func (s *Subscription) shouldReconnect(err error) bool {
+	if s.reconnect == nil || s.isClosed() {
+		return false
+	}
+	var (
+		closeErr *websocket.CloseError
+		netErr   net.Error
+	)
+	return errors.As(err, &closeErr) || errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// redial dials the websocket again until it succeeds, the attempts run out or the subscription is shut down.
+// It returns the new connection, nil when the reader must end
+func (s *Subscription) redial(lost *websocket.Conn) *websocket.Conn {
+	_ = lost.Close()
+	start := time.Now()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if s.hasTrace {
+		ctx = log.CtxWithTraceContext(ctx, s.trace)
+	}
+	go func() {
+		select {
+		case <-s.closed:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	p := s.reconnect
+	for attempt := 1; p.MaxAttempts <= 0 || attempt <= p.MaxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(p.backoff(attempt)):
+		}
+
+		conn, err := s.dial(ctx)
+		if err == nil {
+			if err = s.connect(ctx, conn); err != nil {
+				_ = conn.Close()
+			}
+		}
+		if err != nil {
+			s.log.WithError(err).Warn().WithFields(map[string]interface{}{"attempt": attempt}).Msg("sub reconnection failed")
+			continue
+		}
+
+		s.mu.Lock()
+		if s.isClosed() {
+			s.mu.Unlock()
+			_ = conn.Close()
+			return nil
+		}
+		s.conn = conn
+		s.mu.Unlock()
+
+		s.log.Info().WithFields(map[string]interface{}{"attempt": attempt}).Msg("sub reconnected")
+		s.metrics.SubscriptionReconnected(s.operation)
+		_ = s.informMessageReceived(&Message{Payload: ReconnectedMessage{Attempts: attempt, Downtime: time.Since(start)}})
+		return conn
+	}
+	s.log.Error().Msg("sub reconnection attempts exhausted")
+	return nil
+}
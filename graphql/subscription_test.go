@@ -3,15 +3,23 @@ package graphql_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/99designs/gqlgen/example/chat"
 	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/gorilla/websocket"
 	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/log"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func newServer() *httptest.Server {
@@ -96,11 +104,227 @@ func TestSubscription_SendKAs(t *testing.T) {
 	require.True(t, ok)
 }
 
+var subscriptionProtocols = map[string][]graphql.SubscriptionOption{
+	"graphql-ws":           nil,
+	"graphql-transport-ws": {graphql.SubscriptionWithProtocol(graphql.ProtocolGraphQLTransportWS)},
+	"negotiated":           {graphql.SubscriptionNegotiateProtocol},
+}
+
 func TestSubscription_WorksWithMock(t *testing.T) {
+	for name, opts := range subscriptionProtocols {
+		t.Run(name, func(t *testing.T) {
+			q := "subscription test"
+			vars := map[string]interface{}{"test": "test"}
+			output := 2
+			s := graphql.NewMockSubServer(t, q, vars, output)
+			defer s.Close()
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			u, err := url.Parse(s.URL)
+			require.NoError(t, err)
+			u.Scheme = "ws"
+
+			sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+				return new(int)
+			}, append(opts, graphql.SubscriptionWithVars(vars))...)
+			require.NoError(t, err)
+			defer sub.Shutdown(context.TODO())
+
+			messages := sub.Messages()
+
+			m := <-messages
+			require.NoError(t, m.Err)
+			require.Equal(t, &output, m.Payload)
+		})
+	}
+}
+
+func TestSubscription_WorksWithMockOnError(t *testing.T) {
+	for name, opts := range subscriptionProtocols {
+		t.Run(name, func(t *testing.T) {
+			q := "subscription test"
+			vars := map[string]interface{}{"test": "test"}
+			output := errors.New("failed")
+			s := graphql.NewMockSubServer(t, q, vars, output)
+			defer s.Close()
+
+			u, err := url.Parse(s.URL)
+			require.NoError(t, err)
+			u.Scheme = "ws"
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+				return new(int)
+			}, append(opts, graphql.SubscriptionWithVars(vars))...)
+			require.NoError(t, err)
+			defer sub.Shutdown(context.TODO())
+			m := <-sub.Messages()
+			require.Error(t, m.Err)
+		})
+	}
+}
+
+func TestSubscription_MockKeepAlives(t *testing.T) {
+	for name, opts := range subscriptionProtocols {
+		t.Run(name, func(t *testing.T) {
+			q := "subscription test"
+			vars := map[string]interface{}{"test": "test"}
+			output := 2
+			s := graphql.NewMockSubServer(t, q, vars, output)
+			defer s.Close()
+
+			ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+			defer cancel()
+
+			u, err := url.Parse(s.URL)
+			require.NoError(t, err)
+			u.Scheme = "ws"
+
+			sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+				return new(int)
+			}, append(opts, graphql.SubscriptionWithVars(vars), graphql.SubscriptionSendKAMessages)...)
+			require.NoError(t, err)
+			defer sub.Shutdown(context.TODO())
+
+			m := <-sub.Messages()
+			assert.IsType(t, graphql.KeepAliveMessage{}, m.Payload)
+			m = <-sub.Messages()
+			require.NoError(t, m.Err)
+			require.Equal(t, &output, m.Payload)
+		})
+	}
+}
+
+func TestSubscription_NegotiateFallsBackToLegacy(t *testing.T) {
+	s := newServer()
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 10*time.Second)
+	defer cancel()
+
+	sub, err := graphql.NewSubscription(ctx, u, `subscription {
+	messageAdded(roomName: "test") {
+		text
+	}
+}`,
+		func() interface{} {
+			return &expectedResp{}
+		}, graphql.SubscriptionNegotiateProtocol)
+	require.NoError(t, err)
+	defer sub.Shutdown(context.TODO())
+
+	m := <-sub.Messages()
+	require.NoError(t, m.Err)
+	require.Equal(t, "hello!", m.Payload.(*expectedResp).MessageAdded.Text)
+}
+
+func TestSubscription_WithTokenSource(t *testing.T) {
+	gqlSrv := handler.NewDefaultServer(chat.NewExecutableSchema(newChatConfig()))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := r.Cookie("access_token")
+		if !assert.NoError(t, err) || !assert.Equal(t, "token", c.Value) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		gqlSrv.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	sub, err := graphql.NewSubscription(ctx, u, `subscription {
+	messageAdded(roomName: "test") {
+		text
+	}
+}`,
+		func() interface{} {
+			return &expectedResp{}
+		},
+		graphql.SubscriptionWithTokenSource(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"})))
+	require.NoError(t, err)
+	defer sub.Shutdown(context.TODO())
+
+	m := <-sub.Messages()
+	require.NoError(t, m.Err)
+	require.Equal(t, "hello!", m.Payload.(*expectedResp).MessageAdded.Text)
+}
+
+func TestSubscription_TraceHeaders(t *testing.T) {
+	gqlSrv := handler.NewDefaultServer(chat.NewExecutableSchema(newChatConfig()))
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "req", r.Header.Get(log.XRequestID))
+		assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", r.Header.Get(log.Traceparent))
+		gqlSrv.ServeHTTP(w, r)
+	}))
+	defer s.Close()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	ctx = log.CtxWithTraceContext(ctx, log.TraceContext{
+		RequestID: "req",
+		TraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:    "00f067aa0ba902b7",
+	})
+
+	sub, err := graphql.NewSubscription(ctx, u, `subscription {
+	messageAdded(roomName: "test") {
+		text
+	}
+}`,
+		func() interface{} {
+			return &expectedResp{}
+		})
+	require.NoError(t, err)
+	defer sub.Shutdown(context.TODO())
+
+	m := <-sub.Messages()
+	require.NoError(t, m.Err)
+}
+
+type subMetrics struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (m *subMetrics) inc(event, operation string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.counts[event+":"+operation]++
+}
+
+func (m *subMetrics) get(event string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.counts[event]
+}
+
+func (m *subMetrics) SubscriptionStarted(op string)         { m.inc("started", op) }
+func (m *subMetrics) SubscriptionEnded(op string)           { m.inc("ended", op) }
+func (m *subMetrics) SubscriptionMessageReceived(op string) { m.inc("received", op) }
+func (m *subMetrics) SubscriptionMessageDropped(op string)  { m.inc("dropped", op) }
+func (m *subMetrics) SubscriptionReconnected(op string)     { m.inc("reconnected", op) }
+
+func TestSubscription_Instrumentation(t *testing.T) {
 	q := "subscription test"
 	vars := map[string]interface{}{"test": "test"}
-	output := 2
-	s := graphql.NewMockSubServer(t, q, vars, output)
+	s := graphql.NewMockSubServer(t, q, vars, 2)
 	defer s.Close()
 
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
@@ -110,38 +334,271 @@ func TestSubscription_WorksWithMock(t *testing.T) {
 	require.NoError(t, err)
 	u.Scheme = "ws"
 
+	metrics := &subMetrics{counts: map[string]int{}}
 	sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
 		return new(int)
-	}, graphql.SubscriptionWithVars(vars))
+	}, graphql.SubscriptionWithVars(vars), graphql.SubscriptionWithInstrumentation(metrics))
 	require.NoError(t, err)
-	defer sub.Shutdown(context.TODO())
-
-	messages := sub.Messages()
 
-	m := <-messages
+	m := <-sub.Messages()
 	require.NoError(t, m.Err)
-	require.Equal(t, &output, m.Payload)
+	assert.Equal(t, 1, metrics.get("started:test"))
+	assert.Equal(t, 1, metrics.get("received:test"))
+
+	require.NoError(t, sub.Shutdown(ctx))
+	assert.Equal(t, 1, metrics.get("ended:test"))
 }
 
-func TestSubscription_WorksWithMockOnError(t *testing.T) {
+type countingTokenSource struct {
+	calls int32
+}
+
+func (ts *countingTokenSource) Token() (*oauth2.Token, error) {
+	n := atomic.AddInt32(&ts.calls, 1)
+	return &oauth2.Token{AccessToken: fmt.Sprintf("token-%d", n)}, nil
+}
+
+func TestSubscription_Reconnect(t *testing.T) {
 	q := "subscription test"
 	vars := map[string]interface{}{"test": "test"}
-	output := errors.New("failed")
-	s := graphql.NewMockSubServer(t, q, vars, output)
+	var (
+		upgrader websocket.Upgrader
+		dials    int32
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&dials, 1)
+		c, err := r.Cookie("access_token")
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("token-%d", n), c.Value)
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var msg struct {
+			ID      string          `json:"id"`
+			Type    string          `json:"type"`
+			Payload graphql.Request `json:"payload"`
+		}
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "connection_init", msg.Type)
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "connection_ack"}))
+		require.NoError(t, conn.ReadJSON(&msg))
+		assert.Equal(t, "start", msg.Type)
+		assert.Equal(t, q, msg.Payload.Query)
+		assert.Equal(t, vars, msg.Payload.Variables)
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "data", "id": msg.ID, "payload": map[string]interface{}{"data": n}}))
+		if n > 1 {
+			_, _, _ = conn.ReadMessage() //keep the last connection open until shutdown
+		}
+	}))
 	defer s.Close()
 
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
 	u, err := url.Parse(s.URL)
 	require.NoError(t, err)
 	u.Scheme = "ws"
 
+	metrics := &subMetrics{counts: map[string]int{}}
+	policy := graphql.DefaultReconnectPolicy()
+	policy.BaseDelay = 10 * time.Millisecond
+	sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
+		return new(int)
+	},
+		graphql.SubscriptionWithVars(vars),
+		graphql.SubscriptionWithTokenSource(&countingTokenSource{}),
+		graphql.SubscriptionWithInstrumentation(metrics),
+		graphql.SubscriptionWithReconnect(policy))
+	require.NoError(t, err)
+
+	next := func() *graphql.Message {
+		select {
+		case m, ok := <-sub.Messages():
+			require.True(t, ok)
+			return m
+		case <-ctx.Done():
+			require.FailNow(t, "channel timed out")
+			return nil
+		}
+	}
+
+	m := next()
+	require.NoError(t, m.Err)
+	assert.Equal(t, 1, *m.Payload.(*int))
+
+	m = next()
+	reconnected, ok := m.Payload.(graphql.ReconnectedMessage)
+	require.True(t, ok)
+	assert.Equal(t, 1, reconnected.Attempts)
+
+	m = next()
+	require.NoError(t, m.Err)
+	assert.Equal(t, 2, *m.Payload.(*int))
+	assert.Equal(t, 1, metrics.get("reconnected:test"))
+
+	require.NoError(t, sub.Shutdown(ctx))
+	_, ok = <-sub.Messages()
+	assert.False(t, ok)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&dials))
+}
+
+func TestSubscription_ReconnectAttemptsExhausted(t *testing.T) {
+	q := "subscription test"
+	var (
+		upgrader websocket.Upgrader
+		dials    int32
+	)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&dials, 1) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		var msg map[string]interface{}
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "connection_ack"}))
+		require.NoError(t, conn.ReadJSON(&msg))
+	}))
+	defer s.Close()
+
 	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
 	defer cancel()
 
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
 	sub, err := graphql.NewSubscription(ctx, u, q, func() interface{} {
 		return new(int)
-	}, graphql.SubscriptionWithVars(vars))
+	}, graphql.SubscriptionWithReconnect(graphql.ReconnectPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}))
 	require.NoError(t, err)
-	defer sub.Shutdown(context.TODO())
+
+	select {
+	case _, ok := <-sub.Messages():
+		assert.False(t, ok)
+	case <-ctx.Done():
+		require.FailNow(t, "channel timed out")
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&dials))
+}
+
+// newKeepAliveServer sends one data message per connection, the first silentDials connections then stop reading,
+// leaving the websocket pings unanswered like a half-open connection
+func newKeepAliveServer(t *testing.T, silentDials int32, release <-chan struct{}) *httptest.Server {
+	var (
+		upgrader websocket.Upgrader
+		dials    int32
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&dials, 1)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		var msg struct {
+			ID   string `json:"id"`
+			Type string `json:"type"`
+		}
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "connection_ack"}))
+		require.NoError(t, conn.ReadJSON(&msg))
+		require.NoError(t, conn.WriteJSON(map[string]interface{}{"type": "data", "id": msg.ID, "payload": map[string]interface{}{"data": n}}))
+		if n <= silentDials {
+			<-release
+			return
+		}
+		for { //answers the pings until shutdown
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestSubscription_KeepAliveTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	s := newKeepAliveServer(t, 1, release)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithKeepAliveTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	var msgs []*graphql.Message
+	for m := range sub.Messages() {
+		msgs = append(msgs, m)
+	}
+	require.Len(t, msgs, 2)
+	require.NoError(t, msgs[0].Err)
+	assert.Equal(t, 1, *msgs[0].Payload.(*int))
+	assert.True(t, errors.Is(msgs[1].Err, graphql.ErrKeepAliveTimeout))
+}
+
+func TestSubscription_KeepAlivePings(t *testing.T) {
+	s := newKeepAliveServer(t, 0, nil)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	}, graphql.SubscriptionWithKeepAliveTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+
+	m := <-sub.Messages()
+	require.NoError(t, m.Err)
+	select {
+	case m, ok := <-sub.Messages():
+		require.FailNow(t, "idle subscription got a message", "%v %v", m, ok)
+	case <-time.After(400 * time.Millisecond):
+	}
+	require.NoError(t, sub.Shutdown(ctx))
+}
+
+func TestSubscription_KeepAliveTimeoutReconnects(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	s := newKeepAliveServer(t, 1, release)
+	defer s.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+	u.Scheme = "ws"
+
+	sub, err := graphql.NewSubscription(ctx, u, "subscription test", func() interface{} {
+		return new(int)
+	},
+		graphql.SubscriptionWithKeepAliveTimeout(100*time.Millisecond),
+		graphql.SubscriptionWithReconnect(graphql.ReconnectPolicy{BaseDelay: time.Millisecond}))
+	require.NoError(t, err)
+
 	m := <-sub.Messages()
-	require.Error(t, m.Err)
+	assert.Equal(t, 1, *m.Payload.(*int))
+	m = <-sub.Messages()
+	_, ok := m.Payload.(graphql.ReconnectedMessage)
+	assert.True(t, ok)
+	m = <-sub.Messages()
+	assert.Equal(t, 2, *m.Payload.(*int))
+	require.NoError(t, sub.Shutdown(ctx))
 }
@@ -2,6 +2,16 @@ package graphql
 
 import "encoding/json"
 
+// SubscriptionProtocol is a GraphQL over websocket protocol, sent as the websocket subprotocol
+type SubscriptionProtocol string
+
+const (
+	// ProtocolGraphQLWS is the legacy subscriptions-transport-ws protocol, the default
+	ProtocolGraphQLWS SubscriptionProtocol = "graphql-ws"
+	// ProtocolGraphQLTransportWS is the graphql-transport-ws protocol of graphql-ws
+	ProtocolGraphQLTransportWS SubscriptionProtocol = "graphql-transport-ws"
+)
+
 const (
 	connectionInitMsg = "connection_init" // Client -> Server
 	startMsg          = "start"           // Client -> Server
@@ -10,6 +20,12 @@ const (
 	dataMsg           = "data"            // Server -> Client
 	errorMsg          = "error"           // Server -> Client
 	completedMsg      = "complete"
+
+	// graphql-transport-ws messages
+	subscribeMsg = "subscribe" // Client -> Server
+	nextMsg      = "next"      // Server -> Client
+	pingMsg      = "ping"      // bidirectional
+	pongMsg      = "pong"      // bidirectional
 )
 
 type operationMessage struct {
@@ -4,51 +4,76 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/gorilla/websocket"
 	"github.com/pkg/errors"
 )
 
-type writeStateFunc func() (interface{}, writeStateFunc, error)
+type writeStateFunc func(ctx context.Context, conn *websocket.Conn) (interface{}, writeStateFunc, error)
 
-func (s *Subscription) writeConnectionInitMessageState() (interface{}, writeStateFunc, error) {
+func (s *Subscription) writeConnectionInitMessageState(context.Context, *websocket.Conn) (interface{}, writeStateFunc, error) {
 	s.log.Debug().Msg("writeState:writeConnectionInitMessage")
 	return operationMessage{Type: connectionInitMsg}, s.writeSubscriptionQueryState, nil
 }
 
-func (s *Subscription) writeSubscriptionQueryState() (interface{}, writeStateFunc, error) {
+// writeSubscriptionQueryState waits for the ack of the connection_init message before starting the subscription
+func (s *Subscription) writeSubscriptionQueryState(ctx context.Context, conn *websocket.Conn) (interface{}, writeStateFunc, error) {
 	s.log.Debug().Msg("writeState:writeSubscriptionQuery")
+	if err := s.readAck(ctx, conn); err != nil {
+		return nil, nil, err
+	}
+	msg, err := s.startMessage(s.protocolOf(conn), "1")
+	if err != nil {
+		return nil, nil, err
+	}
+	return msg, nil, nil
+}
+
+// startMessage returns the message starting the subscription with the operation id
+func (s *Subscription) startMessage(protocol SubscriptionProtocol, id string) (operationMessage, error) {
 	buf, err := json.Marshal(Request{
 		Query:     s.query,
 		Variables: s.vars,
 	})
 	if err != nil {
-		return nil, nil, errors.Wrap(err, "failed marshalling query")
+		return operationMessage{}, errors.Wrap(err, "failed marshalling query")
 	}
 
-	return operationMessage{Type: startMsg, ID: "1", Payload: buf}, nil, nil
+	msgType := startMsg
+	if protocol == ProtocolGraphQLTransportWS {
+		msgType = subscribeMsg
+	}
+	return operationMessage{Type: msgType, ID: id, Payload: buf}, nil
 }
 
-func (s *Subscription) connect(connectionCtx context.Context) error {
+// connect sends connection_init, waits for the ack, then starts the subscription. It reads the ack from conn, so
+// the reader of conn must not be running yet
+func (s *Subscription) connect(connectionCtx context.Context, conn *websocket.Conn) error {
 	currentState := s.writeConnectionInitMessageState
 	for currentState != nil {
 
 		select {
 		case <-connectionCtx.Done():
 			return connectionCtx.Err()
-		case <-s.readerDone:
-			return errors.New("reader down")
 		default:
 		}
 
-		toWrite, next, err := currentState()
+		toWrite, next, err := currentState(connectionCtx, conn)
 		if err != nil {
 
 			return err
 		}
 
-		if err := s.conn.WriteJSON(toWrite); err != nil {
+		if err := s.writeJSON(conn, toWrite); err != nil {
 			return err
 		}
 		currentState = next
 	}
 	return nil
 }
+
+// writeJSON serialises the writes to conn, the reader answers pings while connect may be writing
+func (s *Subscription) writeJSON(conn *websocket.Conn, v interface{}) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return conn.WriteJSON(v)
+}
@@ -0,0 +1,104 @@
+// Package operation classifies the operations of GraphQL documents, for the packages which cannot import each other
+package operation
+
+import (
+	"strings"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+// Operation types
+const (
+	Query        = "query"
+	Mutation     = "mutation"
+	Subscription = "subscription"
+)
+
+// Type returns the type of the first operation of the GraphQL document query, skipping the fragment definitions.
+// Documents which do not parse are classified by their first keyword, and are queries unless it says otherwise
+func Type(query string) string {
+	if op := first(query); op != nil {
+		return string(op.Operation)
+	}
+	query = StripComments(query)
+	for _, typ := range []string{Mutation, Subscription} {
+		if strings.HasPrefix(query, typ) {
+			return typ
+		}
+	}
+	return Query
+}
+
+// Name returns the name of the first operation of the GraphQL document query, skipping the fragment definitions.
+// Anonymous operations are named after their first field, so `query { assets { id } }` is named "assets"
+func Name(query string) string {
+	if op := first(query); op != nil {
+		if op.Name != "" {
+			return op.Name
+		}
+		if len(op.SelectionSet) > 0 {
+			if field, ok := op.SelectionSet[0].(*ast.Field); ok {
+				return field.Name
+			}
+		}
+	}
+
+	query = StripComments(query)
+	for _, typ := range []string{Query, Mutation, Subscription} {
+		if strings.HasPrefix(query, typ) {
+			rest := strings.TrimSpace(query[len(typ):])
+			if name := graphQLName(rest); name != "" {
+				return name
+			}
+			query = rest
+			break
+		}
+	}
+
+	idx := strings.IndexByte(query, '{')
+	if idx < 0 {
+		return ""
+	}
+	field := StripComments(query[idx+1:])
+	name := graphQLName(field)
+	if rest := strings.TrimSpace(field[len(name):]); strings.HasPrefix(rest, ":") { //aliased field
+		return graphQLName(StripComments(rest[1:]))
+	}
+	return name
+}
+
+// first returns the first operation definition of query, or nil when query does not parse
+func first(query string) *ast.OperationDefinition {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil || len(doc.Operations) == 0 {
+		return nil
+	}
+	return doc.Operations[0]
+}
+
+// StripComments removes leading whitespace and # comment lines from a GraphQL document
+func StripComments(query string) string {
+	for {
+		query = strings.TrimSpace(query)
+		if !strings.HasPrefix(query, "#") {
+			return query
+		}
+		idx := strings.IndexByte(query, '\n')
+		if idx < 0 {
+			return ""
+		}
+		query = query[idx+1:]
+	}
+}
+
+// graphQLName returns the GraphQL name at the start of s, if any
+func graphQLName(s string) string {
+	for i, r := range s {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return s[:i]
+		}
+	}
+	return s
+}
@@ -0,0 +1,36 @@
+package operation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestType(t *testing.T) {
+	for query, typ := range map[string]string{
+		"query { a }":                Query,
+		"{ a }":                      Query,
+		"# comment\n mutation { a }": Mutation,
+		"subscription s { a }":       Subscription,
+		"fragment f on A { id }\nmutation m { a { ...f } }": Mutation,
+		"mutation {": Mutation,
+		"testQuery":  Query,
+	} {
+		assert.Equal(t, typ, Type(query), query)
+	}
+}
+
+func TestName(t *testing.T) {
+	for query, want := range map[string]string{
+		`query GetAssets($ids: [ID!]) { assets(ids: $ids) { id } }`: "GetAssets",
+		`{ alias: asset(id: "1") { id } }`:                          "asset",
+		"fragment f on A { id }\nmutation m { a { ...f } }":         "m",
+		"fragment f on A { id }\n{ a { ...f } }":                    "a",
+		//does not parse
+		`query ($id: ID!) { alias: asset(id: $id) { id }`: "asset",
+		`mutation createPlaybook(`:                        "createPlaybook",
+		``:                                                "",
+	} {
+		assert.Equal(t, want, Name(query), query)
+	}
+}
@@ -0,0 +1,7 @@
+// Package sentinel holds the sentinel errors shared by the client and graphql packages
+package sentinel
+
+import "errors"
+
+// ErrRateLimited is the error of the requests refused by a client side rate limit or by the server
+var ErrRateLimited = errors.New("ctpx-sdk-go/client: rate limit exceeded")
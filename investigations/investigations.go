@@ -52,6 +52,7 @@ type IInvestigationSvc interface {
 type InvestigationSvc struct {
 	client      *client.Client
 	serviceName string
+	url         string
 }
 
 // NewInvestigationsSvc takes a client from `client` package -- see examples/notifications.go for an example
@@ -59,7 +60,37 @@ func NewInvestigationSvc(c *client.Client, serviceName string) *InvestigationSvc
 	return &InvestigationSvc{client: c, serviceName: serviceName}
 }
 
+// NewInvestigationSvcWithURL is NewInvestigationSvc against the API at url instead of the INVESTIGATIONS_URL environment variable
+func NewInvestigationSvcWithURL(c *client.Client, serviceName string, url string) *InvestigationSvc {
+	return &InvestigationSvc{client: c, serviceName: serviceName, url: url}
+}
+
+func (t *InvestigationSvc) serviceURL() string {
+	if t.url != "" {
+		return t.url
+	}
+	return envy.Get("INVESTIGATIONS_URL", DefaultURL)
+}
+
 func (t *InvestigationSvc) GetInvestigation(in *GetInvestigationInput, rf graphql.ResponseFields, opts ...graphql.RequestOption) (*InvestigationOutput, error) {
+	var investigation *InvestigationOutput
+	if err := t.getInvestigation(in, rf, &investigation, opts...); err != nil {
+		return nil, err
+	}
+	return investigation, nil
+}
+
+// GetInvestigationInto is GetInvestigation decoding the investigation into out, a pointer to a struct whose fields
+// are the ones requested, see graphql.ResponseFieldsOf
+func (t *InvestigationSvc) GetInvestigationInto(in *GetInvestigationInput, out interface{}, opts ...graphql.RequestOption) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.getInvestigation(in, rf, out, opts...)
+}
+
+func (t *InvestigationSvc) getInvestigation(in *GetInvestigationInput, rf graphql.ResponseFields, investigation interface{}, opts ...graphql.RequestOption) error {
 	query := fmt.Sprintf(`query getInvestigation($id: ID!){
 			investigation(investigation_id: $id) {
 				%s
@@ -76,15 +107,15 @@ func (t *InvestigationSvc) GetInvestigation(in *GetInvestigationInput, rf graphq
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(graphqlReq)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	reqBody := bytes.NewReader(buf.Bytes())
-	investigationsURL := envy.Get("INVESTIGATIONS_URL", DefaultURL)
+	investigationsURL := t.serviceURL()
 
 	request, err := http.NewRequest(http.MethodPost, investigationsURL, reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("malformed request error: %w", err)
+		return fmt.Errorf("malformed request error: %w", err)
 	}
 	if _, ok := graphqlReq.Header[common.AuthorizationHeader]; ok {
 		request.Header.Add(common.AuthorizationHeader, graphqlReq.Header.Get(common.AuthorizationHeader))
@@ -93,24 +124,25 @@ func (t *InvestigationSvc) GetInvestigation(in *GetInvestigationInput, rf graphq
 
 	resp, err := t.client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("server connection error: %w", err)
+		return &graphql.TransportError{Op: "server connection error", Err: err}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusInternalServerError {
-		return nil, fmt.Errorf("server responded with an error: %d", resp.StatusCode)
+		return &graphql.HTTPError{StatusCode: resp.StatusCode}
 	}
 
 	type createResponse struct {
 		Data struct {
-			Out *InvestigationOutput `json:"investigation"`
+			Out interface{} `json:"investigation"`
 		} `json:"data"`
 		Error []graphql.Error `json:"errors"`
 	}
 
 	out := createResponse{}
+	out.Data.Out = investigation
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("error decoding response: %s", err.Error())
+		return &graphql.TransportError{Op: "error decoding response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -118,10 +150,10 @@ func (t *InvestigationSvc) GetInvestigation(in *GetInvestigationInput, rf graphq
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 type GetInvestigationInput struct {
@@ -1,6 +1,8 @@
 package investigations
 
 import (
+	"errors"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"regexp"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/secureworks/taegis-sdk-go/client"
 	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -178,6 +181,7 @@ func TestGetInvestigation_ServerErrors(t *testing.T) {
 	assert.Nil(t, out)
 	assert.NotNil(t, err)
 	assert.Equal(t, "server responded with an error: 500", err.Error())
+	assert.True(t, errors.Is(err, graphql.ErrServer))
 
 	fakeHandler = func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, http.MethodPost, r.Method)
@@ -212,3 +216,40 @@ func TestGetInvestigation_ServerErrors(t *testing.T) {
 	assert.NotNil(t, err)
 	assert.Equal(t, "server responded with an error: 500", err.Error())
 }
+
+func TestGetInvestigationInto(t *testing.T) {
+	investigationSvc := NewInvestigationSvc(client.NewClient(), "test-app")
+	fakeHandler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `priority`)
+		assert.NotContains(t, string(body), `genesis_alerts`)
+
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"data": {"investigation": {"id": "1234", "priority": 2}}}`))
+	}
+	server := httptest.NewServer(http.HandlerFunc(fakeHandler))
+	DefaultURL = server.URL
+	defer server.Close()
+
+	var out struct {
+		ID       string `json:"id"`
+		Priority int    `json:"priority"`
+	}
+	err := investigationSvc.GetInvestigationInto(&GetInvestigationInput{ID: "1234", TenantID: "1"}, &out)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1234", out.ID)
+	assert.Equal(t, 2, out.Priority)
+
+	assert.Error(t, investigationSvc.GetInvestigationInto(&GetInvestigationInput{ID: "1234", TenantID: "1"}, "id"))
+}
+
+// TestInvestigationSvc_Schema checks the queries of every method against the bundled schema
+func TestInvestigationSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	svc := NewInvestigationSvcWithURL(client.NewClient(), "test", s.URL)
+
+	_, _ = svc.GetInvestigation(&GetInvestigationInput{TenantID: "tenantID", ID: "id"}, DefaultFields)
+}
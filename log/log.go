@@ -105,6 +105,8 @@ const (
 	LoggerKey ctxKey = iota + 1
 	//EntryKey is the key value to use with context.Context for Logger put and retrieval.
 	EntryKey
+	//traceKey is the key of the TraceContext, see CtxWithTraceContext.
+	traceKey
 )
 
 //CtxWithLogger returns a context with Logger l as its value.
@@ -90,6 +90,8 @@ func NewHTTPRequestMiddleware(logger log.Logger, lvl log.Level) func(http.Handle
 			entry := logger.Entry(lvl).Async()
 
 			ctx := log.CtxWithEntry(r.Context(), entry)
+			//keep the inbound ids so outbound client calls made with ctx propagate them
+			ctx = log.CtxWithTraceContext(ctx, log.TraceContextFromHeader(r.Header))
 			r = r.WithContext(ctx)
 
 			defer logEntry(w, r, entry, time.Now())
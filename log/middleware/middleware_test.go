@@ -87,6 +87,25 @@ func TestHTTPRequestMiddleware(t *testing.T) {
 	}
 }
 
+func TestHTTPRequestMiddlewareTraceContext(t *testing.T) {
+	mid := NewHTTPRequestMiddleware(mLog{log.Noop()}, 0)
+
+	var tc log.TraceContext
+	var ok bool
+	handler := mid(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc, ok = log.TraceContextFromCtx(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/path", nil)
+	req.Header.Set(log.XRequestID, "req")
+	req.Header.Set(log.Traceparent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !ok || tc.RequestID != "req" || tc.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || tc.SpanID != "00f067aa0ba902b7" {
+		t.Fatalf("Unexpected trace context in request context: %+v", tc)
+	}
+}
+
 func TestHTTPRequestMiddlewarePanic(t *testing.T) {
 	ml := mLog{log.Noop()}
 	mid := NewHTTPRequestMiddleware(ml, 0)
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+const (
+	//Traceparent is the W3C Trace Context header.
+	Traceparent = `traceparent`
+
+	traceparentVersion = "00"
+	traceparentSampled = "01"
+	traceIDLength      = 32
+	spanIDLength       = 16
+)
+
+//TraceContext holds the ids used to correlate requests across services.
+type TraceContext struct {
+	RequestID string
+	TraceID   string
+	SpanID    string
+}
+
+//CtxWithTraceContext returns a context with TraceContext tc as its value.
+func CtxWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey, tc)
+}
+
+//TraceContextFromCtx returns the TraceContext in ctx, and whether one exists.
+func TraceContextFromCtx(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey).(TraceContext)
+	return tc, ok
+}
+
+//TraceContextFromHeader reads the request, trace and span ids from header.
+//X-Trace-Id and X-Span-Id take precedence over the W3C traceparent header.
+func TraceContextFromHeader(header http.Header) TraceContext {
+	tc := TraceContext{
+		RequestID: header.Get(XRequestID),
+		TraceID:   header.Get(XTraceID),
+		SpanID:    header.Get(XSpanID),
+	}
+	if tp, ok := ParseTraceparent(header.Get(Traceparent)); ok {
+		if tc.TraceID == "" {
+			tc.TraceID = tp.TraceID
+		}
+		if tc.SpanID == "" {
+			tc.SpanID = tp.SpanID
+		}
+	}
+	return tc
+}
+
+//ParseTraceparent parses a W3C traceparent header value, only version 00 is supported.
+func ParseTraceparent(v string) (TraceContext, bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || parts[0] != traceparentVersion ||
+		!isHexID(parts[1], traceIDLength) || !isHexID(parts[2], spanIDLength) || !isHexID(parts[3], 2) {
+		return TraceContext{}, false
+	}
+	return TraceContext{TraceID: parts[1], SpanID: parts[2]}, true
+}
+
+//Traceparent formats tc as a W3C traceparent header value.
+//It returns false when the trace or span id can't be represented in that format.
+func (tc TraceContext) Traceparent() (string, bool) {
+	traceID := strings.ToLower(strings.Replace(tc.TraceID, "-", "", -1))
+	spanID := strings.ToLower(tc.SpanID)
+	if !isHexID(traceID, traceIDLength) || !isHexID(spanID, spanIDLength) {
+		return "", false
+	}
+	return fmt.Sprintf("%s-%s-%s-%s", traceparentVersion, traceID, spanID, traceparentSampled), true
+}
+
+//InjectTraceHeaders sets the X-Request-Id, X-Trace-Id, X-Span-Id and traceparent headers from the
+//TraceContext in ctx, headers already present are kept. A request id is generated when none is known.
+func InjectTraceHeaders(ctx context.Context, header http.Header) {
+	tc, _ := TraceContextFromCtx(ctx)
+	setIfMissing := func(k, v string) {
+		if v != "" && header.Get(k) == "" {
+			header.Set(k, v)
+		}
+	}
+
+	if tc.RequestID == "" && header.Get(XRequestID) == "" {
+		tc.RequestID = NewRequestID()
+	}
+	setIfMissing(XRequestID, tc.RequestID)
+	setIfMissing(XTraceID, tc.TraceID)
+	setIfMissing(XSpanID, tc.SpanID)
+	if tp, ok := tc.Traceparent(); ok {
+		setIfMissing(Traceparent, tp)
+	}
+}
+
+//NewRequestID returns a new random request id.
+func NewRequestID() string {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return ""
+	}
+	return id.String()
+}
+
+func isHexID(s string, length int) bool {
+	if len(s) != length {
+		return false
+	}
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return false
+	}
+	for _, c := range b { //all zero ids are invalid
+		if c != 0 {
+			return true
+		}
+	}
+	return false
+}
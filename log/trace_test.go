@@ -0,0 +1,88 @@
+package log
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	tc, ok := ParseTraceparent("00-" + testTraceID + "-" + testSpanID + "-01")
+	if !ok || tc.TraceID != testTraceID || tc.SpanID != testSpanID {
+		t.Fatalf("Unexpected trace context parsed: %+v", tc)
+	}
+
+	for _, v := range []string{
+		"",
+		"01-" + testTraceID + "-" + testSpanID + "-01",
+		"00-" + testTraceID + "-" + testSpanID,
+		"00-00000000000000000000000000000000-" + testSpanID + "-01",
+		"00-" + testTraceID + "-xyz-01",
+	} {
+		if _, ok := ParseTraceparent(v); ok {
+			t.Fatalf("Invalid traceparent parsed: %q", v)
+		}
+	}
+}
+
+func TestTraceContextFromHeader(t *testing.T) {
+	header := http.Header{}
+	header.Set(XRequestID, "req")
+	header.Set(Traceparent, "00-"+testTraceID+"-"+testSpanID+"-01")
+
+	tc := TraceContextFromHeader(header)
+	if tc.RequestID != "req" || tc.TraceID != testTraceID || tc.SpanID != testSpanID {
+		t.Fatalf("Unexpected trace context from traceparent: %+v", tc)
+	}
+
+	header.Set(XTraceID, "trace")
+	if tc = TraceContextFromHeader(header); tc.TraceID != "trace" || tc.SpanID != testSpanID {
+		t.Fatalf("X-Trace-Id should take precedence over traceparent: %+v", tc)
+	}
+}
+
+func TestInjectTraceHeaders(t *testing.T) {
+	ctx := CtxWithTraceContext(context.Background(), TraceContext{RequestID: "req", TraceID: testTraceID, SpanID: testSpanID})
+	header := http.Header{}
+	InjectTraceHeaders(ctx, header)
+
+	if header.Get(XRequestID) != "req" || header.Get(XTraceID) != testTraceID || header.Get(XSpanID) != testSpanID {
+		t.Fatalf("Trace headers not injected: %v", header)
+	}
+	if tp := header.Get(Traceparent); tp != "00-"+testTraceID+"-"+testSpanID+"-01" {
+		t.Fatalf("Unexpected traceparent: %s", tp)
+	}
+
+	//existing headers are kept
+	header = http.Header{}
+	header.Set(XRequestID, "mine")
+	InjectTraceHeaders(ctx, header)
+	if header.Get(XRequestID) != "mine" {
+		t.Fatalf("Existing request id replaced: %s", header.Get(XRequestID))
+	}
+
+	//non W3C ids are still sent but without a traceparent
+	ctx = CtxWithTraceContext(context.Background(), TraceContext{TraceID: "trace", SpanID: "span"})
+	header = http.Header{}
+	InjectTraceHeaders(ctx, header)
+	if header.Get(XTraceID) != "trace" || header.Get(Traceparent) != "" {
+		t.Fatalf("Unexpected headers for non W3C ids: %v", header)
+	}
+}
+
+func TestInjectTraceHeadersGeneratesRequestID(t *testing.T) {
+	header := http.Header{}
+	InjectTraceHeaders(context.Background(), header)
+
+	if header.Get(XRequestID) == "" {
+		t.Fatal("Request id not generated")
+	}
+	if header.Get(XTraceID) != "" || header.Get(XSpanID) != "" || header.Get(Traceparent) != "" {
+		t.Fatalf("Unexpected trace headers: %v", header)
+	}
+}
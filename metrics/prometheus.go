@@ -0,0 +1,349 @@
+// Package metrics provides ready made implementations of the client and subscription instrumentation interfaces
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+const (
+	// DefaultNamespace prefixes every metric name of a Prometheus collector
+	DefaultNamespace = "ctpx_sdk"
+
+	contentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// DefaultDurationBuckets are the request duration histogram buckets, in seconds
+	DefaultDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// DefaultSizeBuckets are the request and response size histogram buckets, in bytes
+	DefaultSizeBuckets = []float64{256, 1024, 4096, 16384, 65536, 262144, 1048576, 4194304}
+)
+
+var (
+	_ client.Instrumentation              = (*Prometheus)(nil)
+	_ graphql.SubscriptionInstrumentation = (*Prometheus)(nil)
+	_ http.Handler                        = (*Prometheus)(nil)
+)
+
+// Option sets optional behaviour of a Prometheus collector
+type Option func(p *Prometheus)
+
+// WithNamespace sets the prefix of every metric name, defaults to DefaultNamespace
+func WithNamespace(ns string) Option {
+	return func(p *Prometheus) {
+		p.namespace = ns
+	}
+}
+
+// WithDurationBuckets sets the request duration histogram buckets, in seconds
+func WithDurationBuckets(buckets []float64) Option {
+	return func(p *Prometheus) {
+		p.durationBuckets = sortedBuckets(buckets)
+	}
+}
+
+// WithSizeBuckets sets the request and response size histogram buckets, in bytes
+func WithSizeBuckets(buckets []float64) Option {
+	return func(p *Prometheus) {
+		p.sizeBuckets = sortedBuckets(buckets)
+	}
+}
+
+// Prometheus collects client and subscription metrics and exposes them in the Prometheus text exposition format.
+// Pass it to client.WithInstrumentation and graphql.SubscriptionWithInstrumentation, and serve it as an http.Handler
+type Prometheus struct {
+	namespace       string
+	durationBuckets []float64
+	sizeBuckets     []float64
+
+	mu            sync.Mutex
+	requests      map[requestLabels]*requestSeries
+	subscriptions map[string]*subscriptionSeries
+}
+
+type requestLabels struct {
+	method, endpoint, operation, status string
+}
+
+type requestSeries struct {
+	count         uint64
+	duration      *histogram
+	requestBytes  *histogram
+	responseBytes *histogram
+}
+
+type subscriptionSeries struct {
+	active, received, dropped, reconnects int64
+}
+
+// NewPrometheus returns a collector with no recorded metrics
+func NewPrometheus(opts ...Option) *Prometheus {
+	p := &Prometheus{
+		namespace:       DefaultNamespace,
+		durationBuckets: DefaultDurationBuckets,
+		sizeBuckets:     DefaultSizeBuckets,
+		requests:        map[requestLabels]*requestSeries{},
+		subscriptions:   map[string]*subscriptionSeries{},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// ObserveRequest implements client.Instrumentation
+func (p *Prometheus) ObserveRequest(m client.RequestMetrics) {
+	status := "error"
+	if m.StatusCode != 0 {
+		status = strconv.Itoa(m.StatusCode)
+	}
+	key := requestLabels{method: m.Method, endpoint: m.Endpoint, operation: m.Operation, status: status}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.requests[key]
+	if !ok {
+		s = &requestSeries{
+			duration:      newHistogram(p.durationBuckets),
+			requestBytes:  newHistogram(p.sizeBuckets),
+			responseBytes: newHistogram(p.sizeBuckets),
+		}
+		p.requests[key] = s
+	}
+	s.count++
+	s.duration.observe(m.Duration.Seconds())
+	s.requestBytes.observe(float64(m.RequestBytes))
+	s.responseBytes.observe(float64(m.ResponseBytes))
+}
+
+// SubscriptionStarted implements graphql.SubscriptionInstrumentation
+func (p *Prometheus) SubscriptionStarted(operation string) {
+	p.subscription(operation, func(s *subscriptionSeries) { s.active++ })
+}
+
+// SubscriptionEnded implements graphql.SubscriptionInstrumentation
+func (p *Prometheus) SubscriptionEnded(operation string) {
+	p.subscription(operation, func(s *subscriptionSeries) { s.active-- })
+}
+
+// SubscriptionMessageReceived implements graphql.SubscriptionInstrumentation
+func (p *Prometheus) SubscriptionMessageReceived(operation string) {
+	p.subscription(operation, func(s *subscriptionSeries) { s.received++ })
+}
+
+// SubscriptionMessageDropped implements graphql.SubscriptionInstrumentation
+func (p *Prometheus) SubscriptionMessageDropped(operation string) {
+	p.subscription(operation, func(s *subscriptionSeries) { s.dropped++ })
+}
+
+// SubscriptionReconnected implements graphql.SubscriptionInstrumentation
+func (p *Prometheus) SubscriptionReconnected(operation string) {
+	p.subscription(operation, func(s *subscriptionSeries) { s.reconnects++ })
+}
+
+func (p *Prometheus) subscription(operation string, update func(s *subscriptionSeries)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	s, ok := p.subscriptions[operation]
+	if !ok {
+		s = &subscriptionSeries{}
+		p.subscriptions[operation] = s
+	}
+	update(s)
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format
+func (p *Prometheus) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", contentType)
+	_, _ = p.WriteTo(w)
+}
+
+// WriteTo writes the metrics to w in the Prometheus text exposition format
+func (p *Prometheus) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: bufio.NewWriter(w)}
+
+	p.mu.Lock()
+	p.writeRequests(cw)
+	p.writeSubscriptions(cw)
+	p.mu.Unlock()
+
+	if cw.err == nil {
+		cw.err = cw.w.Flush()
+	}
+	return cw.n, cw.err
+}
+
+func (p *Prometheus) writeRequests(w *countingWriter) {
+	if len(p.requests) == 0 {
+		return
+	}
+	keys := make([]requestLabels, 0, len(p.requests))
+	for k := range p.requests {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.endpoint != b.endpoint {
+			return a.endpoint < b.endpoint
+		}
+		if a.operation != b.operation {
+			return a.operation < b.operation
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.status < b.status
+	})
+	labels := func(k requestLabels) string {
+		return formatLabels("method", k.method, "endpoint", k.endpoint, "operation", k.operation, "status", k.status)
+	}
+
+	name := p.name("requests_total")
+	w.header(name, "counter", "Requests made by the SDK client.")
+	for _, k := range keys {
+		w.printf("%s%s %d\n", name, labels(k), p.requests[k].count)
+	}
+
+	histograms := []struct {
+		name, help string
+		get        func(s *requestSeries) *histogram
+	}{
+		{"request_duration_seconds", "Duration of the SDK client requests, in seconds.", func(s *requestSeries) *histogram { return s.duration }},
+		{"request_size_bytes", "Size of the SDK client request bodies, in bytes.", func(s *requestSeries) *histogram { return s.requestBytes }},
+		{"response_size_bytes", "Size of the SDK client response bodies, in bytes.", func(s *requestSeries) *histogram { return s.responseBytes }},
+	}
+	for _, h := range histograms {
+		name := p.name(h.name)
+		w.header(name, "histogram", h.help)
+		for _, k := range keys {
+			h.get(p.requests[k]).write(w, name, labels(k))
+		}
+	}
+}
+
+func (p *Prometheus) writeSubscriptions(w *countingWriter) {
+	if len(p.subscriptions) == 0 {
+		return
+	}
+	ops := make([]string, 0, len(p.subscriptions))
+	for op := range p.subscriptions {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	series := []struct {
+		name, kind, help string
+		get              func(s *subscriptionSeries) int64
+	}{
+		{"subscriptions_active", "gauge", "Open GraphQL subscriptions.", func(s *subscriptionSeries) int64 { return s.active }},
+		{"subscription_messages_received_total", "counter", "Data messages received by GraphQL subscriptions.", func(s *subscriptionSeries) int64 { return s.received }},
+		{"subscription_messages_dropped_total", "counter", "GraphQL subscription messages dropped because the consumer was too slow.", func(s *subscriptionSeries) int64 { return s.dropped }},
+		{"subscription_reconnects_total", "counter", "GraphQL subscription reconnections.", func(s *subscriptionSeries) int64 { return s.reconnects }},
+	}
+	for _, m := range series {
+		name := p.name(m.name)
+		w.header(name, m.kind, m.help)
+		for _, op := range ops {
+			w.printf("%s%s %d\n", name, formatLabels("operation", op), m.get(p.subscriptions[op]))
+		}
+	}
+}
+
+func (p *Prometheus) name(metric string) string {
+	if p.namespace == "" {
+		return metric
+	}
+	return p.namespace + "_" + metric
+}
+
+type histogram struct {
+	buckets []float64
+	counts  []uint64
+	sum     float64
+	count   uint64
+}
+
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{buckets: buckets, counts: make([]uint64, len(buckets))}
+}
+
+func (h *histogram) observe(v float64) {
+	for i, upper := range h.buckets {
+		if v <= upper {
+			h.counts[i]++
+		}
+	}
+	h.sum += v
+	h.count++
+}
+
+func (h *histogram) write(w *countingWriter, name, labels string) {
+	for i, upper := range h.buckets {
+		w.printf("%s_bucket%s %d\n", name, withLabel(labels, "le", formatFloat(upper)), h.counts[i])
+	}
+	w.printf("%s_bucket%s %d\n", name, withLabel(labels, "le", "+Inf"), h.count)
+	w.printf("%s_sum%s %s\n", name, labels, formatFloat(h.sum))
+	w.printf("%s_count%s %d\n", name, labels, h.count)
+}
+
+type countingWriter struct {
+	w   *bufio.Writer
+	n   int64
+	err error
+}
+
+func (w *countingWriter) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(w.w, format, args...)
+	w.n += int64(n)
+	w.err = err
+}
+
+func (w *countingWriter) header(name, kind, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// formatLabels formats name/value pairs as a label set
+func formatLabels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+func withLabel(labels, name, value string) string {
+	return strings.TrimSuffix(labels, "}") + "," + strings.TrimPrefix(formatLabels(name, value), "{")
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+func sortedBuckets(buckets []float64) []float64 {
+	out := append([]float64(nil), buckets...)
+	sort.Float64s(out)
+	return out
+}
@@ -0,0 +1,82 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+)
+
+func TestPrometheusRequests(t *testing.T) {
+	p := NewPrometheus(WithNamespace("test"), WithDurationBuckets([]float64{1, 0.1}), WithSizeBuckets([]float64{100}))
+	m := client.RequestMetrics{
+		Method:        http.MethodPost,
+		Endpoint:      "api.example.com/graphql",
+		Operation:     "assets",
+		StatusCode:    http.StatusOK,
+		Duration:      50 * time.Millisecond,
+		RequestBytes:  10,
+		ResponseBytes: 1000,
+	}
+	p.ObserveRequest(m)
+	m.Duration = 500 * time.Millisecond
+	p.ObserveRequest(m)
+	p.ObserveRequest(client.RequestMetrics{Method: http.MethodPost, Endpoint: "api.example.com/graphql", Operation: `say "hi"`})
+
+	var b strings.Builder
+	_, err := p.WriteTo(&b)
+	require.NoError(t, err)
+	out := b.String()
+
+	const labels = `method="POST",endpoint="api.example.com/graphql",operation="assets",status="200"`
+	for _, line := range []string{
+		"# TYPE test_requests_total counter",
+		"test_requests_total{" + labels + "} 2",
+		`test_requests_total{method="POST",endpoint="api.example.com/graphql",operation="say \"hi\"",status="error"} 1`,
+		"# TYPE test_request_duration_seconds histogram",
+		"test_request_duration_seconds_bucket{" + labels + `,le="0.1"} 1`,
+		"test_request_duration_seconds_bucket{" + labels + `,le="1"} 2`,
+		"test_request_duration_seconds_bucket{" + labels + `,le="+Inf"} 2`,
+		"test_request_duration_seconds_sum{" + labels + "} 0.55",
+		"test_request_duration_seconds_count{" + labels + "} 2",
+		"test_request_size_bytes_bucket{" + labels + `,le="100"} 2`,
+		"test_response_size_bytes_bucket{" + labels + `,le="100"} 0`,
+		"test_response_size_bytes_sum{" + labels + "} 2000",
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+	assert.NotContains(t, out, "subscriptions_active")
+}
+
+func TestPrometheusSubscriptions(t *testing.T) {
+	p := NewPrometheus()
+	p.SubscriptionStarted("messageAdded")
+	p.SubscriptionStarted("messageAdded")
+	p.SubscriptionEnded("messageAdded")
+	p.SubscriptionMessageReceived("messageAdded")
+	p.SubscriptionMessageReceived("messageAdded")
+	p.SubscriptionMessageDropped("messageAdded")
+	p.SubscriptionReconnected("messageAdded")
+
+	rec := httptest.NewRecorder()
+	p.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, contentType, rec.Header().Get("Content-Type"))
+
+	out := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE ctpx_sdk_subscriptions_active gauge",
+		`ctpx_sdk_subscriptions_active{operation="messageAdded"} 1`,
+		`ctpx_sdk_subscription_messages_received_total{operation="messageAdded"} 2`,
+		`ctpx_sdk_subscription_messages_dropped_total{operation="messageAdded"} 1`,
+		`ctpx_sdk_subscription_reconnects_total{operation="messageAdded"} 1`,
+	} {
+		assert.Contains(t, out, line+"\n")
+	}
+	assert.NotContains(t, out, "requests_total")
+}
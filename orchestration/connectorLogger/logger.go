@@ -1,6 +1,8 @@
 package connectorLogger
 
 import (
+	"context"
+
 	"github.com/secureworks/taegis-sdk-go/client"
 	"github.com/secureworks/taegis-sdk-go/common"
 	"github.com/secureworks/taegis-sdk-go/graphql"
@@ -8,6 +10,7 @@ import (
 
 type Service interface {
 	GetAllConnectorLogs(args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) (*ConnectorLogEntries, error)
+	GetAllConnectorLogsIterator(args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator
 }
 
 var _ Service = (*connectorLoggerSvc)(nil)
@@ -26,6 +29,16 @@ func New(url string, opts ...client.Option) *connectorLoggerSvc {
 }
 
 func (loggerService *connectorLoggerSvc) GetAllConnectorLogs(args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) (*ConnectorLogEntries, error) {
+	return loggerService.getAllConnectorLogs(context.Background(), args, pagination, opts...)
+}
+
+// GetAllConnectorLogsIterator returns an Iterator over the *ConnectorLogEntry items of GetAllConnectorLogs, starting at
+// the page pagination points to
+func (loggerService *connectorLoggerSvc) GetAllConnectorLogsIterator(args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return NewConnectorLogsIterator(loggerService.getAllConnectorLogs, args, pagination, opts...)
+}
+
+func (loggerService *connectorLoggerSvc) getAllConnectorLogs(ctx context.Context, args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) (*ConnectorLogEntries, error) {
 	req := graphql.NewRequest(getAllConnectorLogsQuery, opts...)
 	req.Var("args", args)
 	req.Var("pagination", pagination)
@@ -33,9 +46,27 @@ func (loggerService *connectorLoggerSvc) GetAllConnectorLogs(args ConnectorLogQu
 	var data struct {
 		ConnectorLogs *ConnectorLogEntries `json:"getAllConnectorLogs"`
 	}
-	if err := graphql.ExecuteQuery(loggerService.client, loggerService.url, req, &data); err != nil {
+	if err := graphql.ExecuteQueryContext(ctx, &graphql.QueryConfig{
+		HClient:   loggerService.client,
+		ServerURL: loggerService.url,
+		Request:   req,
+		Output:    &data,
+	}); err != nil {
 		return nil, err
 	}
 
 	return data.ConnectorLogs, nil
 }
+
+// NewConnectorLogsIterator returns an Iterator over the *ConnectorLogEntry items of the pages getAllConnectorLogs
+// returns, starting at the page pagination points to
+func NewConnectorLogsIterator(getAllConnectorLogs func(context.Context, ConnectorLogQueryInput, common.Pagination, ...graphql.RequestOption) (*ConnectorLogEntries, error), args ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		logs, err := getAllConnectorLogs(ctx, args, common.PaginationOf(req), opts...)
+		if err != nil || logs == nil {
+			return nil, err
+		}
+		total := logs.TotalCount
+		return &common.Page{Items: logs.Entries, Total: &total}, nil
+	}, pagination.PageRequest())
+}
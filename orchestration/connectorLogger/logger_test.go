@@ -1,6 +1,7 @@
 package connectorLogger
 
 import (
+	"context"
 	"github.com/secureworks/taegis-sdk-go/common"
 	"github.com/secureworks/taegis-sdk-go/graphql"
 	"github.com/secureworks/taegis-sdk-go/testutils"
@@ -42,3 +43,32 @@ func TestConnectorLoggerSvc_GetAllConnectorLogs(t *testing.T) {
 	assert.Len(t, log.Entries, 1)
 	assert.Equal(t, "Connector", log.Entries[0].Connector)
 }
+
+func TestConnectorLoggerSvc_GetAllConnectorLogsIterator(t *testing.T) {
+	r := struct {
+		Out *ConnectorLogEntries `json:"getAllConnectorLogs"`
+	}{
+		Out: &ConnectorLogEntries{Entries: []*ConnectorLogEntry{&testConnectorLog}, TotalCount: 2},
+	}
+
+	s := testutils.NewMockGQLOutput(t, header, r)
+	defer s.Close()
+
+	c := New(s.URL)
+
+	it := c.GetAllConnectorLogsIterator(ConnectorLogQueryInput{}, common.NewPaginationOptions(1, 1), graphql.RequestWithTenant(tenantId))
+	var logs []*ConnectorLogEntry
+	assert.Nil(t, it.All(context.Background(), &logs))
+	assert.Len(t, logs, 2)
+	total, _ := it.Total()
+	assert.Equal(t, 2, total)
+}
+
+// TestConnectorLoggerSvc_Schema checks the queries of every method against the bundled schema
+func TestConnectorLoggerSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL)
+
+	_, _ = c.GetAllConnectorLogs(ConnectorLogQueryInput{Connector: "connector", Level: "error"}, common.NewPaginationOptions(1, 10))
+}
@@ -1,6 +1,8 @@
 package mocks
 
 import (
+	"context"
+
 	"github.com/secureworks/taegis-sdk-go/common"
 	"github.com/secureworks/taegis-sdk-go/graphql"
 	"github.com/secureworks/taegis-sdk-go/orchestration/connectorLogger"
@@ -16,3 +18,9 @@ type Service struct {
 func (m *Service) GetAllConnectorLogs(_ connectorLogger.ConnectorLogQueryInput, _ common.Pagination, _ ...graphql.RequestOption) (*connectorLogger.ConnectorLogEntries, error) {
 	return m.GetAllConnectorLogsResult, m.GetAllConnectorLogsError
 }
+
+func (m *Service) GetAllConnectorLogsIterator(args connectorLogger.ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return connectorLogger.NewConnectorLogsIterator(func(_ context.Context, args connectorLogger.ConnectorLogQueryInput, pagination common.Pagination, opts ...graphql.RequestOption) (*connectorLogger.ConnectorLogEntries, error) {
+		return m.GetAllConnectorLogs(args, pagination, opts...)
+	}, args, pagination, opts...)
+}
@@ -40,6 +40,8 @@ func (s *subscription) Next(ctx context.Context) (*Connector, error) {
 		switch t := msg.Payload.(type) {
 		case graphql.KeepAliveMessage:
 			return &Connector{}, nil
+		case graphql.ReconnectedMessage:
+			return nil, graphql.ErrReconnected
 		case *connectorCreatedEvent:
 			return t.ConnectorEvent, nil
 		case *connectorUpdatedEvent:
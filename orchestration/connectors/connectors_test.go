@@ -1,12 +1,14 @@
 package connectors
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/secureworks/taegis-sdk-go/client"
 
 	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
 	"github.com/secureworks/taegis-sdk-go/testutils"
 	"github.com/stretchr/testify/assert"
 )
@@ -650,3 +652,69 @@ func TestNew(t *testing.T) {
 	c := New("testurl")
 	assert.NotNil(t, c)
 }
+
+// TestConnectorSvc_Schema checks the queries of every method against the bundled schema
+func TestConnectorSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL, client.WithTenant(tenantId))
+
+	yes := true
+	meta := common.ObjectMetaInput{Name: "test", Tags: common.Tags{"tag"}}
+	connectionInput := &ConnectionInput{Name: "test", Tags: common.Tags{"tag"}, Config: common.Object{"key": "value"}, AuthType: AuthTypeAPIKey}
+	ids := []string{"id"}
+
+	_, _ = c.GetConnectionMethod("test")
+	_, _ = c.GetConnectors(&GetConnectorsInput{ConnectionMethodIDs: ids, ConnectorInterfaceIDs: ids, ConnectorCategoryIDs: ids, Tags: ids})
+	_, _ = c.GetConnections(&GetConnectionsInput{ConnectionIDs: ids, ConnectorIDs: ids, ConnectorInterfaceIDs: ids})
+	_, _ = c.GetContext(&GetConnectionsInput{ConnectionIDs: ids, ConnectorIDs: ids, ConnectorInterfaceIDs: ids})
+	_, _ = c.DefineConnectionMethod(&ConnectionMethodInput{ObjectMetaInput: meta, URL: "url"})
+	_, _ = c.RemoveConnectionMethod("id")
+	interfaceInput := &ConnectorInterfaceInput{
+		ObjectMetaInput: meta,
+		Categories:      common.IDs{"id"},
+		Actions:         []*ConnectorActionInput{{ObjectMetaInput: meta}},
+		AllTenants:      &yes,
+	}
+	_, _ = c.CreateConnectorInterface(interfaceInput)
+	_, _ = c.UpdateConnectorInterface("id", interfaceInput)
+	_, _ = c.DeleteConnectorInterface("id")
+	_, _ = c.CreateConnector("id", &ConnectorInput{
+		ObjectMetaInput: meta,
+		Implements:      common.IDs{"id"},
+		AuthTypes:       []AuthType{AuthTypeAPIKey},
+		AllTenants:      &yes,
+		Actions:         []*ConnectorActionDefinitionInput{{Action: "id"}},
+		Categories:      common.IDs{"id"},
+	})
+	_, _ = c.UpdateConnector("id", &ConnectorUpdateInput{
+		Name:       "test",
+		Implements: common.IDs{"id"},
+		AuthTypes:  []AuthType{AuthTypeAPIKey},
+		Actions:    []ConnectorActionDefinitionInput{{Action: "id"}},
+		Categories: common.IDs{"id"},
+		Tags:       common.Tags{"tag"},
+	})
+	_, _ = c.DeleteConnector("id")
+	_, _ = c.CreateConnection("id", connectionInput)
+	_, _ = c.UpdateConnection("id", connectionInput)
+	_, _ = c.DeleteConnection("id")
+	_, _ = c.ValidateConnection("id")
+	_, _ = c.ValidateConnectionInput("id", connectionInput)
+	_, _ = c.ExecuteConnectionAction("id", "action", map[string]interface{}{"key": "value"})
+	_, _ = c.GetConnectorCategory("id")
+	_, _ = c.GetConnectorInterface("id")
+	_, _ = c.GetConnector("id")
+	_, _ = c.GetConnection("id")
+
+	ctx := context.Background()
+	for _, subscribe := range []func(context.Context, common.IDs, bool, ...graphql.SubscriptionOption) (Subscription, error){
+		c.ConnectorCreated, c.ConnectorUpdated, c.ConnectorDeleted,
+	} {
+		sub, err := subscribe(ctx, common.IDs{"id"}, true)
+		if assert.NoError(t, err) {
+			_, _ = sub.Next(ctx) //waits for the server to complete the subscription once validated
+			_ = sub.Close()
+		}
+	}
+}
@@ -351,7 +351,7 @@ const (
 	`
 
 	executeConnectionActionMutation = `
-		mutation ($%[1]s: ID!, $%[1]s: ConnectionInput!, $%[2]s: String!, $%[3]s: Any) {
+		mutation ($%[1]s: ID!, $%[2]s: String!, $%[3]s: Any) {
 			executeConnectionAction(connectionId: $%[1]s, actionName: $%[2]s, inputs: $%[3]s ) {
 				id
 				createdAt
@@ -165,6 +165,7 @@ const (
 				name
 				description
 				tags
+			}
 	}
 `
 	connectorInterfaceQuery = `
@@ -177,11 +178,14 @@ const (
 				description
 				tags
 				tenantId
-				categories
+				categories {
+					id
+				}
 				actions {
 					id
 					name
 				}
+			}
 	}
 `
 	connectorQuery = `
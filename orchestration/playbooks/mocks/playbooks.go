@@ -84,6 +84,12 @@ func (m *Service) GetPlaybookExecutions(_ string, _ common.Pagination, _ ...grap
 	return m.GetPlaybookExecutionsResult, m.GetPlaybookExecutionsError
 }
 
+func (m *Service) GetPlaybookExecutionsIterator(playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return playbooks.NewPlaybookExecutionsIterator(func(_ context.Context, playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) (*playbooks.PlaybookExecutions, error) {
+		return m.GetPlaybookExecutions(playbookInstanceID, pagination, opts...)
+	}, playbookInstanceID, pagination, opts...)
+}
+
 func (m *Service) GetPlaybookTriggerType(_, _ *string, _ ...graphql.RequestOption) (*playbooks.PlaybookTriggerType, error) {
 	return m.GetTriggerTypeResult, m.GetTriggerTypeError
 }
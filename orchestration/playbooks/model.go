@@ -57,7 +57,7 @@ type PlaybookVersion struct {
 	CreatedAt time.Time                        `json:"createdAt"`
 	CreatedBy string                           `json:"createdBy"`
 	Playbook  *Playbook                        `json:"playbook"`
-	Requires  []*connectors.ConnectorInterface `json:"retries"`
+	Requires  []*connectors.ConnectorInterface `json:"requires"`
 	Inputs    common.Object                    `json:"inputs"`
 	Outputs   common.Object                    `json:"outputs"`
 	Dsl       common.Object                    `json:"dsl"`
@@ -37,6 +37,8 @@ func (s *subscription) Next(ctx context.Context) (*PlaybookInstance, error) {
 		switch t := msg.Payload.(type) {
 		case graphql.KeepAliveMessage:
 			return &PlaybookInstance{}, nil
+		case graphql.ReconnectedMessage:
+			return nil, graphql.ErrReconnected
 		case *playbookInstanceCreatedEvent:
 			return t.PlaybookInstanceEvent, nil
 		case *playbookInstanceUpdatedEvent:
@@ -15,6 +15,7 @@ type Service interface {
 	GetPlaybookInstances(playbookID *string, opts ...graphql.RequestOption) ([]*PlaybookInstance, error)
 	GetPlaybookExecution(playbookExecutionID string, opts ...graphql.RequestOption) (*PlaybookExecution, error)
 	GetPlaybookExecutions(playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) (*PlaybookExecutions, error)
+	GetPlaybookExecutionsIterator(playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator
 	GetPlaybookTriggerType(id *string, name *string, opts ...graphql.RequestOption) (*PlaybookTriggerType, error)
 	GetPlaybookTriggerTypes(opts ...graphql.RequestOption) ([]*PlaybookTriggerType, error)
 	GetPlaybookTrigger(playbookTriggerID string, opts ...graphql.RequestOption) (*PlaybookTrigger, error)
@@ -268,6 +269,16 @@ func (playbookService *playbookSvc) GetPlaybookExecution(playbookExecutionID str
 }
 
 func (playbookService *playbookSvc) GetPlaybookExecutions(playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) (*PlaybookExecutions, error) {
+	return playbookService.getPlaybookExecutions(context.Background(), playbookInstanceID, pagination, opts...)
+}
+
+// GetPlaybookExecutionsIterator returns an Iterator over the *PlaybookExecution items of GetPlaybookExecutions,
+// starting at the page pagination points to
+func (playbookService *playbookSvc) GetPlaybookExecutionsIterator(playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return NewPlaybookExecutionsIterator(playbookService.getPlaybookExecutions, playbookInstanceID, pagination, opts...)
+}
+
+func (playbookService *playbookSvc) getPlaybookExecutions(ctx context.Context, playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) (*PlaybookExecutions, error) {
 	req := graphql.NewRequest(getPlaybookExecutionsQuery, opts...)
 	req.Var("playbookInstanceId", playbookInstanceID)
 	req.Var("pagination", pagination)
@@ -275,13 +286,31 @@ func (playbookService *playbookSvc) GetPlaybookExecutions(playbookInstanceID str
 	var data struct {
 		PlaybookExecutions *PlaybookExecutions `json:"playbookExecutions"`
 	}
-	if err := graphql.ExecuteQuery(playbookService.client, playbookService.url, req, &data); err != nil {
+	if err := graphql.ExecuteQueryContext(ctx, &graphql.QueryConfig{
+		HClient:   playbookService.client,
+		ServerURL: playbookService.url,
+		Request:   req,
+		Output:    &data,
+	}); err != nil {
 		return nil, err
 	}
 
 	return data.PlaybookExecutions, nil
 }
 
+// NewPlaybookExecutionsIterator returns an Iterator over the *PlaybookExecution items of the pages
+// getPlaybookExecutions returns, starting at the page pagination points to
+func NewPlaybookExecutionsIterator(getPlaybookExecutions func(context.Context, string, common.Pagination, ...graphql.RequestOption) (*PlaybookExecutions, error), playbookInstanceID string, pagination common.Pagination, opts ...graphql.RequestOption) *common.Iterator {
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		executions, err := getPlaybookExecutions(ctx, playbookInstanceID, common.PaginationOf(req), opts...)
+		if err != nil || executions == nil {
+			return nil, err
+		}
+		total := executions.TotalCount
+		return &common.Page{Items: executions.Executions, Total: &total}, nil
+	}, pagination.PageRequest())
+}
+
 func (playbookService *playbookSvc) GetPlaybookTrigger(playbookTriggerID string, opts ...graphql.RequestOption) (*PlaybookTrigger, error) {
 	req := graphql.NewRequest(getPlaybookTriggerQuery, opts...)
 	req.Var("playbookTriggerId", playbookTriggerID)
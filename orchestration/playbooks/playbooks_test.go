@@ -1,12 +1,15 @@
 package playbooks
 
 import (
+	"context"
+	"io"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/secureworks/taegis-sdk-go/common"
+	"github.com/secureworks/taegis-sdk-go/graphql"
 	"github.com/secureworks/taegis-sdk-go/testutils"
 )
 
@@ -316,6 +319,29 @@ func TestPlaybookSvc_GetPlaybook(t *testing.T) {
 	assert.Equal(t, "1234", pb.ID)
 }
 
+func TestPlaybookSvc_GetPlaybookVersionRequires(t *testing.T) {
+	r := map[string]interface{}{
+		"playbook": map[string]interface{}{
+			"id":       "1234",
+			"head":     map[string]interface{}{"id": "2", "requires": []interface{}{map[string]interface{}{"id": "c1"}}},
+			"versions": []interface{}{map[string]interface{}{"id": "1", "requires": []interface{}{map[string]interface{}{"id": "c2"}}}},
+		},
+	}
+
+	s := testutils.NewMockGQLOutput(t, header, r)
+	defer s.Close()
+
+	c := New(s.URL)
+	pb, err := c.GetPlaybook("123")
+	assert.Nil(t, err)
+	if assert.Len(t, pb.Head.Requires, 1) {
+		assert.Equal(t, "c1", pb.Head.Requires[0].ID)
+	}
+	if assert.Len(t, pb.Versions, 1) && assert.Len(t, pb.Versions[0].Requires, 1) {
+		assert.Equal(t, "c2", pb.Versions[0].Requires[0].ID)
+	}
+}
+
 func TestPlaybookSvc_GetPlaybookExecution(t *testing.T) {
 	r := struct {
 		Out *PlaybookExecution `json:"playbookExecution"`
@@ -353,6 +379,25 @@ func TestPlaybookSvc_GetPlaybookExecutions(t *testing.T) {
 	assert.Equal(t, "123", e.Executions[0].ID)
 }
 
+func TestPlaybookSvc_GetPlaybookExecutionsIterator(t *testing.T) {
+	r := struct {
+		Out *PlaybookExecutions `json:"playbookExecutions"`
+	}{
+		Out: &testPlaybookExecutions,
+	}
+
+	s := testutils.NewMockGQLOutput(t, header, r)
+	defer s.Close()
+
+	c := New(s.URL)
+	it := c.GetPlaybookExecutionsIterator("1234", common.NewPaginationOptions(1, 10))
+
+	var e *PlaybookExecution
+	assert.Nil(t, it.Next(context.Background(), &e))
+	assert.Equal(t, "123", e.ID)
+	assert.Equal(t, io.EOF, it.Next(context.Background(), &e))
+}
+
 func TestPlaybookSvc_GetPlaybookInstance(t *testing.T) {
 	r := struct {
 		Out *PlaybookInstance `json:"playbookInstance"`
@@ -499,3 +544,60 @@ func TestPlaybookSvc_UpdatePlaybookInstance(t *testing.T) {
 	assert.Equal(t, "123", i.ID)
 
 }
+
+// TestPlaybookSvc_Schema checks the queries of every method against the bundled schema
+func TestPlaybookSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	c := New(s.URL)
+
+	id := "id"
+	title := "title"
+	meta := common.ObjectMetaInput{Name: "test", Tags: common.Tags{"tag"}}
+	params := common.Object{"key": "value"}
+	playbookInput := &PlaybookInput{
+		ObjectMetaInput: meta,
+		Version:         &PlaybookVersionInput{Inputs: params, Requires: common.IDs{"id"}, DSL: params},
+		Categories:      common.IDs{"id"},
+		Title:           &title,
+	}
+	instanceInput := &PlaybookInstanceInput{
+		ObjectMetaInput: meta,
+		Trigger:         PlaybookTriggerInput{ObjectMetaInput: meta, TypeID: "id", Config: params},
+		Enabled:         true,
+		Inputs:          params,
+		Connections:     common.IDs{"id"},
+	}
+
+	_, _ = c.ExecutePlaybookInstance("id", params)
+	_, _ = c.CreatePlaybook(playbookInput)
+	_, _ = c.ClonePlaybook(ClonePlaybookInput{PlaybookID: "id", Name: "test"})
+	_, _ = c.UpdatePlaybook("id", playbookInput)
+	_, _ = c.DeletePlaybook("id")
+	_, _ = c.ExecutePlaybook("id", params)
+	_, _ = c.CreatePlaybookInstance("id", instanceInput)
+	_, _ = c.UpdatePlaybookInstance("id", instanceInput)
+	_, _ = c.DeletePlaybookInstance("id")
+	_, _ = c.SetPlaybookInstanceState("id", true)
+	_, _ = c.GetPlaybook("id")
+	_, _ = c.GetPlaybooks(&id, &common.Tags{"tag"})
+	_, _ = c.GetPlaybookInstance("id")
+	_, _ = c.GetPlaybookInstances(&id)
+	_, _ = c.GetPlaybookExecution("id")
+	_, _ = c.GetPlaybookExecutions("id", common.NewPaginationOptions(1, 10))
+	_, _ = c.GetPlaybookTrigger("id")
+	_, _ = c.GetPlaybookTriggers([]string{"id"})
+	_, _ = c.GetPlaybookTriggerType(&id, &title)
+	_, _ = c.GetPlaybookTriggerTypes()
+
+	ctx := context.Background()
+	for _, subscribe := range []func(context.Context, common.IDs, ...graphql.SubscriptionOption) (Subscription, error){
+		c.PlaybookInstanceCreated, c.PlaybookInstanceUpdated, c.PlaybookInstanceDeleted,
+	} {
+		sub, err := subscribe(ctx, common.IDs{"id"})
+		if assert.NoError(t, err) {
+			_, _ = sub.Next(ctx) //waits for the server to complete the subscription once validated
+			_ = sub.Close()
+		}
+	}
+}
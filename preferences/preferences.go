@@ -32,6 +32,15 @@ const (
         key
         preference_items {key, value}
         `
+
+	// tenantPreferencesFields are the fields ListTenantPreferencesByKey always requests
+	tenantPreferencesFields graphql.ResponseFields = `
+				preference_items{
+					key
+					value
+				}
+				tenant_id
+			`
 	EmailKey = "email"
 )
 
@@ -51,12 +60,19 @@ type IPreferencesSvc interface {
 	CreateTenantPreferences(in *PreferencesInput, rf graphql.ResponseFields) (*PreferencesOutput, error)
 	GetPreferencesByKey(*PreferencesInput, graphql.ResponseFields) (*PreferencesOutput, error)
 	ListTenantPreferencesByKey(in *PreferencesInput, rf graphql.ResponseFields) ([]*PreferencesOutput, error)
+	GetNotificationPreferences(*PreferencesInput, graphql.ResponseFields) (*PreferencesOutput, error)
+	CreatePreferencesInto(*PreferencesInput, interface{}) error
+	CreateTenantPreferencesInto(*PreferencesInput, interface{}) error
+	GetPreferencesByKeyInto(*PreferencesInput, interface{}) error
+	ListTenantPreferencesByKeyInto(*PreferencesInput, interface{}) error
+	GetNotificationPreferencesInto(*PreferencesInput, interface{}) error
 }
 
 // PreferencesSvc is the concrete implementation of the interface against the real api
 type PreferencesSvc struct {
 	client      *client.Client
 	serviceName string
+	url         string
 }
 
 // NewPreferenceSvc takes a client from `client` package -- see examples/preferences.go for an example
@@ -64,6 +80,18 @@ func NewPreferencesSvc(c *client.Client, serviceName string) *PreferencesSvc {
 	return &PreferencesSvc{client: c, serviceName: serviceName}
 }
 
+// NewPreferencesSvcWithURL is NewPreferencesSvc against the API at url instead of the PREFERENCES_URL environment variable
+func NewPreferencesSvcWithURL(c *client.Client, serviceName string, url string) *PreferencesSvc {
+	return &PreferencesSvc{client: c, serviceName: serviceName, url: url}
+}
+
+func (t *PreferencesSvc) serviceURL() string {
+	if t.url != "" {
+		return t.url
+	}
+	return envy.Get("PREFERENCES_URL", DefaultURL)
+}
+
 //NewRequestError takes a response body and a response code and returns a custom error
 //to the CreateNotification caller for failed request
 func NewRequestError(respBody io.Reader, respCode int) *RequestError {
@@ -99,7 +127,30 @@ func (b *RequestError) Error() string {
 	return fmt.Sprintf("ctpx-sdk-go/preferences: server responded with bad request: %d - response body: %s", b.Code, b.RespBody)
 }
 
+// Unwrap returns the status code as a *graphql.HTTPError so the graphql sentinel errors can be matched with errors.Is
+func (b *RequestError) Unwrap() error {
+	return &graphql.HTTPError{StatusCode: b.Code}
+}
+
 func (t *PreferencesSvc) CreatePreferences(in *PreferencesInput, rf graphql.ResponseFields) (*PreferencesOutput, error) {
+	var preferences *PreferencesOutput
+	if err := t.createPreferences(in, rf, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// CreatePreferencesInto is CreatePreferences decoding the created preferences into out, a pointer to a struct whose
+// fields are the ones requested, see graphql.ResponseFieldsOf
+func (t *PreferencesSvc) CreatePreferencesInto(in *PreferencesInput, out interface{}) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.createPreferences(in, rf, out)
+}
+
+func (t *PreferencesSvc) createPreferences(in *PreferencesInput, rf graphql.ResponseFields, preferences interface{}) error {
 	var preferenceItems []PreferenceItem
 	for k, v := range in.Preferences {
 		item := PreferenceItem{
@@ -122,36 +173,37 @@ func (t *PreferencesSvc) CreatePreferences(in *PreferencesInput, rf graphql.Resp
 	}
 	graphqlReq.Var("newUserPreference", newUserPreference)
 
-	request, err := BuildRequest(graphqlReq, in.BearerToken, in.TenantID)
+	request, err := buildRequest(t.serviceURL(), graphqlReq, in.BearerToken, in.TenantID)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := t.client.Do(request)
 
 	if err != nil {
-		return nil, fmt.Errorf("ctpx-sdk-go/preferences: server connection error: %w", err)
+		return fmt.Errorf("ctpx-sdk-go/preferences: %w", &graphql.TransportError{Op: "server connection error", Err: err})
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		requestError := NewRequestError(resp.Body, resp.StatusCode)
-		return nil, requestError
+		return requestError
 	}
 
 	type createResponse struct {
 		Data struct {
-			Out *PreferencesOutput `json:"createUserPreference"`
+			Out interface{} `json:"createUserPreference"`
 		} `json:"data"`
 		Error []graphql.Error `json:"errors"`
 	}
 
 	out := createResponse{}
+	out.Data.Out = preferences
 
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("error decoding response: %s", err.Error())
+		return &graphql.TransportError{Op: "error decoding response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -159,13 +211,31 @@ func (t *PreferencesSvc) CreatePreferences(in *PreferencesInput, rf graphql.Resp
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 func (t *PreferencesSvc) CreateTenantPreferences(in *PreferencesInput, rf graphql.ResponseFields) (*PreferencesOutput, error) {
+	var preferences *PreferencesOutput
+	if err := t.createTenantPreferences(in, rf, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// CreateTenantPreferencesInto is CreateTenantPreferences decoding the created preferences into out, see
+// graphql.ResponseFieldsOf
+func (t *PreferencesSvc) CreateTenantPreferencesInto(in *PreferencesInput, out interface{}) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.createTenantPreferences(in, rf, out)
+}
+
+func (t *PreferencesSvc) createTenantPreferences(in *PreferencesInput, rf graphql.ResponseFields, preferences interface{}) error {
 	var preferenceItems []PreferenceItem
 	for k, v := range in.Preferences {
 		item := PreferenceItem{
@@ -188,36 +258,37 @@ func (t *PreferencesSvc) CreateTenantPreferences(in *PreferencesInput, rf graphq
 	}
 	graphqlReq.Var("newTenantPreference", newUserPreference)
 
-	request, err := BuildRequest(graphqlReq, in.BearerToken, in.TenantID)
+	request, err := buildRequest(t.serviceURL(), graphqlReq, in.BearerToken, in.TenantID)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := t.client.Do(request)
 
 	if err != nil {
-		return nil, fmt.Errorf("ctpx-sdk-go/preferences: server connection error: %w", err)
+		return fmt.Errorf("ctpx-sdk-go/preferences: %w", &graphql.TransportError{Op: "server connection error", Err: err})
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		requestError := NewRequestError(resp.Body, resp.StatusCode)
-		return nil, requestError
+		return requestError
 	}
 
 	type createResponse struct {
 		Data struct {
-			Out *PreferencesOutput `json:"createTenantPreference"`
+			Out interface{} `json:"createTenantPreference"`
 		} `json:"data"`
 		Error []graphql.Error `json:"errors"`
 	}
 
 	out := createResponse{}
+	out.Data.Out = preferences
 
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("error decoding response: %s", err.Error())
+		return &graphql.TransportError{Op: "error decoding response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -225,54 +296,67 @@ func (t *PreferencesSvc) CreateTenantPreferences(in *PreferencesInput, rf graphq
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 func (t *PreferencesSvc) ListTenantPreferencesByKey(in *PreferencesInput, rf graphql.ResponseFields) ([]*PreferencesOutput, error) {
-	query := `query listTenantPreferencesByKey($key: String!) {
-			listTenantPreferencesByKey(key: $key){
-				preference_items{
-					key
-					value
-				}
-				tenant_id
-			}
-		}`
+	var preferences []*PreferencesOutput
+	if err := t.listTenantPreferencesByKey(in, tenantPreferencesFields, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// ListTenantPreferencesByKeyInto is ListTenantPreferencesByKey decoding the preferences into out, a pointer to a
+// slice of structs whose fields are the ones requested, see graphql.ResponseFieldsOf
+func (t *PreferencesSvc) ListTenantPreferencesByKeyInto(in *PreferencesInput, out interface{}) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.listTenantPreferencesByKey(in, rf, out)
+}
+
+func (t *PreferencesSvc) listTenantPreferencesByKey(in *PreferencesInput, rf graphql.ResponseFields, preferences interface{}) error {
+	query := fmt.Sprintf(`query listTenantPreferencesByKey($key: String!) {
+			listTenantPreferencesByKey(key: $key){%s}
+		}`, rf)
 	graphqlReq := graphql.NewRequest(query)
 	graphqlReq.Var("key", in.Key)
 
-	request, err := BuildRequest(graphqlReq, in.BearerToken, in.TenantID)
+	request, err := buildRequest(t.serviceURL(), graphqlReq, in.BearerToken, in.TenantID)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := t.client.Do(request)
 
 	if err != nil {
-		return nil, fmt.Errorf("ctpx-sdk-go/preferences: server connection error: %w", err)
+		return fmt.Errorf("ctpx-sdk-go/preferences: %w", &graphql.TransportError{Op: "server connection error", Err: err})
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		requestError := NewRequestError(resp.Body, resp.StatusCode)
-		return nil, requestError
+		return requestError
 	}
 
 	type createResponse struct {
 		Data struct {
-			Out []*PreferencesOutput `json:"listTenantPreferencesByKey"`
+			Out interface{} `json:"listTenantPreferencesByKey"`
 		} `json:"data"`
 		Error []graphql.Error `json:"errors"`
 	}
 
 	out := createResponse{}
+	out.Data.Out = preferences
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("error decoding response: %s", err.Error())
+		return &graphql.TransportError{Op: "error decoding response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -280,13 +364,30 @@ func (t *PreferencesSvc) ListTenantPreferencesByKey(in *PreferencesInput, rf gra
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 func (t *PreferencesSvc) GetPreferencesByKey(in *PreferencesInput, rf graphql.ResponseFields) (*PreferencesOutput, error) {
+	var preferences *PreferencesOutput
+	if err := t.getPreferencesByKey(in, rf, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// GetPreferencesByKeyInto is GetPreferencesByKey decoding the preferences into out, see graphql.ResponseFieldsOf
+func (t *PreferencesSvc) GetPreferencesByKeyInto(in *PreferencesInput, out interface{}) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.getPreferencesByKey(in, rf, out)
+}
+
+func (t *PreferencesSvc) getPreferencesByKey(in *PreferencesInput, rf graphql.ResponseFields, preferences interface{}) error {
 	query := fmt.Sprintf(`
         query userPreferenceByKey ($key: String!) {
             userPreferenceByKey (key: $key) 
@@ -296,35 +397,36 @@ func (t *PreferencesSvc) GetPreferencesByKey(in *PreferencesInput, rf graphql.Re
 	graphqlReq := graphql.NewRequest(query)
 	graphqlReq.Var("key", in.Key)
 
-	request, err := BuildRequest(graphqlReq, in.BearerToken, in.TenantID)
+	request, err := buildRequest(t.serviceURL(), graphqlReq, in.BearerToken, in.TenantID)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	resp, err := t.client.Do(request)
 
 	if err != nil {
-		return nil, fmt.Errorf("ctpx-sdk-go/preferences: server connection error: %w", err)
+		return fmt.Errorf("ctpx-sdk-go/preferences: %w", &graphql.TransportError{Op: "server connection error", Err: err})
 	}
 
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= http.StatusBadRequest {
 		requestError := NewRequestError(resp.Body, resp.StatusCode)
-		return nil, requestError
+		return requestError
 	}
 
 	type createResponse struct {
 		Data struct {
-			Out *PreferencesOutput `json:"userPreferenceByKey"`
+			Out interface{} `json:"userPreferenceByKey"`
 		} `json:"data"`
 		Error []graphql.Error `json:"errors"`
 	}
 
 	out := createResponse{}
+	out.Data.Out = preferences
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, fmt.Errorf("error decoding response: %s", err.Error())
+		return &graphql.TransportError{Op: "error decoding response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -332,13 +434,31 @@ func (t *PreferencesSvc) GetPreferencesByKey(in *PreferencesInput, rf graphql.Re
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 func (t *PreferencesSvc) GetNotificationPreferences(in *PreferencesInput, rf graphql.ResponseFields) (*PreferencesOutput, error) {
+	var preferences *PreferencesOutput
+	if err := t.getNotificationPreferences(in, rf, &preferences); err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// GetNotificationPreferencesInto is GetNotificationPreferences decoding the preferences into out, see
+// graphql.ResponseFieldsOf
+func (t *PreferencesSvc) GetNotificationPreferencesInto(in *PreferencesInput, out interface{}) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return t.getNotificationPreferences(in, rf, out)
+}
+
+func (t *PreferencesSvc) getNotificationPreferences(in *PreferencesInput, rf graphql.ResponseFields, preferences interface{}) error {
 	query := fmt.Sprintf(`
         query userNotificationPreference ($userID: String!) {
             userNotificationPreference (userID: $userID) 
@@ -354,11 +474,11 @@ func (t *PreferencesSvc) GetNotificationPreferences(in *PreferencesInput, rf gra
 	h.Add("Content-Type", "application/json")
 
 	out := &struct {
-		Out *PreferencesOutput `json:"userNotificationPreference"`
-	}{}
+		Out interface{} `json:"userNotificationPreference"`
+	}{Out: preferences}
 
 	qc := &graphql.QueryConfig{
-		ServerURL:  envy.Get("PREFERENCES_URL", DefaultURL),
+		ServerURL:  t.serviceURL(),
 		HClient:    t.client,
 		Request:    graphqlReq,
 		Header:     h,
@@ -369,13 +489,17 @@ func (t *PreferencesSvc) GetNotificationPreferences(in *PreferencesInput, rf gra
 	err := graphql.ExecuteQueryContext(context.Background(), qc)
 
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	return out.Out, nil
+	return nil
 }
 
 func BuildRequest(graphqlReq *graphql.Request, bearerToken string, tenantID string) (*http.Request, error) {
+	return buildRequest(envy.Get("PREFERENCES_URL", DefaultURL), graphqlReq, bearerToken, tenantID)
+}
+
+func buildRequest(preferencesURL string, graphqlReq *graphql.Request, bearerToken string, tenantID string) (*http.Request, error) {
 	buf := &bytes.Buffer{}
 	enc := json.NewEncoder(buf)
 	enc.SetEscapeHTML(false)
@@ -386,7 +510,6 @@ func BuildRequest(graphqlReq *graphql.Request, bearerToken string, tenantID stri
 	}
 
 	reqBody := bytes.NewReader(buf.Bytes())
-	preferencesURL := envy.Get("PREFERENCES_URL", DefaultURL)
 	request, err := http.NewRequest(http.MethodPost, preferencesURL, reqBody)
 
 	if err != nil {
@@ -2,6 +2,7 @@ package preferences
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -10,6 +11,8 @@ import (
 	"testing"
 
 	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/stretchr/testify/assert"
 )
@@ -420,6 +423,7 @@ func TestPreferences_ServerErrors(t *testing.T) {
 	assert.Nil(t, out)
 	assert.NotNil(t, err)
 	assert.Equal(t, err.Error(), "ctpx-sdk-go/preferences: server responded with an error: 500 - response body: ")
+	assert.True(t, errors.Is(err, graphql.ErrServer))
 
 	out, err = preferenceSvc.GetPreferencesByKey(preferenceInput, DefaultFields)
 
@@ -541,3 +545,87 @@ func TestPreferences_NewRequestError(t *testing.T) {
 
 	assert.NotNil(t, requestError)
 }
+
+type emailPreferences struct {
+	Key   string `json:"key"`
+	Items []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"items" graphql:"preference_items"`
+}
+
+func TestPreferences_Into(t *testing.T) {
+	var query string
+	fakeHandler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		query = req.Query
+
+		w.WriteHeader(http.StatusOK)
+		for field, response := range map[string]string{
+			"createUserPreference":       "createSuccess",
+			"createTenantPreference":     "createTenantSuccess",
+			"userPreferenceByKey":        "getSuccess",
+			"userNotificationPreference": "getNotificationPreferenceSuccess",
+			"listTenantPreferencesByKey": "getTenantSuccess",
+		} {
+			if strings.Contains(req.Query, field+" (") || strings.Contains(req.Query, field+"(") {
+				_, _ = w.Write([]byte(strings.Replace(responses[response], `"preference_items"`, `"items"`, 1)))
+				return
+			}
+		}
+	}
+	server := httptest.NewServer(http.HandlerFunc(fakeHandler))
+	DefaultURL = server.URL
+	defer server.Close()
+
+	preferenceSvc := NewPreferencesSvc(client.NewClient(), "preferences example")
+	preferenceInput := &PreferencesInput{TenantID: "123456789", Key: EmailKey, UserID: "auth0|123"}
+
+	for name, into := range map[string]func(*PreferencesInput, interface{}) error{
+		"CreatePreferences":          preferenceSvc.CreatePreferencesInto,
+		"CreateTenantPreferences":    preferenceSvc.CreateTenantPreferencesInto,
+		"GetPreferencesByKey":        preferenceSvc.GetPreferencesByKeyInto,
+		"GetNotificationPreferences": preferenceSvc.GetNotificationPreferencesInto,
+	} {
+		var out emailPreferences
+		assert.NoError(t, into(preferenceInput, &out), name)
+		assert.Contains(t, query, "items: preference_items {", name)
+		assert.NotContains(t, query, "user_id", name)
+		assert.Equal(t, EmailKey, out.Key, name)
+		if assert.Len(t, out.Items, 1, name) {
+			assert.Equal(t, "true", out.Items[0].Value, name)
+		}
+	}
+
+	var list []emailPreferences
+	assert.NoError(t, preferenceSvc.ListTenantPreferencesByKeyInto(preferenceInput, &list))
+	assert.Contains(t, query, "items: preference_items {")
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, EmailKey, list[0].Key)
+	}
+
+	assert.Error(t, preferenceSvc.GetPreferencesByKeyInto(preferenceInput, &[]string{}))
+}
+
+// TestPreferencesSvc_Schema checks the queries of every method against the bundled schema
+func TestPreferencesSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	svc := NewPreferencesSvcWithURL(client.NewClient(), "test", s.URL)
+	in := &PreferencesInput{
+		BearerToken: "token",
+		UserID:      "userID",
+		TenantID:    "tenantID",
+		Key:         EmailKey,
+		Preferences: map[string]interface{}{"mention": true},
+	}
+
+	_, _ = svc.CreatePreferences(in, DefaultFields)
+	_, _ = svc.CreateTenantPreferences(in, tenantPreferencesFields)
+	_, _ = svc.GetPreferencesByKey(in, DefaultFields)
+	_, _ = svc.ListTenantPreferencesByKey(in, DefaultFields)
+	_, _ = svc.GetNotificationPreferences(in, DefaultFields)
+}
@@ -22,6 +22,8 @@ type MockGraphQLHandler struct {
 	Errors            []graphql.Error
 	ExpectedHeaders   http.Header
 	ExpectedVariables map[string]interface{}
+	// Schema validates the incoming requests when set, see Schema
+	Schema *graphql.Schema
 }
 
 // NewMockGraphQLHandler sets up a MockGraphQLHandler to use the given testing.T
@@ -48,6 +50,11 @@ func (g *MockGraphQLHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err := dec.Decode(&gr)
 	assert.Nil(g.t, err)
 
+	// Make sure the request is valid against the schema if one is defined
+	if g.Schema != nil {
+		AssertValidRequest(g.t, g.Schema, &gr)
+	}
+
 	// Make sure the variables are what is expected if they are defined
 	if g.ExpectedVariables != nil {
 		assert.Equal(g.t, g.ExpectedVariables, gr.Variables)
@@ -0,0 +1,496 @@
+package testutils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/secureworks/taegis-sdk-go/common"
+
+	"github.com/gorilla/websocket"
+	"github.com/hashicorp/go-cleanhttp"
+)
+
+const redacted = "REDACTED"
+
+// RecorderMode selects whether a Recorder talks to a real API or replays a cassette
+type RecorderMode int
+
+const (
+	// ModeReplay serves every request from the cassette file, nothing is sent over the network
+	ModeReplay RecorderMode = iota
+	// ModeRecord sends requests to the real API and saves the exchanges to the cassette file on Stop
+	ModeRecord
+)
+
+// Cassette is the fixture file content of a Recorder
+type Cassette struct {
+	Interactions  []Interaction             `json:"interactions"`
+	Subscriptions []SubscriptionInteraction `json:"subscriptions,omitempty"`
+}
+
+// Interaction is a recorded GraphQL request and its response
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the scrubbed GraphQL request of an Interaction
+type RecordedRequest struct {
+	Method    string                 `json:"method"`
+	URL       string                 `json:"url"`
+	Header    http.Header            `json:"header,omitempty"`
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+}
+
+// RecordedResponse is the response of an Interaction, Body is kept as JSON when it is valid JSON
+type RecordedResponse struct {
+	StatusCode int             `json:"statusCode"`
+	Header     http.Header     `json:"header,omitempty"`
+	Body       json.RawMessage `json:"body,omitempty"`
+	BodyText   string          `json:"bodyText,omitempty"`
+}
+
+// SubscriptionInteraction is a recorded subscription and the messages the server sent for it
+type SubscriptionInteraction struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables,omitempty"`
+	Messages  []json.RawMessage      `json:"messages"`
+}
+
+// RecorderOption sets optional Recorder settings
+type RecorderOption func(r *Recorder)
+
+// RecorderWithTransport sets the transport used to reach the real API in ModeRecord, defaults to a clean transport
+func RecorderWithTransport(rt http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		r.transport = rt
+	}
+}
+
+// RecorderWithWebsocketUpstream sets the real subscription endpoint WebsocketHandler proxies to in ModeRecord
+func RecorderWithWebsocketUpstream(u *url.URL) RecorderOption {
+	return func(r *Recorder) {
+		r.upstream = u
+	}
+}
+
+// Recorder is a cassette style http.RoundTripper recording real GraphQL exchanges to a fixture file and replaying them.
+// Requests are matched on their normalised query and variables, the Authorization header and the
+// access_token and x-tenant-context cookies are scrubbed before anything is written. Plug it in with
+//
+//	client.NewClient(client.WithHTTPClient(recorder.Client()))
+//
+// Subscriptions are recorded and replayed by pointing graphql.NewSubscription at a server running WebsocketHandler
+type Recorder struct {
+	mode      RecorderMode
+	path      string
+	transport http.RoundTripper
+	upstream  *url.URL
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     map[int]bool
+}
+
+// NewRecorder creates a Recorder for the cassette at path, in ModeReplay the cassette must exist
+func NewRecorder(path string, mode RecorderMode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		mode:      mode,
+		path:      path,
+		transport: cleanhttp.DefaultTransport(),
+		used:      map[int]bool{},
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if mode == ModeReplay {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+		}
+	}
+	return r, nil
+}
+
+// Client returns an http.Client using the Recorder as its transport
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Cassette returns a copy of the exchanges known to the Recorder
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return Cassette{
+		Interactions:  append([]Interaction(nil), r.cassette.Interactions...),
+		Subscriptions: append([]SubscriptionInteraction(nil), r.cassette.Subscriptions...),
+	}
+}
+
+// Stop writes the recorded cassette in ModeRecord, it does nothing in ModeReplay
+func (r *Recorder) Stop() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+	data, err := json.MarshalIndent(r.Cassette(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0600)
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var gqlReq struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &gqlReq); err != nil {
+			return nil, fmt.Errorf("recorder: request is not a graphql request: %w", err)
+		}
+	}
+	recReq := RecordedRequest{
+		Method:    req.Method,
+		URL:       req.URL.String(),
+		Header:    scrubHeader(req.Header),
+		Query:     gqlReq.Query,
+		Variables: gqlReq.Variables,
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recReq)
+	}
+
+	out := req.Clone(req.Context())
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	recResp := RecordedResponse{StatusCode: resp.StatusCode, Header: scrubHeader(resp.Header)}
+	if json.Valid(respBody) {
+		recResp.Body = respBody
+	} else {
+		recResp.BodyText = string(respBody)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{Request: recReq, Response: recResp})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+// replay returns the first unused matching interaction, or the last matching one when all have been used
+func (r *Recorder) replay(req *http.Request, recReq RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := -1
+	for i, in := range r.cassette.Interactions {
+		if !matchOperation(in.Request.Query, in.Request.Variables, recReq.Query, recReq.Variables) {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("recorder: no recorded interaction for query %q", NormalizeQuery(recReq.Query))
+	}
+	r.used[found] = true
+
+	rec := r.cassette.Interactions[found].Response
+	body := []byte(rec.Body)
+	if rec.BodyText != "" {
+		body = []byte(rec.BodyText)
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+var (
+	queryWhitespace  = regexp.MustCompile(`[\s,]+`)
+	queryPunctuation = regexp.MustCompile(` ?([{}():\[\]!=@$|]) ?`)
+)
+
+// NormalizeQuery collapses the insignificant whitespace and commas of a GraphQL document so
+// queries differing only in formatting compare equal
+func NormalizeQuery(query string) string {
+	query = queryWhitespace.ReplaceAllString(strings.TrimSpace(query), " ")
+	return strings.TrimSpace(queryPunctuation.ReplaceAllString(query, "$1"))
+}
+
+func matchOperation(queryA string, varsA map[string]interface{}, queryB string, varsB map[string]interface{}) bool {
+	if NormalizeQuery(queryA) != NormalizeQuery(queryB) {
+		return false
+	}
+	if len(varsA) == 0 && len(varsB) == 0 {
+		return true
+	}
+	a, errA := json.Marshal(varsA)
+	b, errB := json.Marshal(varsB)
+	return errA == nil && errB == nil && bytes.Equal(normalizeJSON(a), normalizeJSON(b))
+}
+
+// normalizeJSON re-encodes data so numbers and key order compare equal
+func normalizeJSON(data []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	out, _ := json.Marshal(v)
+	return out
+}
+
+// scrubHeader copies h without the Authorization header and with the auth and tenant cookies redacted,
+// both those sent and those set by the server
+func scrubHeader(h http.Header) http.Header {
+	out := h.Clone()
+	if out == nil {
+		return nil
+	}
+	out.Del(common.AuthorizationHeader)
+	if cookies := out["Cookie"]; len(cookies) > 0 {
+		out.Del("Cookie")
+		for _, c := range (&http.Request{Header: http.Header{"Cookie": cookies}}).Cookies() {
+			out.Add("Cookie", scrubCookie(c).String())
+		}
+	}
+	if cookies := out["Set-Cookie"]; len(cookies) > 0 {
+		out.Del("Set-Cookie")
+		for _, c := range (&http.Response{Header: http.Header{"Set-Cookie": cookies}}).Cookies() {
+			out.Add("Set-Cookie", scrubCookie(c).String())
+		}
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+func scrubCookie(c *http.Cookie) *http.Cookie {
+	if c.Name == "access_token" || c.Name == "x-tenant-context" {
+		c.Value = redacted
+	}
+	return c
+}
+
+// wsMessage is a subscription protocol message
+type wsMessage struct {
+	Payload json.RawMessage `json:"payload,omitempty"`
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+}
+
+// WebsocketHandler serves GraphQL subscriptions. In ModeRecord it proxies them to the upstream set with
+// RecorderWithWebsocketUpstream and records the server messages, in ModeReplay it answers from the cassette
+func (r *Recorder) WebsocketHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		upgrader := websocket.Upgrader{Subprotocols: websocket.Subprotocols(req)}
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if r.mode == ModeReplay {
+			r.replaySubscriptions(conn)
+			return
+		}
+		r.recordSubscriptions(conn, req.Header)
+	})
+}
+
+func (r *Recorder) replaySubscriptions(conn *websocket.Conn) {
+	for {
+		var msg wsMessage
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+		switch msg.Type {
+		case "connection_init":
+			if err := conn.WriteJSON(wsMessage{Type: "connection_ack"}); err != nil {
+				return
+			}
+		case "start", "subscribe":
+			if err := r.replaySubscription(conn, msg); err != nil {
+				return
+			}
+		case "ping":
+			if err := conn.WriteJSON(wsMessage{Type: "pong"}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (r *Recorder) replaySubscription(conn *websocket.Conn, start wsMessage) error {
+	var gqlReq struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	_ = json.Unmarshal(start.Payload, &gqlReq)
+
+	var sub *SubscriptionInteraction
+	r.mu.Lock()
+	for i := range r.cassette.Subscriptions {
+		s := &r.cassette.Subscriptions[i]
+		if matchOperation(s.Query, s.Variables, gqlReq.Query, gqlReq.Variables) {
+			sub = s
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if sub == nil {
+		payload, _ := json.Marshal(fmt.Sprintf("recorder: no recorded subscription for query %q", NormalizeQuery(gqlReq.Query)))
+		return conn.WriteJSON(wsMessage{Type: "error", ID: start.ID, Payload: payload})
+	}
+	for _, raw := range sub.Messages {
+		var msg wsMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return err
+		}
+		msg.ID = start.ID
+		if err := conn.WriteJSON(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) recordSubscriptions(conn *websocket.Conn, header http.Header) {
+	if r.upstream == nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "no upstream set"))
+		return
+	}
+
+	upHeader := http.Header{}
+	for _, k := range []string{common.AuthorizationHeader, "Cookie", common.XTenantContextHeader} {
+		for _, v := range header[k] {
+			upHeader.Add(k, v)
+		}
+	}
+	dialer := *websocket.DefaultDialer
+	dialer.Subprotocols = websocket.Subprotocols(&http.Request{Header: header})
+	upConn, _, err := dialer.Dial(r.upstream.String(), upHeader)
+	if err != nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, err.Error()))
+		return
+	}
+
+	var (
+		mu   sync.Mutex
+		subs = map[string]*SubscriptionInteraction{}
+		ids  []string
+	)
+	defer func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for _, id := range ids {
+			r.cassette.Subscriptions = append(r.cassette.Subscriptions, *subs[id])
+		}
+	}()
+
+	done := make(chan struct{})
+	go func() { // server -> client
+		defer close(done)
+		for {
+			_, data, err := upConn.ReadMessage()
+			if err != nil {
+				_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			var msg wsMessage
+			if json.Unmarshal(data, &msg) == nil {
+				mu.Lock()
+				if sub, ok := subs[msg.ID]; ok && isRecordedServerMessage(msg.Type) {
+					sub.Messages = append(sub.Messages, json.RawMessage(data))
+				}
+				mu.Unlock()
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				return
+			}
+		}
+	}()
+
+	for { // client -> server
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			_ = upConn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			break
+		}
+		var msg wsMessage
+		if json.Unmarshal(data, &msg) == nil && (msg.Type == "start" || msg.Type == "subscribe") {
+			var gqlReq struct {
+				Query     string                 `json:"query"`
+				Variables map[string]interface{} `json:"variables"`
+			}
+			if json.Unmarshal(msg.Payload, &gqlReq) == nil {
+				mu.Lock()
+				subs[msg.ID] = &SubscriptionInteraction{Query: gqlReq.Query, Variables: gqlReq.Variables}
+				ids = append(ids, msg.ID)
+				mu.Unlock()
+			}
+		}
+		if err := upConn.WriteMessage(websocket.TextMessage, data); err != nil {
+			break
+		}
+	}
+	_ = upConn.Close()
+	<-done
+}
+
+func isRecordedServerMessage(typ string) bool {
+	switch typ {
+	case "data", "next", "error", "complete":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,161 @@
+package testutils_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/client"
+	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
+)
+
+type tagsOutput struct {
+	Tags []string `json:"tags"`
+}
+
+func TestRecorderRecordAndReplay(t *testing.T) {
+	handler := testutils.NewMockGraphQLHandler(t)
+	handler.Response = tagsOutput{Tags: []string{"a", "b"}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "access_token", Value: "secret-cookie", HttpOnly: true})
+		handler.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+	rec, err := testutils.NewRecorder(path, testutils.ModeRecord)
+	require.NoError(t, err)
+
+	c := client.NewClient(client.WithHTTPClient(rec.Client()), client.WithBearerToken("secret-token"))
+	req := graphql.NewRequest(`query($id: ID!) {
+		tags(id: $id)
+	}`, graphql.RequestWithHeader(nil))
+	req.Var("id", "1")
+	req.Header.Add("Cookie", "access_token=secret-token; x-tenant-context=tenant; other=kept")
+
+	var out tagsOutput
+	require.NoError(t, graphql.ExecuteQuery(c, srv.URL, req, &out))
+	assert.Equal(t, []string{"a", "b"}, out.Tags)
+	require.NoError(t, rec.Stop())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret-token")
+	assert.NotContains(t, string(data), "secret-cookie")
+	assert.NotContains(t, string(data), "x-tenant-context=tenant")
+	assert.Contains(t, string(data), "other=kept")
+
+	srv.Close()
+	replay, err := testutils.NewRecorder(path, testutils.ModeReplay)
+	require.NoError(t, err)
+	c = client.NewClient(client.WithHTTPClient(replay.Client()))
+
+	// formatting differences do not matter
+	req = graphql.NewRequest(`query ( $id : ID! ) { tags(id: $id) }`)
+	req.Var("id", "1")
+	out = tagsOutput{}
+	require.NoError(t, graphql.ExecuteQuery(c, srv.URL, req, &out))
+	assert.Equal(t, []string{"a", "b"}, out.Tags)
+
+	// variables do
+	req = graphql.NewRequest(`query ( $id : ID! ) { tags(id: $id) }`)
+	req.Var("id", "2")
+	err = graphql.ExecuteQuery(c, srv.URL, req, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded interaction")
+}
+
+// subUpstream is a graphql-ws server answering every start message with a data message of payload
+func subUpstream(t *testing.T, payload int) *httptest.Server {
+	upgrader := websocket.Upgrader{}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		require.NoError(t, err)
+		defer conn.Close()
+		for {
+			var msg struct {
+				Type string `json:"type"`
+				ID   string `json:"id"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			switch msg.Type {
+			case "connection_init":
+				err = conn.WriteJSON(map[string]interface{}{"type": "connection_ack"})
+			case "start":
+				err = conn.WriteJSON(map[string]interface{}{"type": "data", "id": msg.ID, "payload": map[string]interface{}{"data": payload}})
+			}
+			if err != nil {
+				return
+			}
+		}
+	}))
+}
+
+func TestRecorderSubscriptions(t *testing.T) {
+	const query = "subscription test"
+	vars := map[string]interface{}{"id": "1"}
+	upstream := subUpstream(t, 42)
+	defer upstream.Close()
+
+	upURL, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+	upURL.Scheme = "ws"
+
+	dir, err := ioutil.TempDir("", "cassette")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+	rec, err := testutils.NewRecorder(path, testutils.ModeRecord, testutils.RecorderWithWebsocketUpstream(upURL))
+	require.NoError(t, err)
+
+	receive := func(handler *httptest.Server) *int {
+		u, err := url.Parse(handler.URL)
+		require.NoError(t, err)
+		u.Scheme = "ws"
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		sub, err := graphql.NewSubscription(ctx, u, query, func() interface{} { return new(int) },
+			graphql.SubscriptionWithVars(vars), graphql.SubscriptionWithToken("secret-token"))
+		require.NoError(t, err)
+		defer sub.Shutdown(ctx)
+
+		m := <-sub.Messages()
+		require.NotNil(t, m)
+		require.NoError(t, m.Err)
+		return m.Payload.(*int)
+	}
+
+	proxy := httptest.NewServer(rec.WebsocketHandler())
+	assert.Equal(t, 42, *receive(proxy))
+	proxy.Close()
+
+	require.Eventually(t, func() bool { return len(rec.Cassette().Subscriptions) == 1 }, time.Second, 10*time.Millisecond)
+	require.NoError(t, rec.Stop())
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "secret-token"))
+
+	replay, err := testutils.NewRecorder(path, testutils.ModeReplay)
+	require.NoError(t, err)
+	replaySrv := httptest.NewServer(replay.WebsocketHandler())
+	defer replaySrv.Close()
+	assert.Equal(t, 42, *receive(replaySrv))
+}
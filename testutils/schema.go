@@ -0,0 +1,97 @@
+package testutils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/secureworks/taegis-sdk-go/graphql"
+)
+
+var (
+	schemaOnce sync.Once
+	schema     *graphql.Schema
+	schemaErr  error
+)
+
+// SchemaDir returns the directory of the bundled Taegis GraphQL schema
+func SchemaDir() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Join(filepath.Dir(file), "..", "schema")
+}
+
+// Schema returns the bundled Taegis GraphQL schema, it is loaded once and shared by the tests
+func Schema(t *testing.T) *graphql.Schema {
+	schemaOnce.Do(func() {
+		schema, schemaErr = graphql.LoadSchema(SchemaDir())
+	})
+	require.NoError(t, schemaErr)
+	return schema
+}
+
+// AssertValidRequest asserts that the query and variables of req are valid against schema
+func AssertValidRequest(t *testing.T, schema *graphql.Schema, req *graphql.Request) bool {
+	return assert.NoError(t, schema.Validate(req), "query:\n%s", req.Query)
+}
+
+// NewSchemaServer returns a server validating the GraphQL requests and subscriptions it receives against schema,
+// the invalid ones fail t. Requests get a null data response and subscriptions are completed right after they start,
+// so every method of a service can be called to check its queries without network access
+func NewSchemaServer(t *testing.T, schema *graphql.Schema) *httptest.Server {
+	upgrader := websocket.Upgrader{Subprotocols: []string{"graphql-transport-ws", "graphql-ws"}}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if websocket.IsWebSocketUpgrade(r) {
+			conn, err := upgrader.Upgrade(w, r, nil)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer conn.Close()
+			serveSchemaSubscription(t, schema, conn)
+			return
+		}
+
+		var req graphql.Request
+		if assert.NoError(t, json.NewDecoder(r.Body).Decode(&req)) {
+			AssertValidRequest(t, schema, &req)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"data":null}`))
+	}))
+}
+
+type schemaOperationMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// serveSchemaSubscription acknowledges the connection, validates the first operation and completes it
+func serveSchemaSubscription(t *testing.T, schema *graphql.Schema, conn *websocket.Conn) {
+	var msg schemaOperationMessage
+	if !assert.NoError(t, conn.ReadJSON(&msg)) || !assert.Equal(t, "connection_init", msg.Type) {
+		return
+	}
+	if !assert.NoError(t, conn.WriteJSON(schemaOperationMessage{Type: "connection_ack"})) {
+		return
+	}
+	for msg.Type != "start" && msg.Type != "subscribe" {
+		if err := conn.ReadJSON(&msg); err != nil {
+			return
+		}
+	}
+
+	var req graphql.Request
+	if assert.NoError(t, json.Unmarshal(msg.Payload, &req)) {
+		AssertValidRequest(t, schema, &req)
+	}
+	_ = conn.WriteJSON(schemaOperationMessage{ID: msg.ID, Type: "complete"})
+	_, _, _ = conn.ReadMessage() //waits for the client to close the connection
+}
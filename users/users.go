@@ -2,6 +2,7 @@ package users
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -106,18 +107,19 @@ type IUserSvc interface {
 
 type UserSvc struct {
 	client *client.Client
+	url    string
 }
 
 type getUserResponse struct {
 	Data struct {
-		Out *UserOutput `json:"tdruser"`
+		Out interface{} `json:"tdruser"`
 	} `json:"data"`
 	Error []graphql.Error `json:"errors"`
 }
 
 type findUsersResponse struct {
 	Data struct {
-		Out []*UserOutput `json:"tdrusers"`
+		Out interface{} `json:"tdrusers"`
 	} `json:"data"`
 	Error []graphql.Error `json:"errors"`
 }
@@ -127,7 +129,37 @@ func NewUserSvc(c *client.Client) IUserSvc {
 	return &UserSvc{client: c}
 }
 
+// NewUserSvcWithURL is NewUserSvc against the API at url instead of the USER_SVC_URL environment variable
+func NewUserSvcWithURL(c *client.Client, url string) IUserSvc {
+	return &UserSvc{client: c, url: url}
+}
+
+func (u *UserSvc) serviceURL() string {
+	if u.url != "" {
+		return u.url
+	}
+	return envy.Get(UserServiceEnv, DefaultURL)
+}
+
 func (u *UserSvc) GetUser(in *GetUserInput, rf graphql.ResponseFields, opts ...graphql.RequestOption) (*UserOutput, error) {
+	var user *UserOutput
+	if err := u.getUser(in, rf, &user, opts...); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserInto is GetUser decoding the user into out, a pointer to a struct whose fields are the ones requested,
+// see graphql.ResponseFieldsOf
+func (u *UserSvc) GetUserInto(in *GetUserInput, out interface{}, opts ...graphql.RequestOption) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return u.getUser(in, rf, out, opts...)
+}
+
+func (u *UserSvc) getUser(in *GetUserInput, rf graphql.ResponseFields, user interface{}, opts ...graphql.RequestOption) error {
 	query := fmt.Sprintf(`query ($id: ID!) {
 			tdruser(id: $id) {
 				%s
@@ -144,15 +176,15 @@ func (u *UserSvc) GetUser(in *GetUserInput, rf graphql.ResponseFields, opts ...g
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(graphqlReq)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	reqBody := bytes.NewReader(buf.Bytes())
-	userSvcURL := envy.Get(UserServiceEnv, DefaultURL)
+	userSvcURL := u.serviceURL()
 
 	request, err := http.NewRequest(http.MethodPost, userSvcURL, reqBody)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if _, ok := graphqlReq.Header[common.AuthorizationHeader]; ok {
 		request.Header.Add(common.AuthorizationHeader, graphqlReq.Header.Get(common.AuthorizationHeader))
@@ -160,22 +192,23 @@ func (u *UserSvc) GetUser(in *GetUserInput, rf graphql.ResponseFields, opts ...g
 	if _, ok := graphqlReq.Header[common.XTenantContextHeader]; ok {
 		request.Header.Add(common.XTenantContextHeader, graphqlReq.Header.Get(common.XTenantContextHeader))
 	} else {
-		return nil, errors.New("tenant context is required")
+		return errors.New("tenant context is required")
 	}
 
 	resp, err := u.client.Do(request)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("server responded with an error: %d, %s", resp.StatusCode, resp.Status))
+		return fmt.Errorf("%w, %s", &graphql.HTTPError{StatusCode: resp.StatusCode}, resp.Status)
 	}
 
 	out := getUserResponse{}
+	out.Data.Out = user
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, errors.New(fmt.Sprintf("error decoding get user response: %s", err.Error()))
+		return &graphql.TransportError{Op: "error decoding get user response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -183,13 +216,48 @@ func (u *UserSvc) GetUser(in *GetUserInput, rf graphql.ResponseFields, opts ...g
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
 
 func (u *UserSvc) FindUsers(in *FindUsersInput, rf graphql.ResponseFields, opts ...graphql.RequestOption) ([]*UserOutput, error) {
+	var users []*UserOutput
+	if err := u.findUsers(context.Background(), in, rf, &users, opts...); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// FindUsersInto is FindUsers decoding the users into out, a pointer to a slice of structs whose fields are the ones
+// requested, see graphql.ResponseFieldsOf
+func (u *UserSvc) FindUsersInto(in *FindUsersInput, out interface{}, opts ...graphql.RequestOption) error {
+	rf, err := graphql.ResponseFieldsOf(out)
+	if err != nil {
+		return err
+	}
+	return u.findUsers(context.Background(), in, rf, out, opts...)
+}
+
+// FindUsersIterator returns an Iterator over the *UserOutput items of FindUsers, starting at in.Page with pages of
+// in.PerPage users, a nil in finds all the users
+func (u *UserSvc) FindUsersIterator(in *FindUsersInput, rf graphql.ResponseFields, opts ...graphql.RequestOption) *common.Iterator {
+	if in == nil {
+		in = &FindUsersInput{}
+	}
+	return common.NewIterator(func(ctx context.Context, req common.PageRequest) (*common.Page, error) {
+		args := *in
+		args.Page, args.PerPage = req.Page, req.Size
+		var users []*UserOutput
+		if err := u.findUsers(ctx, &args, rf, &users, opts...); err != nil {
+			return nil, err
+		}
+		return &common.Page{Items: users}, nil
+	}, common.PageRequest{Page: in.Page, Size: in.PerPage})
+}
+
+func (u *UserSvc) findUsers(ctx context.Context, in *FindUsersInput, rf graphql.ResponseFields, users interface{}, opts ...graphql.RequestOption) error {
 	query := fmt.Sprintf(`query ($email: String, $role: String, $tenantID: ID, $status: String, $page: Int, $perPage: Int) {
 			tdrusers(email: $email, role: $role, tenantID: $tenantID, status: $status, page: $page, perPage: $perPage) {
 				%s
@@ -223,15 +291,15 @@ func (u *UserSvc) FindUsers(in *FindUsersInput, rf graphql.ResponseFields, opts
 	enc.SetEscapeHTML(false)
 	err := enc.Encode(graphqlReq)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	reqBody := bytes.NewReader(buf.Bytes())
-	userSvcURL := envy.Get(UserServiceEnv, DefaultURL)
+	userSvcURL := u.serviceURL()
 
-	request, err := http.NewRequest(http.MethodPost, userSvcURL, reqBody)
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, userSvcURL, reqBody)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if _, ok := graphqlReq.Header[common.AuthorizationHeader]; ok {
 		request.Header.Add(common.AuthorizationHeader, graphqlReq.Header.Get(common.AuthorizationHeader))
@@ -239,22 +307,23 @@ func (u *UserSvc) FindUsers(in *FindUsersInput, rf graphql.ResponseFields, opts
 	if _, ok := graphqlReq.Header[common.XTenantContextHeader]; ok {
 		request.Header.Add(common.XTenantContextHeader, graphqlReq.Header.Get(common.XTenantContextHeader))
 	} else {
-		return nil, errors.New("tenant context is required")
+		return errors.New("tenant context is required")
 	}
 
 	resp, err := u.client.Do(request)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, errors.New(fmt.Sprintf("server responded with an error: %d, %s", resp.StatusCode, resp.Status))
+		return fmt.Errorf("%w, %s", &graphql.HTTPError{StatusCode: resp.StatusCode}, resp.Status)
 	}
 
 	out := findUsersResponse{}
+	out.Data.Out = users
 	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
-		return nil, errors.New(fmt.Sprintf("error decoding find users response: %s", err.Error()))
+		return &graphql.TransportError{Op: "error decoding find users response", Err: err}
 	}
 
 	if len(out.Error) > 0 {
@@ -262,8 +331,8 @@ func (u *UserSvc) FindUsers(in *FindUsersInput, rf graphql.ResponseFields, opts
 		for _, e := range out.Error {
 			outErr = multierror.Append(outErr, e)
 		}
-		return nil, outErr
+		return outErr
 	}
 
-	return out.Data.Out, nil
+	return nil
 }
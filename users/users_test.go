@@ -3,6 +3,7 @@ package users
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -11,6 +12,7 @@ import (
 	"github.com/secureworks/taegis-sdk-go/client"
 	"github.com/secureworks/taegis-sdk-go/common"
 	"github.com/secureworks/taegis-sdk-go/graphql"
+	"github.com/secureworks/taegis-sdk-go/testutils"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/gobuffalo/envy"
 	"github.com/hashicorp/go-cleanhttp"
@@ -479,6 +481,115 @@ func TestFindUserFullInput(t *testing.T) {
 	require.Equal(t, "1111", out[0].ID)
 }
 
+type testUser struct {
+	ID      string `json:"id"`
+	Email   string `json:"email"`
+	Tenants []struct {
+		ID   string `json:"id"`
+		Role string `json:"role"`
+	} `json:"tenants_v2"`
+}
+
+func TestGetUserInto(t *testing.T) {
+	mockHandler := func() http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req graphql.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Contains(t, req.Query, "tdruser(id: $id) {")
+			require.Contains(t, req.Query, "\n\tid\n\temail\n\ttenants_v2 {\n\t\tid\n\t\trole\n\t}\n")
+			require.NotContains(t, req.Query, "family_name")
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(getUserResponses["success"]))
+		}
+	}
+	mockUserService := getMockUsersAPIServer(mockHandler)
+	defer mockUserService.Close()
+
+	var out testUser
+	err := uSvc.(*UserSvc).GetUserInto(&GetUserInput{ID: testUserID}, &out, graphql.RequestWithTenant(testTenantID))
+	require.NoError(t, err)
+	require.Equal(t, "1111", out.ID)
+	require.Equal(t, testEmail, out.Email)
+	require.Len(t, out.Tenants, 2)
+	require.Equal(t, "tm-manager", out.Tenants[1].Role)
+
+	require.Error(t, uSvc.(*UserSvc).GetUserInto(&GetUserInput{ID: testUserID}, "id", graphql.RequestWithTenant(testTenantID)))
+}
+
+func TestFindUsersInto(t *testing.T) {
+	mockHandler := func() http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req graphql.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Contains(t, req.Query, "\n\tid\n\temail\n\ttenants_v2 {")
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(findUsersResponses["success"]))
+		}
+	}
+	mockUserService := getMockUsersAPIServer(mockHandler)
+	defer mockUserService.Close()
+
+	var out []*testUser
+	err := uSvc.(*UserSvc).FindUsersInto(&FindUsersInput{Email: testEmail}, &out, graphql.RequestWithTenant(testTenantID))
+	require.NoError(t, err)
+	require.Len(t, out, 1)
+	require.Equal(t, "1111", out[0].ID)
+	require.Equal(t, "admin", out[0].Tenants[0].Role)
+}
+
+func TestFindUsersIterator(t *testing.T) {
+	var pages []float64
+	mockHandler := func() http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req graphql.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Equal(t, float64(1), req.Variables["perPage"])
+			pages = append(pages, req.Variables["page"].(float64))
+
+			w.WriteHeader(http.StatusOK)
+			if len(pages) == 3 {
+				_, _ = w.Write([]byte(`{"data": {"tdrusers": []}}`))
+				return
+			}
+			_, _ = w.Write([]byte(findUsersResponses["success"]))
+		}
+	}
+	mockUserService := getMockUsersAPIServer(mockHandler)
+	defer mockUserService.Close()
+
+	it := uSvc.(*UserSvc).FindUsersIterator(&FindUsersInput{Email: testEmail, Page: 2, PerPage: 1}, DefaultFields, graphql.RequestWithTenant(testTenantID))
+	var users []*UserOutput
+	require.NoError(t, it.All(context.Background(), &users))
+	require.Len(t, users, 2)
+	require.Equal(t, []float64{2, 3, 4}, pages)
+}
+
+func TestFindUsersIteratorNilInput(t *testing.T) {
+	var vars []map[string]interface{}
+	mockHandler := func() http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			var req graphql.Request
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			vars = append(vars, req.Variables)
+
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(findUsersResponses["success"]))
+		}
+	}
+	mockUserService := getMockUsersAPIServer(mockHandler)
+	defer mockUserService.Close()
+
+	it := uSvc.(*UserSvc).FindUsersIterator(nil, DefaultFields, graphql.RequestWithTenant(testTenantID))
+	var users []*UserOutput
+	require.NoError(t, it.All(context.Background(), &users))
+	require.Len(t, users, 1)
+	require.Len(t, vars, 1)
+	require.Equal(t, float64(1), vars[0]["page"])
+	require.NotContains(t, vars[0], "email")
+}
+
 func createTestToken(userID string, tenantID string, role string) (string, error) {
 	claims := jwt.MapClaims{}
 	claims["sub"] = userID
@@ -501,3 +612,16 @@ func getMockUsersAPIServer(handler func() http.HandlerFunc) *httptest.Server {
 	envy.Set(UserServiceEnv, newServer.URL)
 	return newServer
 }
+
+// TestUserSvc_Schema checks the queries of every method against the bundled schema
+func TestUserSvc_Schema(t *testing.T) {
+	s := testutils.NewSchemaServer(t, testutils.Schema(t))
+	defer s.Close()
+	svc := NewUserSvcWithURL(client.NewClient(), s.URL)
+	tenant := graphql.RequestWithTenant(testTenantID)
+
+	_, _ = svc.GetUser(&GetUserInput{ID: "id"}, DefaultFields, tenant)
+	_, _ = svc.FindUsers(&FindUsersInput{
+		Email: "test@example.com", Role: testRole, TenantID: testTenantID, Status: "Registered", Page: 1, PerPage: 10,
+	}, DefaultFields, tenant)
+}